@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "sistema-gestion-informacion/docs" // Documentación generada por swag
@@ -11,8 +17,16 @@ import (
 	"github.com/joho/godotenv"
 	httpSwagger "github.com/swaggo/http-swagger"
 
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/cifrado"
+	"sistema-gestion-informacion/internal/infrastructure/database"
 	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/persistence"
+	"sistema-gestion-informacion/internal/infrastructure/retry"
 	"sistema-gestion-informacion/internal/interfaces/handlers"
+	"sistema-gestion-informacion/internal/interfaces/watcher"
 )
 
 // @title Sistema de Procesamiento de Datos API
@@ -37,28 +51,52 @@ func main() {
 	}
 
 	// Inicializar base de datos (Singleton)
-	// dbInstance := database.GetInstance()
-	// dsn := getDSN()
-	// db, err := dbInstance.Connect(dsn)
-	// if err != nil {
-	// 	log.Fatalf("❌ Error conectando a la base de datos: %v", err)
-	// }
-
-	// Auto migrar modelos
-	// log.Println("🔄 Migrando esquemas de base de datos...")
-	// entities := []interface{}{
-	// 	&entities.Producto{},
-	// 	&entities.Venta{},
-	// 	&entities.DetalleVenta{},
-	// 	&entities.Sucursal{},
-	// }
-
-	// for _, entity := range entities {
-	// 	if err := db.AutoMigrate(entity); err != nil {
-	// 		log.Fatalf("❌ Error migrando entidad: %v", err)
-	// 	}
-	// }
-	// log.Println("✅ Migración completada")
+	dbInstance := database.GetInstance()
+	driver := getEnv("DB_DRIVER", database.DriverMySQL)
+	dsn := getDSN(driver)
+	db, err := dbInstance.Connect(database.Configuracion{Driver: driver, DSN: dsn})
+	if err != nil {
+		log.Fatalf("❌ Error conectando a la base de datos: %v", err)
+	}
+	defer dbInstance.Close()
+
+	// Subcomando de migraciones: migrate up|down|status
+	migrador := database.NewMigrador(db)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := ejecutarMigraciones(migrador, os.Args[2:]); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	// Las credenciales de las sucursales se cifran con las claves de CREDENCIALES_CLAVES
+	cifrador, err := cifradorCredenciales()
+	if err != nil {
+		log.Fatalf("❌ Error en CREDENCIALES_CLAVES: %v", err)
+	}
+
+	// Subcomando de credenciales: credenciales rotar
+	if len(os.Args) > 1 && os.Args[1] == "credenciales" {
+		if err := ejecutarCredenciales(persistence.NewSucursalRepositoryGorm(db, cifrador), os.Args[2:]); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	// Con DB_AUTO_MIGRATE=true las migraciones pendientes se aplican al iniciar. Una
+	// base SQLite en memoria nace vacía en cada ejecución, por lo que siempre se migra.
+	if os.Getenv("DB_AUTO_MIGRATE") == "true" || (driver == database.DriverSQLite && strings.HasPrefix(dsn, ":memory:")) {
+		log.Println("🔄 Aplicando migraciones pendientes...")
+		if _, err := migrador.Subir(context.Background()); err != nil {
+			log.Fatalf("❌ Error aplicando migraciones: %v", err)
+		}
+	}
+
+	// El servidor no inicia con el esquema desactualizado
+	if err := migrador.VerificarAlDia(context.Background()); err != nil {
+		log.Fatalf("❌ %v; ejecute 'migrate up' antes de iniciar el servidor", err)
+	}
+	log.Println("✅ Esquema de base de datos al día")
 
 	// Inicializar bus de eventos (Singleton)
 	eventBus := events.GetEventBusInstance()
@@ -66,9 +104,152 @@ func main() {
 	// Registrar manejadores de eventos
 	registerEventHandlers(eventBus)
 
+	// Configurar políticas de reintentos para sincronización y persistencia
+	politicaReintento := retry.NewPolitica(
+		getEnvInt("MAX_RETRY_ATTEMPTS", 3),
+		time.Duration(getEnvInt("RETRY_DELAY_SECONDS", 30))*time.Second,
+	)
+	politicaPersistencia := retry.NewPolitica(
+		getEnvInt("PERSISTENCIA_MAX_REINTENTOS", 3),
+		time.Duration(getEnvInt("PERSISTENCIA_DEMORA_REINTENTO_MS", 200))*time.Millisecond,
+	)
+	politicaPersistencia.Transitorio = database.EsErrorTransitorio
+
+	// Crear repositorios
+	sucursalRepo := persistence.NewSucursalRepositoryGorm(db, cifrador)
+	productoRepo := persistence.NewProductoRepositoryGorm(db)
+	ventaRepo := persistence.NewVentaRepositoryGorm(db)
+	precioRepo := persistence.NewPrecioHistoricoRepositoryGorm(db)
+	movimientoRepo := persistence.NewMovimientoStockRepositoryGorm(db)
+	outboxRepo := persistence.NewOutboxRepositoryGorm(db)
+	clienteRepo := persistence.NewClientePotencialRepositoryGorm(db)
+	clienteConvertidoRepo := persistence.NewClienteRepositoryGorm(db)
+
+	// Crear servicios
+	sucursalService := services.NewSucursalService(sucursalRepo)
+	if ruta := os.Getenv("SUCURSALES_FILE"); ruta != "" {
+		if err := cargarSucursales(sucursalService, ruta); err != nil {
+			log.Fatalf("❌ Error cargando sucursales: %v", err)
+		}
+	}
+	procesadorService := services.NewProcesadorDatosService(eventBus, politicaReintento, sucursalRepo, productoRepo, ventaRepo, precioRepo, movimientoRepo)
+	procesadorService.SetTamanoBloque(getEnvInt("PERSISTENCIA_TAMANO_BLOQUE", services.TamanoBloquePorDefecto))
+	procesadorService.SetPoliticaPersistencia(politicaPersistencia)
+
+	// Calcular los impuestos de las ventas con las reglas de IMPUESTOS_REGLAS_FILE o las por defecto
+	if ruta := os.Getenv("IMPUESTOS_REGLAS_FILE"); ruta != "" {
+		reglasImpuestos, err := services.CargarReglasImpuestos(ruta)
+		if err != nil {
+			log.Fatalf("❌ Error cargando las reglas de impuestos: %v", err)
+		}
+		procesadorService.SetReglasImpuestos(reglasImpuestos)
+	}
+
+	// Entregar al bus los eventos guardados en el outbox junto con los datos
+	relayOutbox := services.NewRelayOutbox(outboxRepo, eventBus, time.Duration(getEnvInt("OUTBOX_INTERVAL_SECONDS", 5))*time.Second)
+	relayOutbox.SetMaxIntentos(getEnvInt("OUTBOX_MAX_INTENTOS", services.MaxIntentosOutboxPorDefecto))
+	transacciones := persistence.NewTransaccionesGorm(db)
+	procesadorService.SetOutbox(outboxRepo, transacciones, relayOutbox)
+	clienteService := services.NewClienteService(eventBus, clienteRepo, clienteConvertidoRepo, sucursalRepo)
+	clienteService.SetOutbox(outboxRepo, transacciones, relayOutbox)
+	ventaService := services.NewVentaService(eventBus, ventaRepo, movimientoRepo)
+	ventaService.SetOutbox(outboxRepo, transacciones, relayOutbox)
+
+	// Puntuar los clientes potenciales con el modelo de PUNTAJE_MODELO_FILE o el por defecto
+	var modeloPuntaje *services.ModeloPuntaje
+	if ruta := os.Getenv("PUNTAJE_MODELO_FILE"); ruta != "" {
+		if modeloPuntaje, err = services.CargarModeloPuntaje(ruta); err != nil {
+			log.Fatalf("❌ Error cargando el modelo de puntaje: %v", err)
+		}
+	}
+	puntajeService := services.NewPuntajeClientesService(modeloPuntaje, clienteRepo,
+		time.Duration(getEnvInt("PUNTAJE_INTERVALO_MINUTOS", 60))*time.Minute)
+	for _, tipo := range services.EventosPuntaje {
+		eventBus.Subscribe(tipo, puntajeService.Manejador(tipo))
+	}
+
+	// Archivar cada lote recibido, comprimido y direccionado por su contenido
+	archivoLotesRepo := persistence.NewLoteArchivadoRepositoryGorm(db, getEnv("ARCHIVO_LOTES_DIR", "./archivo_lotes"))
+	procesadorService.SetArchivo(archivoLotesRepo)
+
+	// Purgar los datos vencidos según las políticas de RETENCION_POLITICAS
+	politicasRetencion, err := services.ParsearPoliticasRetencion(os.Getenv("RETENCION_POLITICAS"))
+	if err != nil {
+		log.Fatalf("❌ Error en RETENCION_POLITICAS: %v", err)
+	}
+	retencionService := services.NewRetencionService(politicasRetencion, persistence.NewPurgaRepositoryGorm(db), eventBus,
+		time.Duration(getEnvInt("RETENCION_INTERVALO_MINUTOS", 60))*time.Minute)
+	retencionService.SetTamanoLote(getEnvInt("RETENCION_TAMANO_LOTE", services.TamanoLotePurgaPorDefecto))
+	retencionService.RegistrarClase(services.ClaseLotesCrudos, entities.AccionPurgaEliminar, archivoLotesRepo.PurgarAnteriores)
+	retencionService.RegistrarClase(services.ClaseEventos, entities.AccionPurgaEliminar, outboxRepo.PurgarEntregados)
+	retencionService.RegistrarClase(services.ClaseClientes, entities.AccionPurgaAnonimizar, clienteRepo.AnonimizarAnteriores)
+
+	// Subcomando de retención: retencion purgar
+	if len(os.Args) > 1 && os.Args[1] == "retencion" {
+		if err := ejecutarRetencion(retencionService, os.Args[2:]); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	// Subcomando de lotes: lotes reprocesar [opciones] [lote_id...]
+	if len(os.Args) > 1 && os.Args[1] == "lotes" {
+		if err := ejecutarLotes(procesadorService, os.Args[2:]); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		// Entregar los eventos de los lotes aplicados antes de terminar; el relay
+		// del servidor, si está en marcha, no entrega los que este proceso reclama
+		if _, err := relayOutbox.Entregar(context.Background()); err != nil {
+			log.Printf("⚠️ Eventos del outbox pendientes de entrega: %v", err)
+		}
+		return
+	}
+
+	go func() {
+		if err := relayOutbox.Iniciar(context.Background()); err != nil {
+			log.Printf("❌ Entrega de eventos del outbox detenida: %v", err)
+		}
+	}()
+
+	if len(politicasRetencion) > 0 {
+		go func() {
+			if err := retencionService.Iniciar(context.Background()); err != nil {
+				log.Printf("❌ Purga de datos vencidos detenida: %v", err)
+			}
+		}()
+	}
+
+	go func() {
+		if err := puntajeService.Iniciar(context.Background()); err != nil {
+			log.Printf("❌ Recálculo de puntajes de clientes detenido: %v", err)
+		}
+	}()
+
+	// Iniciar ingesta de archivos desde carpeta compartida (opcional)
+	if inbox := os.Getenv("WATCH_INBOX_DIR"); inbox != "" {
+		reglas, err := watcher.ParsearReglas(os.Getenv("WATCH_REGLAS"))
+		if err != nil {
+			log.Fatalf("❌ Error en reglas de archivos: %v", err)
+		}
+		intervalo := time.Duration(getEnvInt("WATCH_INTERVAL_SECONDS", 10)) * time.Second
+		carpetaWatcher := watcher.NewCarpetaWatcher(inbox, reglas, intervalo, procesadorService, sucursalRepo)
+		go func() {
+			if err := carpetaWatcher.Iniciar(context.Background()); err != nil {
+				log.Printf("❌ Vigilancia de carpeta detenida: %v", err)
+			}
+		}()
+	}
+
 	// Crear handlers
-	// clienteHandler := handlers.NewClienteHandler(db, eventBus)
-	procesamientoHandler := handlers.NewProcesamientoHandler(eventBus)
+	clienteHandler := handlers.NewClienteHandler(clienteService)
+	ventaHandler := handlers.NewVentaHandler(ventaService)
+	procesamientoHandler := handlers.NewProcesamientoHandler(eventBus, procesadorService, sucursalRepo)
+	webhookHandler := handlers.NewWebhookHandler(procesadorService, sucursalRepo)
+	productoHandler := handlers.NewProductoHandler(productoRepo, precioRepo, movimientoRepo)
+	sucursalHandler := handlers.NewSucursalHandler(sucursalService)
+	archivoLotesHandler := handlers.NewArchivoLotesHandler(procesadorService)
+	retencionHandler := handlers.NewRetencionHandler(retencionService)
+	reporteHandler := handlers.NewReporteHandler(services.NewReporteService(ventaRepo, productoRepo, precioRepo))
 
 	// Configurar rutas con HTTP nativo
 	mux := http.NewServeMux()
@@ -82,6 +263,49 @@ func main() {
 		}
 	})
 
+	// Ruta de carga de archivos exportados por sucursales (POST)
+	mux.HandleFunc("/api/procesar/archivo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			procesamientoHandler.ProcesarArchivo(w, r)
+		} else {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Ruta de ingesta en streaming NDJSON (POST)
+	mux.HandleFunc("/api/procesar/stream", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			procesamientoHandler.ProcesarStream(w, r)
+		} else {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Ruta para consultar el estado de un lote encolado (GET)
+	mux.HandleFunc("/api/lotes/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			procesamientoHandler.GetEstadoLote(w, r)
+		} else {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Rutas del archivo de lotes crudos: consulta (GET) y reproceso (POST)
+	mux.HandleFunc("/api/lotes/archivados", archivoLotesHandler.ListarLotesArchivados)
+	mux.HandleFunc("/api/lotes/reprocesar", archivoLotesHandler.ReprocesarLotes)
+
+	// Ruta de las políticas de retención y la auditoría de purgas (GET)
+	mux.HandleFunc("/api/retencion", retencionHandler.GetRetencion)
+
+	// Ruta de webhooks firmados enviados por sucursales (POST)
+	mux.HandleFunc("/api/webhooks/sucursales/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			webhookHandler.RecibirWebhookSucursal(w, r)
+		} else {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Ruta para consultar datos depurados (GET)
 	mux.HandleFunc("/api/datos-procesados", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
@@ -100,6 +324,29 @@ func main() {
 		}
 	})
 
+	// Rutas del historial de precios y los movimientos de stock de productos (GET)
+	mux.HandleFunc("/api/productos/", productoHandler.RutaProductos)
+
+	// Rutas de sucursales (GET, POST y PUT); las credenciales son de solo escritura
+	mux.HandleFunc("/api/sucursales", sucursalHandler.RutaSucursales)
+	mux.HandleFunc("/api/sucursales/", sucursalHandler.RutaSucursales)
+
+	// Rutas de clientes potenciales (GET, POST, PUT y DELETE) y de sus cambios de estado e interacciones (GET y POST)
+	mux.HandleFunc("/api/clientes", clienteHandler.RutaClientes)
+	mux.HandleFunc("/api/clientes/", clienteHandler.RutaClientes)
+
+	// Rutas de consulta de ventas (GET) y de sus cambios de estado (GET y POST)
+	mux.HandleFunc("/api/ventas/", ventaHandler.RutaVentas)
+
+	// Ruta del reporte de ventas por sucursal (GET)
+	mux.HandleFunc("/api/reportes/ventas", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reporteHandler.GetReporteVentas(w, r)
+		} else {
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Ruta de salud
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
@@ -122,8 +369,25 @@ func main() {
 				"description": "API RESTful con arquitectura dirigida por eventos para procesamiento de datos",
 				"endpoints": {
 					"procesar": "/api/procesar",
+					"procesar_archivo": "/api/procesar/archivo",
+					"procesar_stream": "/api/procesar/stream",
+					"lotes": "/api/lotes/{id}",
+					"lotes_archivados": "/api/lotes/archivados",
+					"reprocesar_lotes": "/api/lotes/reprocesar",
+					"webhooks_sucursales": "/api/webhooks/sucursales/{id}",
 					"datos_procesados": "/api/datos-procesados",
 					"reporte": "/api/reporte",
+					"reporte_ventas": "/api/reportes/ventas",
+					"precio_producto": "/api/productos/{sku}/precio",
+					"historial_precios": "/api/productos/{sku}/precios",
+					"movimientos_stock": "/api/productos/{sku}/movimientos",
+					"sucursales": "/api/sucursales",
+					"clientes": "/api/clientes",
+					"transiciones_clientes": "/api/clientes/{id}/transiciones",
+					"interacciones_clientes": "/api/clientes/{id}/interacciones",
+					"ventas": "/api/ventas/{id}",
+					"transiciones_ventas": "/api/ventas/{id}/transiciones",
+					"retencion": "/api/retencion",
 					"health": "/health",
 					"swagger": "/swagger/"
 				}
@@ -156,15 +420,218 @@ func main() {
 	}
 }
 
-// getDSN obtiene la cadena de conexión a la base de datos
-func getDSN() string {
+// getDSN obtiene la cadena de conexión a la base de datos para el driver indicado
+func getDSN(driver string) string {
+	if dsn := os.Getenv("DB_DSN"); dsn != "" {
+		return dsn
+	}
+
+	switch driver {
+	case database.DriverSQLite:
+		// Ruta del archivo, o ":memory:" para una base que se descarta al terminar
+		return getEnv("DB_PATH", "sistema_gestion_informacion.db")
+	case database.DriverPostgres:
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+			getEnv("DB_HOST", "localhost"),
+			getEnv("DB_PORT", "5432"),
+			getEnv("DB_USER", "postgres"),
+			getEnv("DB_PASSWORD", ""),
+			getEnv("DB_NAME", "sistema_gestion_informacion"),
+			getEnv("DB_SSLMODE", "disable"),
+		)
+	}
+
 	host := getEnv("DB_HOST", "localhost")
 	port := getEnv("DB_PORT", "3306")
 	user := getEnv("DB_USER", "root")
 	password := getEnv("DB_PASSWORD", "")
-	database := getEnv("DB_NAME", "sistema_gestion_informacion")
+	dbName := getEnv("DB_NAME", "sistema_gestion_informacion")
+
+	return user + ":" + password + "@tcp(" + host + ":" + port + ")/" + dbName + "?charset=utf8mb4&parseTime=True&loc=Local"
+}
 
-	return user + ":" + password + "@tcp(" + host + ":" + port + ")/" + database + "?charset=utf8mb4&parseTime=True&loc=Local"
+// ejecutarMigraciones implementa el subcomando "migrate up|down [cantidad]|status"
+func ejecutarMigraciones(migrador *database.Migrador, args []string) error {
+	ctx := context.Background()
+	if len(args) == 0 {
+		return fmt.Errorf("uso: migrate up|down [cantidad]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		aplicadas, err := migrador.Subir(ctx)
+		for _, migracion := range aplicadas {
+			log.Printf("⬆️ Aplicada %04d_%s", migracion.Version, migracion.Nombre)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ %d migraciones aplicadas", len(aplicadas))
+
+	case "down":
+		cantidad := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("cantidad inválida: %s", args[1])
+			}
+			cantidad = n
+		}
+		revertidas, err := migrador.Bajar(ctx, cantidad)
+		for _, migracion := range revertidas {
+			log.Printf("⬇️ Revertida %04d_%s", migracion.Version, migracion.Nombre)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("✅ %d migraciones revertidas", len(revertidas))
+
+	case "status":
+		estados, err := migrador.Estado(ctx)
+		if err != nil {
+			return err
+		}
+		for _, estado := range estados {
+			aplicadaEn := "pendiente"
+			if estado.Aplicada {
+				aplicadaEn = estado.AplicadaEn.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d  %-30s %s\n", estado.Version, estado.Nombre, aplicadaEn)
+		}
+
+	default:
+		return fmt.Errorf("subcomando de migrate desconocido: %s (se acepta up, down o status)", args[0])
+	}
+
+	return nil
+}
+
+// cifradorCredenciales crea el cifrador de credenciales con las claves de
+// CREDENCIALES_CLAVES. Sin claves las credenciales se guardan sin cifrar, lo que
+// solo se admite fuera de producción.
+func cifradorCredenciales() (*cifrado.Cifrador, error) {
+	claves, err := cifrado.ParsearClaves(os.Getenv("CREDENCIALES_CLAVES"))
+	if err != nil {
+		return nil, err
+	}
+	if len(claves) == 0 {
+		if os.Getenv("ENVIRONMENT") == "production" {
+			return nil, fmt.Errorf("se requiere al menos una clave en producción")
+		}
+		log.Println("⚠️ CREDENCIALES_CLAVES no configurada: las credenciales de las sucursales se guardan sin cifrar")
+		return nil, nil
+	}
+	return cifrado.NewCifrador(claves)
+}
+
+// ejecutarCredenciales ejecuta el subcomando de credenciales indicado
+func ejecutarCredenciales(sucursales *persistence.SucursalRepositoryGorm, args []string) error {
+	if len(args) == 0 || args[0] != "rotar" {
+		return fmt.Errorf("uso: credenciales rotar")
+	}
+
+	actualizadas, err := sucursales.RecifrarCredenciales(context.Background())
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Credenciales de %d sucursales cifradas con la clave activa", actualizadas)
+	return nil
+}
+
+// ejecutarLotes implementa el subcomando "lotes reprocesar [-aplicar]
+// [-sucursal N] [-tipo T] [-desde F] [-hasta F] [lote_id...]". Sin -aplicar los
+// lotes se simulan y solo se informan las diferencias.
+func ejecutarLotes(procesador *services.ProcesadorDatosService, args []string) error {
+	if len(args) == 0 || args[0] != "reprocesar" {
+		return fmt.Errorf("uso: lotes reprocesar [-aplicar] [-sucursal N] [-tipo T] [-desde F] [-hasta F] [lote_id...]")
+	}
+
+	opciones := flag.NewFlagSet("lotes reprocesar", flag.ContinueOnError)
+	aplicar := opciones.Bool("aplicar", false, "aplicar los cambios en lugar de simularlos")
+	sucursalID := opciones.Uint("sucursal", 0, "ID de la sucursal de los lotes")
+	tipo := opciones.String("tipo", "", "tipo de datos de los lotes (producto, stock, venta)")
+	desde := opciones.String("desde", "", "lotes recibidos desde la fecha (AAAA-MM-DD o RFC3339)")
+	hasta := opciones.String("hasta", "", "lotes recibidos hasta la fecha (AAAA-MM-DD inclusive, o RFC3339)")
+	if err := opciones.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	filtro := repositories.FiltroLotesArchivados{LoteIDs: opciones.Args(), SucursalID: *sucursalID, Tipo: *tipo}
+	var err error
+	if *desde != "" {
+		if filtro.Desde, err = parsearFechaComando(*desde, false); err != nil {
+			return fmt.Errorf("-desde inválido: %v", err)
+		}
+	}
+	if *hasta != "" {
+		if filtro.Hasta, err = parsearFechaComando(*hasta, true); err != nil {
+			return fmt.Errorf("-hasta inválido: %v", err)
+		}
+	}
+
+	reprocesos, err := procesador.ReprocesarLotes(context.Background(), filtro, *aplicar)
+	if err != nil {
+		return err
+	}
+
+	conDiferencias := 0
+	for _, reproceso := range reprocesos {
+		destino := reproceso.LoteID
+		if reproceso.NuevoLoteID != "" {
+			destino += " -> " + reproceso.NuevoLoteID
+		}
+		switch {
+		case reproceso.Error != "":
+			fmt.Printf("%s  error: %s\n", destino, reproceso.Error)
+		case len(reproceso.Diferencias) == 0:
+			fmt.Printf("%s  sin diferencias\n", destino)
+		default:
+			fmt.Printf("%s  %d diferencias\n", destino, len(reproceso.Diferencias))
+		}
+		for _, diferencia := range reproceso.Diferencias {
+			fmt.Printf("    %-13s %6d -> %6d (%+d)\n", diferencia.Campo, diferencia.Antes, diferencia.Despues, diferencia.Diferencia)
+		}
+		if len(reproceso.Diferencias) > 0 {
+			conDiferencias++
+		}
+	}
+
+	accion := "simulados"
+	if *aplicar {
+		accion = "aplicados"
+	}
+	log.Printf("✅ %d lotes %s, %d con diferencias", len(reprocesos), accion, conDiferencias)
+	return nil
+}
+
+// ejecutarRetencion implementa el subcomando "retencion purgar", que aplica una
+// vez las políticas de retención
+func ejecutarRetencion(retencion *services.RetencionService, args []string) error {
+	if len(args) == 0 || args[0] != "purgar" {
+		return fmt.Errorf("uso: retencion purgar")
+	}
+
+	registros, err := retencion.Purgar(context.Background())
+	for _, registro := range registros {
+		fmt.Printf("%-22s %-10s %8d registros antes de %s\n", registro.Clase, registro.Accion, registro.Registros, registro.AntesDe.Format(time.RFC3339))
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Políticas de retención aplicadas: %d clases con datos purgados", len(registros))
+	return nil
+}
+
+// parsearFechaComando interpreta una fecha AAAA-MM-DD o RFC3339. Una fecha de
+// fin sin hora incluye el día completo.
+func parsearFechaComando(valor string, fin bool) (time.Time, error) {
+	if fecha, err := time.Parse("2006-01-02", valor); err == nil {
+		if fin {
+			fecha = fecha.Add(24 * time.Hour)
+		}
+		return fecha, nil
+	}
+	return time.Parse(time.RFC3339, valor)
 }
 
 // getEnv obtiene una variable de entorno con valor por defecto
@@ -175,6 +642,37 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// cargarSucursales carga en el repositorio las sucursales definidas en un archivo JSON
+func cargarSucursales(sucursalService *services.SucursalService, ruta string) error {
+	contenido, err := os.ReadFile(ruta)
+	if err != nil {
+		return err
+	}
+
+	var sucursales []entities.Sucursal
+	if err := json.Unmarshal(contenido, &sucursales); err != nil {
+		return err
+	}
+
+	for i := range sucursales {
+		if err := sucursalService.Guardar(context.Background(), &sucursales[i]); err != nil {
+			return fmt.Errorf("sucursal %q: %v", sucursales[i].Nombre, err)
+		}
+	}
+
+	log.Printf("✅ %d sucursales cargadas desde %s", len(sucursales), ruta)
+	return nil
+}
+
+// getEnvInt obtiene una variable de entorno numérica con valor por defecto
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // registerEventHandlers registra los manejadores de eventos
 func registerEventHandlers(eventBus *events.EventBus) {
 	// Registrar logger de eventos
@@ -190,6 +688,8 @@ func registerEventHandlers(eventBus *events.EventBus) {
 	eventBus.Subscribe(events.EventDatosProcesados, &DatosProcesadosHandler{})
 	eventBus.Subscribe(events.EventDatosPersistidos, &DatosPersistidosHandler{})
 	eventBus.Subscribe(events.EventReporteGenerado, &ReporteGeneradoHandler{})
+	eventBus.Subscribe(events.EventReintentoProgramado, &ReintentoProgramadoHandler{})
+	eventBus.Subscribe(events.EventReintentosAgotados, &ReintentosAgotadosHandler{})
 
 	log.Println("✅ Manejadores de eventos registrados")
 }
@@ -238,3 +738,25 @@ func (h *ReporteGeneradoHandler) Handle(event events.Event) error {
 func (h *ReporteGeneradoHandler) GetEventType() string {
 	return events.EventReporteGenerado
 }
+
+type ReintentoProgramadoHandler struct{}
+
+func (h *ReintentoProgramadoHandler) Handle(event events.Event) error {
+	log.Printf("🔁 Reintento programado: %v", event.Data)
+	return nil
+}
+
+func (h *ReintentoProgramadoHandler) GetEventType() string {
+	return events.EventReintentoProgramado
+}
+
+type ReintentosAgotadosHandler struct{}
+
+func (h *ReintentosAgotadosHandler) Handle(event events.Event) error {
+	log.Printf("⛔ Reintentos agotados: %v", event.Data)
+	return nil
+}
+
+func (h *ReintentosAgotadosHandler) GetEventType() string {
+	return events.EventReintentosAgotados
+}
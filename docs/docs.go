@@ -24,21 +24,68 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/datos-procesados": {
+        "/api/clientes": {
             "get": {
-                "description": "Obtiene los datos procesados y depurados almacenados en memoria",
+                "description": "Obtiene una página de los clientes potenciales, del captado más recientemente al más antiguo o, con orden=puntaje, del de mayor puntaje al de menor. Cada cliente incluye su puntaje y los factores que lo explican",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "procesamiento"
+                    "clientes"
+                ],
+                "summary": "Listar clientes potenciales",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "sucursal_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fuente de captación",
+                        "name": "fuente",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (nuevo, contactado, calificado, convertido, descartado)",
+                        "name": "estado",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "fecha_captura",
+                        "description": "Orden (fecha_captura, puntaje)",
+                        "name": "orden",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Página, desde 1",
+                        "name": "pagina",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Clientes por página, hasta 100",
+                        "name": "tamano_pagina",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Consultar datos procesados",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.DatosProcesadosResponse"
+                            "$ref": "#/definitions/handlers.ClientesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "405": {
@@ -48,11 +95,88 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Registra un cliente potencial en estado nuevo y publica el evento cliente_potencial_creado. Requiere nombre, sucursal existente y email o teléfono; sin fecha_captura se toma la actual",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Registrar un cliente potencial",
+                "parameters": [
+                    {
+                        "description": "Datos del cliente potencial",
+                        "name": "cliente",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ClienteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ClientePotencial"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "/api/procesar": {
-            "post": {
-                "description": "Recibe datos crudos, los procesa y depura, y dispara eventos de notificación",
+        "/api/clientes/{id}": {
+            "get": {
+                "description": "Obtiene el cliente potencial indicado",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Consultar un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.ClientePotencial"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza los datos del cliente potencial. Si fecha_captura se omite se conserva la guardada; el estado cambia solo con POST /api/clientes/{id}/transiciones. Un cliente anonimizado no admite cambios",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,17 +184,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "procesamiento"
+                    "clientes"
                 ],
-                "summary": "Procesar datos crudos",
+                "summary": "Modificar un cliente potencial",
                 "parameters": [
                     {
-                        "description": "Datos crudos a procesar",
-                        "name": "request",
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos del cliente potencial",
+                        "name": "cliente",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.DatosProcesamientoRequest"
+                            "$ref": "#/definitions/handlers.ClienteRequest"
                         }
                     }
                 ],
@@ -78,7 +209,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ProcesamientoResponse"
+                            "$ref": "#/definitions/entities.ClientePotencial"
                         }
                     },
                     "400": {
@@ -87,8 +218,47 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Elimina el cliente potencial indicado",
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Eliminar un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
@@ -96,62 +266,2028 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/reporte": {
+        "/api/clientes/{id}/interacciones": {
             "get": {
-                "description": "Obtiene el último reporte generado usando el patrón Builder",
+                "description": "Obtiene las interacciones del cliente potencial, de la más antigua a la más nueva",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "procesamiento"
+                    "clientes"
+                ],
+                "summary": "Interacciones de un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Obtener último reporte",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ReporteResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.InteraccionCliente"
+                            }
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registra una interacción del cliente potencial, como una visita o una llamada, y publica cliente_potencial_interaccion, que recalcula su puntaje. Sin fecha se toma la actual",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Registrar una interacción de un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos de la interacción",
+                        "name": "interaccion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InteraccionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.InteraccionCliente"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.DatosProcesadosResponse": {
-            "type": "object",
-            "properties": {
-                "productos": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": true
+        },
+        "/api/clientes/{id}/transiciones": {
+            "get": {
+                "description": "Obtiene los cambios de estado del cliente potencial, del más antiguo al más nuevo, con el usuario que los hizo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Historial de estados de un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                },
-                "sucursales": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": true
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.TransicionCliente"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
-                },
-                "ventas": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": true
+                }
+            },
+            "post": {
+                "description": "Pasa el cliente potencial al estado indicado y publica cliente_potencial_estado. Se admite nuevo → contactado → calificado → convertido, y pasar a descartado desde cualquier estado no final. Al pasar a convertido se crea el cliente al que se refieren sus ventas y se publica además cliente_potencial_convertido",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Cambiar el estado de un cliente potencial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del cliente potencial",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Estado nuevo y usuario que lo cambia",
+                        "name": "transicion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TransicionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TransicionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "handlers.DatosProcesamientoRequest": {
-            "type": "object"
+        "/api/datos-procesados": {
+            "get": {
+                "description": "Obtiene los datos procesados y depurados almacenados en memoria",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Consultar datos procesados",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DatosProcesadosResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lotes/archivados": {
+            "get": {
+                "description": "Obtiene los lotes crudos archivados, con su hash de contenido, tamaños y el resultado de su procesamiento",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Listar lotes archivados",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "IDs de lote",
+                        "name": "lote_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "sucursal_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo de datos (producto, stock, venta)",
+                        "name": "tipo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recibidos desde (AAAA-MM-DD o RFC3339)",
+                        "name": "desde",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recibidos hasta (AAAA-MM-DD inclusive, o RFC3339)",
+                        "name": "hasta",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.LoteArchivado"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lotes/reprocesar": {
+            "post": {
+                "description": "Vuelve a procesar los lotes archivados seleccionados con la configuración actual y compara cada resultado con el registrado al procesarlos. Por defecto los cambios se simulan y descartan; con aplicar=true cada lote se procesa como un lote nuevo. No se aplican lotes de ventas ni de movimientos de stock, que se registrarían otra vez",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Reprocesar lotes archivados",
+                "parameters": [
+                    {
+                        "description": "Lotes a reprocesar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReprocesoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReprocesoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lotes/{id}": {
+            "get": {
+                "description": "Obtiene el estado y el resultado de un lote encolado para procesamiento asíncrono. Los lotes terminados se conservan 24 horas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Consultar estado de un lote",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del lote",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.EstadoLote"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/procesar": {
+            "post": {
+                "description": "Recibe datos crudos, los procesa y depura, y dispara eventos de notificación",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Procesar datos crudos",
+                "parameters": [
+                    {
+                        "description": "Datos crudos a procesar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DatosProcesamientoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProcesamientoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/procesar/archivo": {
+            "post": {
+                "description": "Recibe un archivo CSV, XLSX, JSON, NDJSON, XML o de ancho fijo, detecta su formato por contenido o por la configuración de la sucursal y lo encola para procesamiento asíncrono",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Procesar archivo exportado por una sucursal",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Archivo exportado (máximo 20 MB)",
+                        "name": "archivo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal que exporta el archivo",
+                        "name": "sucursal_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo de datos: venta, producto o stock",
+                        "name": "tipo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Codificación de origen (utf-8, windows-1252, iso-8859-1, iso-8859-15 o auto). Por defecto se usa la configurada para la sucursal o se detecta",
+                        "name": "codificacion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoteEncoladoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/procesar/stream": {
+            "post": {
+                "description": "Recibe un objeto JSON por línea, decodifica los registros de a uno, los procesa en chunks y responde el progreso de cada chunk como NDJSON",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Procesar registros en streaming (NDJSON)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal que envía los datos",
+                        "name": "sucursal_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipo de datos: venta, producto o stock",
+                        "name": "tipo",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Registros por chunk (por defecto 1000, máximo 10000)",
+                        "name": "tamanio_chunk",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Una línea por chunk y una línea final con final=true",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ProgresoStreamResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/productos/{sku}/movimientos": {
+            "get": {
+                "description": "Obtiene el historial de movimientos de stock del producto (ventas, devoluciones, ajustes, transferencias y recepciones) y su stock en cada sucursal. La sucursal 0 agrupa el saldo inicial previo al registro de movimientos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "productos"
+                ],
+                "summary": "Consultar los movimientos de stock de un producto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SKU del producto",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Solo los movimientos de esta sucursal",
+                        "name": "sucursal_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MovimientosStockResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/productos/{sku}/precio": {
+            "get": {
+                "description": "Obtiene el precio y la oferta del producto vigentes en la fecha indicada según el historial de precios",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "productos"
+                ],
+                "summary": "Consultar el precio de un producto a una fecha",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SKU del producto",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha (AAAA-MM-DD o RFC3339). Por defecto, el momento actual",
+                        "name": "fecha",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PrecioVigenteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/productos/{sku}/precios": {
+            "get": {
+                "description": "Obtiene todos los cambios de precio del producto con su período de vigencia y su origen",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "productos"
+                ],
+                "summary": "Consultar el historial de precios de un producto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SKU del producto",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HistorialPreciosResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reporte": {
+            "get": {
+                "description": "Obtiene el último reporte generado usando el patrón Builder",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "procesamiento"
+                ],
+                "summary": "Obtener último reporte",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReporteResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reportes/ventas": {
+            "get": {
+                "description": "Genera el reporte de ventas completadas de la sucursal en el período, comparando cada línea con el precio de lista vigente a la fecha de la venta",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reportes"
+                ],
+                "summary": "Generar reporte de ventas por sucursal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "sucursal_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Inicio del período (AAAA-MM-DD o RFC3339)",
+                        "name": "desde",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fin del período (AAAA-MM-DD inclusive, o RFC3339)",
+                        "name": "hasta",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/builders.Reporte"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/retencion": {
+            "get": {
+                "description": "Obtiene las políticas de retención configuradas en RETENCION_POLITICAS y los últimos registros de la auditoría de purgas, del más reciente al más antiguo. Una política inactiva corresponde a una clase sin datos para purgar",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retencion"
+                ],
+                "summary": "Consultar políticas de retención y purgas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Clase de datos (lotes_crudos, eventos, clientes_potenciales)",
+                        "name": "clase",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad máxima de purgas (50 por defecto)",
+                        "name": "limite",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RetencionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sucursales": {
+            "get": {
+                "description": "Obtiene las sucursales registradas. Las credenciales no se incluyen: api_key_configurada y api_secret_configurado indican si están cargadas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sucursales"
+                ],
+                "summary": "Listar sucursales",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.Sucursal"
+                            }
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registra una sucursal. api_key y api_secret se guardan cifrados y nunca se devuelven",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sucursales"
+                ],
+                "summary": "Registrar una sucursal",
+                "parameters": [
+                    {
+                        "description": "Datos de la sucursal",
+                        "name": "sucursal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SucursalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Sucursal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sucursales/{id}": {
+            "get": {
+                "description": "Obtiene la sucursal indicada, sin sus credenciales",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sucursales"
+                ],
+                "summary": "Consultar una sucursal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Sucursal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza los datos de la sucursal. Si api_key o api_secret se omiten se conservan los guardados",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sucursales"
+                ],
+                "summary": "Modificar una sucursal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos de la sucursal",
+                        "name": "sucursal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SucursalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Sucursal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ventas/{id}": {
+            "get": {
+                "description": "Obtiene la venta indicada con sus detalles y las unidades devueltas de cada uno",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ventas"
+                ],
+                "summary": "Consultar una venta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la venta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.Venta"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/ventas/{id}/transiciones": {
+            "get": {
+                "description": "Obtiene los cambios de estado de la venta, del más antiguo al más nuevo, con el usuario que los hizo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ventas"
+                ],
+                "summary": "Historial de estados de una venta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la venta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/entities.TransicionVenta"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Pasa la venta al estado indicado y publica venta_estado. Se admite pendiente → completada o anulada; completada → anulada, devuelta_parcial o devuelta; y devuelta_parcial → devuelta_parcial o devuelta. Completar una venta pendiente egresa su mercadería del stock. Anular una venta completada reingresa al stock las unidades no devueltas; una devolución reingresa las unidades devueltas, que en devuelta_parcial se indican por detalle y en devuelta, si se omiten, son todas las pendientes. Sólo se reingresan las unidades que egresaron por la venta. Cada movimiento publica stock_actualizado",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ventas"
+                ],
+                "summary": "Cambiar el estado de una venta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la venta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Estado nuevo, usuario que lo cambia y unidades devueltas",
+                        "name": "transicion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TransicionVentaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TransicionVentaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/sucursales/{id}": {
+            "post": {
+                "description": "Recibe datos en tiempo real desde el sistema de una sucursal. La firma es el HMAC-SHA256 en hexadecimal de \"\u003ctimestamp\u003e.\u003ccuerpo\u003e\" usando el API secret de la sucursal; el timestamp (segundos Unix) no puede tener más de 5 minutos de antigüedad.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Recibir datos enviados por una sucursal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la sucursal",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp Unix en segundos",
+                        "name": "X-Sucursal-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 hexadecimal, opcionalmente con prefijo sha256=",
+                        "name": "X-Sucursal-Firma",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos enviados por la sucursal",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookSucursalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookSucursalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "builders.Reporte": {
+            "type": "object",
+            "properties": {
+                "datos": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "fecha_fin": {
+                    "type": "string"
+                },
+                "fecha_inicio": {
+                    "type": "string"
+                },
+                "filtros": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "formato": {
+                    "type": "string"
+                },
+                "generado_en": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ordenamiento": {
+                    "type": "string"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Cliente": {
+            "type": "object",
+            "properties": {
+                "cliente_potencial_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "fecha_creacion": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "telefono": {
+                    "type": "string"
+                },
+                "ultima_actualizacion": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ClientePotencial": {
+            "type": "object",
+            "properties": {
+                "anonimizado_en": {
+                    "description": "los datos personales se borraron al vencer su retención",
+                    "type": "string"
+                },
+                "cliente_id": {
+                    "description": "cliente en que se convirtió",
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "estado_actualizado_en": {
+                    "type": "string"
+                },
+                "estado_actualizado_por": {
+                    "type": "string"
+                },
+                "factores_puntaje": {
+                    "description": "explicación del puntaje",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.FactorPuntaje"
+                    }
+                },
+                "fecha_captura": {
+                    "type": "string"
+                },
+                "fecha_creacion": {
+                    "type": "string"
+                },
+                "fuente": {
+                    "description": "'formulario_web', 'referido', 'evento', etc.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interes": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "notas": {
+                    "type": "string"
+                },
+                "puntaje": {
+                    "type": "number"
+                },
+                "puntaje_calculado_en": {
+                    "type": "string"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "telefono": {
+                    "type": "string"
+                },
+                "ultima_actualizacion": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.DetalleVenta": {
+            "type": "object",
+            "properties": {
+                "alicuota_iva": {
+                    "description": "porcentaje: 21, 10.5 o 27",
+                    "type": "number"
+                },
+                "cantidad": {
+                    "type": "integer"
+                },
+                "cantidad_devuelta": {
+                    "description": "unidades devueltas, a lo sumo Cantidad",
+                    "type": "integer"
+                },
+                "descuento": {
+                    "type": "number"
+                },
+                "exento_iva": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "iva": {
+                    "type": "number"
+                },
+                "precio_unitario": {
+                    "type": "number"
+                },
+                "producto": {
+                    "$ref": "#/definitions/entities.Producto"
+                },
+                "producto_id": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "venta_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.DiferenciaResumen": {
+            "type": "object",
+            "properties": {
+                "antes": {
+                    "type": "integer"
+                },
+                "campo": {
+                    "type": "string"
+                },
+                "despues": {
+                    "type": "integer"
+                },
+                "diferencia": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.FactorPuntaje": {
+            "type": "object",
+            "properties": {
+                "explicacion": {
+                    "type": "string"
+                },
+                "factor": {
+                    "description": "'fuente', 'interes', 'recencia', 'interacciones', 'sucursal'",
+                    "type": "string"
+                },
+                "puntos": {
+                    "type": "number"
+                },
+                "valor": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.ImpuestoVenta": {
+            "type": "object",
+            "properties": {
+                "alicuota": {
+                    "description": "porcentaje; 0 en el IVA exento",
+                    "type": "number"
+                },
+                "base_imponible": {
+                    "type": "number"
+                },
+                "concepto": {
+                    "description": "'iva_21', 'iva_exento', 'percepcion_iibb_caba', etc.",
+                    "type": "string"
+                },
+                "descripcion": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "importe": {
+                    "type": "number"
+                },
+                "tipo": {
+                    "description": "'iva', 'percepcion', 'retencion'",
+                    "type": "string"
+                },
+                "venta_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.InteraccionCliente": {
+            "type": "object",
+            "properties": {
+                "cliente_potencial_id": {
+                    "type": "integer"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "description": "'visita', 'llamada', 'email_respondido', etc.",
+                    "type": "string"
+                },
+                "usuario": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.LoteArchivado": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "SHA-256 del contenido sin comprimir",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "origen": {
+                    "type": "string"
+                },
+                "procesado_en": {
+                    "description": "Resultado del procesamiento, para compararlo al reprocesar el lote",
+                    "type": "string"
+                },
+                "recibido_en": {
+                    "type": "string"
+                },
+                "registros": {
+                    "type": "integer"
+                },
+                "reproceso_de": {
+                    "description": "lote archivado del que es un reproceso",
+                    "type": "string"
+                },
+                "resumen": {
+                    "$ref": "#/definitions/entities.ResumenProcesamiento"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "tamano_comprimido": {
+                    "type": "integer"
+                },
+                "tamano_original": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.MovimientoStock": {
+            "type": "object",
+            "properties": {
+                "cantidad": {
+                    "description": "positiva si ingresa stock, negativa si egresa",
+                    "type": "integer"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "producto_id": {
+                    "type": "integer"
+                },
+                "referencia": {
+                    "type": "string"
+                },
+                "stock_resultante": {
+                    "description": "stock total del producto después del movimiento",
+                    "type": "integer"
+                },
+                "sucursal_id": {
+                    "description": "0 para el saldo inicial sin sucursal",
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.PrecioHistorico": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "precio": {
+                    "type": "number"
+                },
+                "precio_anterior": {
+                    "type": "number"
+                },
+                "precio_oferta": {
+                    "type": "number"
+                },
+                "precio_oferta_anterior": {
+                    "type": "number"
+                },
+                "producto_id": {
+                    "type": "integer"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "vigente_desde": {
+                    "type": "string"
+                },
+                "vigente_hasta": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.Producto": {
+            "type": "object",
+            "properties": {
+                "categoria": {
+                    "type": "string"
+                },
+                "descripcion": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "fabricante": {
+                    "type": "string"
+                },
+                "fecha_creacion": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "precio": {
+                    "type": "number"
+                },
+                "precio_oferta": {
+                    "type": "number"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "stock_actual": {
+                    "type": "integer"
+                },
+                "stock_minimo": {
+                    "type": "integer"
+                },
+                "ultima_actualizacion": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.RegistroPurga": {
+            "type": "object",
+            "properties": {
+                "accion": {
+                    "type": "string"
+                },
+                "antes_de": {
+                    "description": "se purgaron los datos anteriores a esta fecha",
+                    "type": "string"
+                },
+                "clase": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finalizada_en": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "iniciada_en": {
+                    "type": "string"
+                },
+                "lotes": {
+                    "description": "tandas en que se purgaron los registros",
+                    "type": "integer"
+                },
+                "registros": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.ResumenProcesamiento": {
+            "type": "object",
+            "properties": {
+                "actualizados": {
+                    "type": "integer"
+                },
+                "fallidos": {
+                    "type": "integer"
+                },
+                "filtrados": {
+                    "type": "integer"
+                },
+                "insertados": {
+                    "type": "integer"
+                },
+                "persistidos": {
+                    "type": "integer"
+                },
+                "sin_cambios": {
+                    "type": "integer"
+                },
+                "unicos": {
+                    "type": "integer"
+                },
+                "validos": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Sucursal": {
+            "type": "object",
+            "properties": {
+                "api_endpoint": {
+                    "description": "Configuración de integración. APIKey y APISecret son de solo escritura: se\nguardan cifradas y no se serializan",
+                    "type": "string"
+                },
+                "api_key_configurada": {
+                    "description": "Indican si hay credenciales configuradas; se completan al serializar",
+                    "type": "boolean"
+                },
+                "api_secret_configurado": {
+                    "type": "boolean"
+                },
+                "ciudad": {
+                    "type": "string"
+                },
+                "configuracion": {
+                    "description": "JSON con configuración específica",
+                    "type": "string"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "fecha_apertura": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "telefono": {
+                    "type": "string"
+                },
+                "tipo_sistema": {
+                    "description": "'api', 'csv', 'excel', 'ancho_fijo', 'xml', 'database'",
+                    "type": "string"
+                },
+                "ultima_sincronizacion": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.TransicionCliente": {
+            "type": "object",
+            "properties": {
+                "cliente_potencial_id": {
+                    "type": "integer"
+                },
+                "desde": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "hasta": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "usuario": {
+                    "type": "string"
+                }
+            }
+        },
+        "entities.TransicionVenta": {
+            "type": "object",
+            "properties": {
+                "desde": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "hasta": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "usuario": {
+                    "type": "string"
+                },
+                "venta_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.Venta": {
+            "type": "object",
+            "properties": {
+                "cliente_id": {
+                    "type": "integer"
+                },
+                "descuento": {
+                    "type": "number"
+                },
+                "detalles_venta": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.DetalleVenta"
+                    }
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "fecha_venta": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "impuestos": {
+                    "description": "IVA y percepciones",
+                    "type": "number"
+                },
+                "impuestos_venta": {
+                    "description": "Desglose de impuestos: el IVA por alícuota y las percepciones y retenciones",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.ImpuestoVenta"
+                    }
+                },
+                "metodo_pago": {
+                    "type": "string"
+                },
+                "retenciones": {
+                    "description": "retenciones practicadas por el cliente",
+                    "type": "number"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.ClienteRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "ana.perez@correo.com"
+                },
+                "fecha_captura": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "fuente": {
+                    "type": "string",
+                    "example": "formulario_web"
+                },
+                "interes": {
+                    "type": "string",
+                    "example": "notebooks"
+                },
+                "nombre": {
+                    "type": "string",
+                    "example": "Ana Pérez"
+                },
+                "notas": {
+                    "type": "string",
+                    "example": "Pidió presupuesto por 10 equipos"
+                },
+                "sucursal_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "telefono": {
+                    "type": "string",
+                    "example": "+54 11 5555-1234"
+                }
+            }
+        },
+        "handlers.ClientesResponse": {
+            "type": "object",
+            "properties": {
+                "clientes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.ClientePotencial"
+                    }
+                },
+                "pagina": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tamano_pagina": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_paginas": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handlers.DatosProcesadosResponse": {
+            "type": "object",
+            "properties": {
+                "productos": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                },
+                "sucursales": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                },
+                "ventas": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                }
+            }
+        },
+        "handlers.DatosProcesamientoRequest": {
+            "type": "object"
+        },
+        "handlers.DevolucionRequest": {
+            "type": "object",
+            "properties": {
+                "cantidad": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "detalle_id": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
         },
         "handlers.ErrorResponse": {
             "type": "object",
@@ -170,6 +2306,116 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.HistorialPreciosResponse": {
+            "type": "object",
+            "properties": {
+                "precios": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.PrecioHistorico"
+                    }
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "PROD-001"
+                }
+            }
+        },
+        "handlers.InteraccionRequest": {
+            "type": "object",
+            "properties": {
+                "fecha": {
+                    "type": "string",
+                    "example": "2024-01-20T16:00:00Z"
+                },
+                "tipo": {
+                    "type": "string",
+                    "example": "visita"
+                },
+                "usuario": {
+                    "type": "string",
+                    "example": "jgomez"
+                }
+            }
+        },
+        "handlers.LoteEncoladoResponse": {
+            "type": "object",
+            "properties": {
+                "codificacion": {
+                    "type": "string",
+                    "example": "windows-1252"
+                },
+                "formato": {
+                    "type": "string",
+                    "example": "csv"
+                },
+                "lote_id": {
+                    "type": "string",
+                    "example": "lote_1705314600000000000"
+                },
+                "registros": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Lote encolado para procesamiento"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                },
+                "url_estado": {
+                    "type": "string",
+                    "example": "/api/lotes/lote_1705314600000000000"
+                }
+            }
+        },
+        "handlers.MovimientosStockResponse": {
+            "type": "object",
+            "properties": {
+                "movimientos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.MovimientoStock"
+                    }
+                },
+                "saldos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SaldoSucursal"
+                    }
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "PROD-001"
+                },
+                "stock_actual": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "handlers.PrecioVigenteResponse": {
+            "type": "object",
+            "properties": {
+                "fecha": {
+                    "type": "string",
+                    "example": "2024-01-15T00:00:00Z"
+                },
+                "precio": {
+                    "$ref": "#/definitions/entities.PrecioHistorico"
+                },
+                "precio_final": {
+                    "type": "number",
+                    "example": 90.45
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "PROD-001"
+                }
+            }
+        },
         "handlers.ProcesamientoResponse": {
             "type": "object",
             "properties": {
@@ -177,13 +2423,52 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Datos procesados y depurados y reporte generado"
                 },
-                "status": {
+                "status": {
+                    "type": "string",
+                    "example": "Proceso completado exitosamente"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                }
+            }
+        },
+        "handlers.ProgresoStreamResponse": {
+            "type": "object",
+            "properties": {
+                "chunk": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
                     "type": "string",
-                    "example": "Proceso completado exitosamente"
+                    "example": ""
                 },
-                "time": {
+                "final": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "lote_id": {
                     "type": "string",
-                    "example": "2024-01-15T10:30:00Z"
+                    "example": "lote_1705314600000000000_1"
+                },
+                "registros": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "registros_acumulados": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "registros_persistidos": {
+                    "type": "integer",
+                    "example": 998
                 }
             }
         },
@@ -233,6 +2518,464 @@ const docTemplate = `{
                     "example": "ventas"
                 }
             }
+        },
+        "handlers.ReprocesoRequest": {
+            "type": "object",
+            "properties": {
+                "aplicar": {
+                    "description": "sin aplicar, los cambios se simulan y descartan",
+                    "type": "boolean",
+                    "example": false
+                },
+                "desde": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "hasta": {
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "lote_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "lote_1705314600000000000"
+                    ]
+                },
+                "sucursal_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tipo": {
+                    "type": "string",
+                    "example": "producto"
+                }
+            }
+        },
+        "handlers.ReprocesoResponse": {
+            "type": "object",
+            "properties": {
+                "aplicado": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "con_diferencias": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "con_errores": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "lotes": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "resultados": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ReprocesoLote"
+                    }
+                }
+            }
+        },
+        "handlers.RetencionResponse": {
+            "type": "object",
+            "properties": {
+                "politicas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.EstadoPolitica"
+                    }
+                },
+                "purgas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.RegistroPurga"
+                    }
+                }
+            }
+        },
+        "handlers.SaldoSucursal": {
+            "type": "object",
+            "properties": {
+                "stock": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "sucursal_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.SucursalRequest": {
+            "type": "object",
+            "properties": {
+                "api_endpoint": {
+                    "type": "string",
+                    "example": "https://api.sucursal.com"
+                },
+                "api_key": {
+                    "type": "string",
+                    "example": "key123"
+                },
+                "api_secret": {
+                    "type": "string",
+                    "example": "secret123"
+                },
+                "ciudad": {
+                    "type": "string",
+                    "example": "Ciudad Principal"
+                },
+                "configuracion": {
+                    "type": "string",
+                    "example": "{\"parametros\":{}}"
+                },
+                "direccion": {
+                    "type": "string",
+                    "example": "Av. Principal 123"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "centro@empresa.com"
+                },
+                "estado": {
+                    "type": "string",
+                    "example": "activa"
+                },
+                "fecha_apertura": {
+                    "type": "string",
+                    "example": "2020-03-01T00:00:00Z"
+                },
+                "nombre": {
+                    "type": "string",
+                    "example": "Sucursal Centro"
+                },
+                "telefono": {
+                    "type": "string",
+                    "example": "+1234567890"
+                },
+                "tipo_sistema": {
+                    "type": "string",
+                    "example": "api"
+                }
+            }
+        },
+        "handlers.TransicionRequest": {
+            "type": "object",
+            "properties": {
+                "estado": {
+                    "type": "string",
+                    "example": "contactado"
+                },
+                "motivo": {
+                    "type": "string",
+                    "example": "Llamado telefónico"
+                },
+                "usuario": {
+                    "type": "string",
+                    "example": "jgomez"
+                }
+            }
+        },
+        "handlers.TransicionResponse": {
+            "type": "object",
+            "properties": {
+                "cliente": {
+                    "$ref": "#/definitions/entities.Cliente"
+                },
+                "cliente_potencial": {
+                    "$ref": "#/definitions/entities.ClientePotencial"
+                },
+                "transicion": {
+                    "$ref": "#/definitions/entities.TransicionCliente"
+                }
+            }
+        },
+        "handlers.TransicionVentaRequest": {
+            "type": "object",
+            "properties": {
+                "devoluciones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.DevolucionRequest"
+                    }
+                },
+                "estado": {
+                    "type": "string",
+                    "example": "devuelta_parcial"
+                },
+                "motivo": {
+                    "type": "string",
+                    "example": "Producto fallado"
+                },
+                "usuario": {
+                    "type": "string",
+                    "example": "jgomez"
+                }
+            }
+        },
+        "handlers.TransicionVentaResponse": {
+            "type": "object",
+            "properties": {
+                "movimientos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.MovimientoStock"
+                    }
+                },
+                "transicion": {
+                    "$ref": "#/definitions/entities.TransicionVenta"
+                },
+                "venta": {
+                    "$ref": "#/definitions/entities.Venta"
+                }
+            }
+        },
+        "handlers.WebhookSucursalRequest": {
+            "type": "object",
+            "properties": {
+                "datos": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": true
+                    }
+                },
+                "tipo": {
+                    "type": "string",
+                    "example": "venta"
+                }
+            }
+        },
+        "handlers.WebhookSucursalResponse": {
+            "type": "object",
+            "properties": {
+                "lote_id": {
+                    "type": "string",
+                    "example": "lote_1705314600000000000"
+                },
+                "registros": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Datos recibidos"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-15T10:30:00Z"
+                }
+            }
+        },
+        "services.AdvertenciaCalidad": {
+            "type": "object",
+            "properties": {
+                "campo": {
+                    "type": "string"
+                },
+                "mensaje": {
+                    "type": "string"
+                },
+                "registro": {
+                    "description": "posición del registro en el lote, desde 1",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.EstadoLote": {
+            "type": "object",
+            "properties": {
+                "actualizado_en": {
+                    "type": "string"
+                },
+                "creado_en": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "origen": {
+                    "type": "string"
+                },
+                "resultado": {
+                    "$ref": "#/definitions/services.ResultadoLote"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.EstadoPolitica": {
+            "type": "object",
+            "properties": {
+                "accion": {
+                    "type": "string",
+                    "example": "eliminar"
+                },
+                "activa": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "clase": {
+                    "type": "string",
+                    "example": "lotes_crudos"
+                },
+                "retencion": {
+                    "type": "string",
+                    "example": "90d"
+                }
+            }
+        },
+        "services.MetricaBloque": {
+            "type": "object",
+            "properties": {
+                "duracion_ms": {
+                    "type": "integer"
+                },
+                "en_bloque": {
+                    "description": "persistidos con la escritura conjunta",
+                    "type": "integer"
+                },
+                "individuales": {
+                    "description": "persistidos de a uno, por no poder escribirse en bloque",
+                    "type": "integer"
+                },
+                "numero": {
+                    "type": "integer"
+                },
+                "persistidos": {
+                    "type": "integer"
+                },
+                "registros": {
+                    "type": "integer"
+                },
+                "registros_por_segundo": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.ReprocesoLote": {
+            "type": "object",
+            "properties": {
+                "antes": {
+                    "description": "nil si el lote no llegó a procesarse",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.ResumenProcesamiento"
+                        }
+                    ]
+                },
+                "aplicado": {
+                    "description": "false si solo se simuló",
+                    "type": "boolean"
+                },
+                "despues": {
+                    "$ref": "#/definitions/entities.ResumenProcesamiento"
+                },
+                "diferencias": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.DiferenciaResumen"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "nuevo_lote_id": {
+                    "description": "lote con el que se aplicó el reproceso",
+                    "type": "string"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ResultadoLote": {
+            "type": "object",
+            "properties": {
+                "advertencias": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.AdvertenciaCalidad"
+                    }
+                },
+                "bloques": {
+                    "description": "Métricas de escritura de cada bloque",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.MetricaBloque"
+                    }
+                },
+                "fin": {
+                    "type": "string"
+                },
+                "inicio": {
+                    "type": "string"
+                },
+                "lote_id": {
+                    "type": "string"
+                },
+                "origen": {
+                    "type": "string"
+                },
+                "registros_actualizados": {
+                    "type": "integer"
+                },
+                "registros_con_advertencias": {
+                    "type": "integer"
+                },
+                "registros_fallidos": {
+                    "type": "integer"
+                },
+                "registros_filtrados": {
+                    "type": "integer"
+                },
+                "registros_insertados": {
+                    "description": "Desglose de los registros persistidos",
+                    "type": "integer"
+                },
+                "registros_persistidos": {
+                    "type": "integer"
+                },
+                "registros_recibidos": {
+                    "type": "integer"
+                },
+                "registros_sin_cambios": {
+                    "type": "integer"
+                },
+                "registros_unicos": {
+                    "type": "integer"
+                },
+                "registros_validos": {
+                    "type": "integer"
+                },
+                "sucursal_id": {
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ErrSinArchivoLotes indica que no hay un archivo de lotes configurado
+var ErrSinArchivoLotes = errors.New("no hay archivo de lotes configurado")
+
+// ErrReprocesoNoAplicable indica que aplicar el reproceso del lote duplicaría sus cambios
+var ErrReprocesoNoAplicable = errors.New("el reproceso del lote no puede aplicarse")
+
+// errSimulacion revierte la transacción en la que se simula un lote
+var errSimulacion = errors.New("simulación de lote: se descartan los cambios")
+
+// claveSimulacion identifica en el contexto a un procesamiento simulado
+type claveSimulacion struct{}
+
+// conSimulacion marca el contexto como el de un procesamiento simulado
+func conSimulacion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, claveSimulacion{}, true)
+}
+
+// esSimulacion indica si el contexto es el de un procesamiento simulado
+func esSimulacion(ctx context.Context) bool {
+	simulacion, _ := ctx.Value(claveSimulacion{}).(bool)
+	return simulacion
+}
+
+// ReprocesoLote compara el resultado de volver a procesar un lote archivado con
+// el que se registró al procesarlo
+type ReprocesoLote struct {
+	LoteID      string `json:"lote_id"`
+	Tipo        string `json:"tipo"`
+	SucursalID  uint   `json:"sucursal_id"`
+	Aplicado    bool   `json:"aplicado"`                // false si solo se simuló
+	NuevoLoteID string `json:"nuevo_lote_id,omitempty"` // lote con el que se aplicó el reproceso
+
+	Antes       *entities.ResumenProcesamiento `json:"antes,omitempty"` // nil si el lote no llegó a procesarse
+	Despues     *entities.ResumenProcesamiento `json:"despues,omitempty"`
+	Diferencias []entities.DiferenciaResumen   `json:"diferencias"`
+	Error       string                         `json:"error,omitempty"`
+}
+
+// SetArchivo hace que cada lote recibido se archive con su resultado, para
+// poder reprocesarlo. Simular un reproceso requiere transacciones que puedan
+// revertirse (ver SetOutbox).
+func (pds *ProcesadorDatosService) SetArchivo(archivo repositories.LoteArchivadoRepository) {
+	pds.archivo = archivo
+}
+
+// archivarLote guarda el contenido del lote en el archivo. Un lote que no puede
+// archivarse se procesa igual.
+func (pds *ProcesadorDatosService) archivarLote(ctx context.Context, datosCrudos *DatosCrudos) bool {
+	if pds.archivo == nil {
+		return false
+	}
+
+	contenido, err := json.Marshal(datosCrudos.Datos)
+	if err != nil {
+		log.Printf("Error archivando lote %s: %v", datosCrudos.LoteID, err)
+		return false
+	}
+
+	recibidoEn := datosCrudos.Timestamp
+	if recibidoEn.IsZero() {
+		recibidoEn = time.Now()
+	}
+	lote := &entities.LoteArchivado{
+		LoteID:      datosCrudos.LoteID,
+		SucursalID:  datosCrudos.SucursalID,
+		Tipo:        datosCrudos.Tipo,
+		Origen:      datosCrudos.Origen,
+		Registros:   len(datosCrudos.Datos),
+		RecibidoEn:  recibidoEn,
+		ReprocesoDe: datosCrudos.ReprocesoDe,
+	}
+	if err := pds.archivo.Archivar(ctx, lote, contenido); err != nil {
+		log.Printf("Error archivando lote %s: %v", datosCrudos.LoteID, err)
+		return false
+	}
+	return true
+}
+
+// registrarResultadoArchivo guarda en el archivo el resultado del procesamiento del lote
+func (pds *ProcesadorDatosService) registrarResultadoArchivo(ctx context.Context, resultado *ResultadoLote, errLote error) {
+	var motivo string
+	if errLote != nil {
+		motivo = errLote.Error()
+	}
+	if err := pds.archivo.RegistrarResultado(ctx, resultado.LoteID, resumenDe(resultado), motivo, time.Now()); err != nil {
+		log.Printf("Error registrando el resultado del lote archivado %s: %v", resultado.LoteID, err)
+	}
+}
+
+// resumenDe retorna las cuentas del resultado que se comparan al reprocesar
+func resumenDe(resultado *ResultadoLote) entities.ResumenProcesamiento {
+	return entities.ResumenProcesamiento{
+		Validos:      resultado.RegistrosValidos,
+		Filtrados:    resultado.RegistrosFiltrados,
+		Unicos:       resultado.RegistrosUnicos,
+		Persistidos:  resultado.RegistrosPersistidos,
+		Insertados:   resultado.RegistrosInsertados,
+		Actualizados: resultado.RegistrosActualizados,
+		SinCambios:   resultado.RegistrosSinCambios,
+		Fallidos:     resultado.RegistrosFallidos,
+	}
+}
+
+// ListarLotesArchivados retorna los lotes archivados que cumplen el filtro
+func (pds *ProcesadorDatosService) ListarLotesArchivados(ctx context.Context, filtro repositories.FiltroLotesArchivados) ([]entities.LoteArchivado, error) {
+	if pds.archivo == nil {
+		return nil, ErrSinArchivoLotes
+	}
+	return pds.archivo.Listar(ctx, filtro)
+}
+
+// ReprocesarLotes vuelve a procesar los lotes archivados que cumplen el filtro
+// con la configuración y los datos actuales, y compara cada resultado con el
+// registrado al procesarlo. Sin aplicar, cada lote se procesa en una
+// transacción que se revierte; al aplicar, se procesa como un lote nuevo que
+// referencia al archivado.
+func (pds *ProcesadorDatosService) ReprocesarLotes(ctx context.Context, filtro repositories.FiltroLotesArchivados, aplicar bool) ([]ReprocesoLote, error) {
+	if pds.archivo == nil {
+		return nil, ErrSinArchivoLotes
+	}
+	if len(filtro.LoteIDs) == 0 && filtro.SucursalID == 0 && filtro.Tipo == "" && filtro.Desde.IsZero() && filtro.Hasta.IsZero() {
+		return nil, fmt.Errorf("%w: indique los lotes, la sucursal, el tipo o el período a reprocesar", ErrDatosInvalidos)
+	}
+	if !aplicar && pds.transacciones == nil {
+		return nil, fmt.Errorf("la simulación de lotes requiere transacciones configuradas")
+	}
+
+	lotes, err := pds.archivo.Listar(ctx, filtro)
+	if err != nil {
+		return nil, err
+	}
+
+	encontrados := make(map[string]bool, len(lotes))
+	reprocesos := make([]ReprocesoLote, 0, len(lotes))
+	for _, lote := range lotes {
+		encontrados[lote.LoteID] = true
+		reprocesos = append(reprocesos, pds.reprocesarLote(ctx, lote, aplicar))
+	}
+	for _, loteID := range filtro.LoteIDs {
+		if !encontrados[loteID] {
+			reprocesos = append(reprocesos, ReprocesoLote{LoteID: loteID, Diferencias: []entities.DiferenciaResumen{}, Error: "lote no archivado"})
+		}
+	}
+	return reprocesos, nil
+}
+
+// reprocesarLote vuelve a procesar un lote archivado, simulando o aplicando los cambios
+func (pds *ProcesadorDatosService) reprocesarLote(ctx context.Context, lote entities.LoteArchivado, aplicar bool) ReprocesoLote {
+	reproceso := ReprocesoLote{
+		LoteID:      lote.LoteID,
+		Tipo:        lote.Tipo,
+		SucursalID:  lote.SucursalID,
+		Diferencias: []entities.DiferenciaResumen{},
+	}
+	var antes entities.ResumenProcesamiento
+	if lote.ProcesadoEn != nil {
+		antes = lote.Resumen
+		reproceso.Antes = &antes
+	}
+
+	contenido, err := pds.archivo.LeerContenido(ctx, lote.Hash)
+	if err != nil {
+		reproceso.Error = fmt.Sprintf("error leyendo el contenido archivado: %v", err)
+		return reproceso
+	}
+	var datos []map[string]interface{}
+	if err := json.Unmarshal(contenido, &datos); err != nil {
+		reproceso.Error = fmt.Sprintf("contenido archivado inválido: %v", err)
+		return reproceso
+	}
+
+	datosCrudos := &DatosCrudos{
+		LoteID:     lote.LoteID,
+		Origen:     lote.Origen,
+		Tipo:       lote.Tipo,
+		Datos:      datos,
+		Timestamp:  lote.RecibidoEn,
+		SucursalID: lote.SucursalID,
+	}
+
+	var resultado *ResultadoLote
+	if aplicar {
+		if err := reaplicable(lote.Tipo, datos); err != nil {
+			reproceso.Error = err.Error()
+			return reproceso
+		}
+		datosCrudos.LoteID = NuevoLoteID()
+		datosCrudos.Timestamp = time.Now()
+		datosCrudos.ReprocesoDe = lote.LoteID
+		reproceso.Aplicado = true
+		reproceso.NuevoLoteID = datosCrudos.LoteID
+		resultado, err = pds.ProcesarLote(ctx, datosCrudos)
+	} else {
+		resultado, err = pds.simularLote(ctx, datosCrudos)
+	}
+
+	if resultado != nil {
+		despues := resumenDe(resultado)
+		reproceso.Despues = &despues
+		reproceso.Diferencias = antes.Diferencias(despues)
+	}
+	if err != nil {
+		reproceso.Error = err.Error()
+	}
+	return reproceso
+}
+
+// simularLote procesa el lote en una transacción que luego se revierte, sin
+// publicar sus eventos
+func (pds *ProcesadorDatosService) simularLote(ctx context.Context, datosCrudos *DatosCrudos) (*ResultadoLote, error) {
+	var resultado *ResultadoLote
+	var errLote error
+
+	err := pds.transacciones.Ejecutar(conSimulacion(ctx), func(ctx context.Context) error {
+		resultado, errLote = pds.procesarLote(ctx, datosCrudos)
+		return errSimulacion
+	})
+	if !errors.Is(err, errSimulacion) {
+		return resultado, err
+	}
+	return resultado, errLote
+}
+
+// reaplicable verifica que procesar de nuevo el lote no duplique sus cambios:
+// las ventas y los movimientos de stock se registrarían otra vez, mientras que
+// los productos y los conteos de stock convergen al mismo estado
+func reaplicable(tipo string, datos []map[string]interface{}) error {
+	switch tipo {
+	case "venta":
+		return fmt.Errorf("%w: las ventas del lote se registrarían otra vez", ErrReprocesoNoAplicable)
+	case "stock":
+		for _, dato := range datos {
+			if registro, err := mapearStock(dato); err == nil && !registro.esConteo() {
+				return fmt.Errorf("%w: los movimientos de stock del lote se registrarían otra vez", ErrReprocesoNoAplicable)
+			}
+		}
+	}
+	return nil
+}
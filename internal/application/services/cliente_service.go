@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+)
+
+// Tamaños de página del listado de clientes potenciales
+const (
+	TamanoPaginaClientesPorDefecto = 20
+	TamanoPaginaClientesMaximo     = 100
+)
+
+// PaginaClientes es una página del listado de clientes potenciales
+type PaginaClientes struct {
+	Clientes     []entities.ClientePotencial
+	Total        int64
+	Pagina       int
+	TamanoPagina int
+}
+
+// TotalPaginas retorna la cantidad de páginas del listado
+func (p *PaginaClientes) TotalPaginas() int {
+	return int((p.Total + int64(p.TamanoPagina) - 1) / int64(p.TamanoPagina))
+}
+
+// ErrTransicionInvalida indica que el cliente potencial o la venta no puede pasar al estado pedido
+var ErrTransicionInvalida = errors.New("cambio de estado inválido")
+
+// ErrClienteAnonimizado indica que los datos personales del cliente potencial
+// se borraron al vencer su retención y no pueden volver a cargarse
+var ErrClienteAnonimizado = errors.New("el cliente potencial fue anonimizado y no admite cambios")
+
+// ClienteService implementa la gestión de clientes potenciales y su conversión en clientes
+type ClienteService struct {
+	emisorEventos
+	repo       repositories.ClientePotencialRepository
+	clientes   repositories.ClienteRepository
+	sucursales repositories.SucursalRepository
+}
+
+// ResultadoTransicion es el cliente potencial después de cambiar de estado, con
+// la transición registrada y, si se convirtió, el cliente creado
+type ResultadoTransicion struct {
+	ClientePotencial *entities.ClientePotencial
+	Transicion       *entities.TransicionCliente
+	Cliente          *entities.Cliente
+}
+
+// NewClienteService crea una nueva instancia del servicio
+func NewClienteService(
+	eventBus *events.EventBus,
+	repo repositories.ClientePotencialRepository,
+	clientes repositories.ClienteRepository,
+	sucursales repositories.SucursalRepository,
+) *ClienteService {
+	return &ClienteService{
+		emisorEventos: emisorEventos{eventBus: eventBus, origen: "clientes"},
+		repo:          repo,
+		clientes:      clientes,
+		sucursales:    sucursales,
+	}
+}
+
+// Crear valida y guarda un cliente potencial nuevo y emite
+// EventClientePotencialCreado en la misma transacción. El cliente queda en
+// estado nuevo y, sin fecha de captura, se toma la actual.
+func (cs *ClienteService) Crear(ctx context.Context, cliente *entities.ClientePotencial) error {
+	cliente.ID = 0
+	cliente.Estado = entities.EstadoClienteNuevo
+	cliente.EstadoActualizadoEn = nil
+	cliente.EstadoActualizadoPor = ""
+	cliente.ClienteID = nil
+	cliente.AnonimizadoEn = nil
+	cliente.Normalizar()
+	if cliente.FechaCaptura.IsZero() {
+		cliente.FechaCaptura = time.Now()
+	}
+	if err := cs.validar(ctx, cliente); err != nil {
+		return err
+	}
+
+	return cs.enTransaccion(ctx, func(ctx context.Context) error {
+		if err := cs.repo.Guardar(ctx, cliente); err != nil {
+			return err
+		}
+		return cs.emitir(ctx, events.EventClientePotencialCreado, map[string]interface{}{
+			"cliente_potencial_id": cliente.ID,
+			"sucursal_id":          cliente.SucursalID,
+			"fuente":               cliente.Fuente,
+			"estado":               cliente.Estado,
+			"fecha_captura":        cliente.FechaCaptura,
+		})
+	})
+}
+
+// ObtenerPorID retorna el cliente potencial con el ID indicado
+func (cs *ClienteService) ObtenerPorID(ctx context.Context, id uint) (*entities.ClientePotencial, error) {
+	return cs.repo.ObtenerPorID(ctx, id)
+}
+
+// Listar retorna la página indicada, desde 1, de los clientes que cumplen el
+// filtro. Un tamaño de página no positivo toma el valor por defecto y uno mayor
+// al máximo se recorta.
+func (cs *ClienteService) Listar(ctx context.Context, filtro repositories.FiltroClientes, pagina, tamanoPagina int) (*PaginaClientes, error) {
+	if filtro.Estado != "" && !entities.EsEstadoCliente(filtro.Estado) {
+		return nil, fmt.Errorf("%w: estado desconocido %q", ErrDatosInvalidos, filtro.Estado)
+	}
+	switch filtro.Orden {
+	case "", repositories.OrdenClientesCaptura, repositories.OrdenClientesPuntaje:
+	default:
+		return nil, fmt.Errorf("%w: orden desconocido %q (se admite %s o %s)", ErrDatosInvalidos, filtro.Orden,
+			repositories.OrdenClientesCaptura, repositories.OrdenClientesPuntaje)
+	}
+	if pagina < 1 {
+		pagina = 1
+	}
+	if tamanoPagina <= 0 {
+		tamanoPagina = TamanoPaginaClientesPorDefecto
+	}
+	tamanoPagina = min(tamanoPagina, TamanoPaginaClientesMaximo)
+
+	filtro.Desplazamiento = (pagina - 1) * tamanoPagina
+	filtro.Limite = tamanoPagina
+	clientes, total, err := cs.repo.Listar(ctx, filtro)
+	if err != nil {
+		return nil, err
+	}
+	if clientes == nil {
+		clientes = []entities.ClientePotencial{}
+	}
+	return &PaginaClientes{Clientes: clientes, Total: total, Pagina: pagina, TamanoPagina: tamanoPagina}, nil
+}
+
+// Actualizar reemplaza los datos editables de un cliente potencial existente y
+// emite EventClientePotencialActualizado en la misma transacción. Si no se
+// informa, se conserva la fecha de captura guardada. El estado no se modifica:
+// cambia solo con Transicionar. Un cliente anonimizado no admite cambios.
+func (cs *ClienteService) Actualizar(ctx context.Context, cliente *entities.ClientePotencial) error {
+	return cs.enTransaccion(ctx, func(ctx context.Context) error {
+		existente, err := cs.repo.ObtenerPorID(ctx, cliente.ID)
+		if err != nil {
+			return err
+		}
+		if existente.AnonimizadoEn != nil {
+			return ErrClienteAnonimizado
+		}
+
+		cliente.Normalizar()
+		if cliente.FechaCaptura.IsZero() {
+			cliente.FechaCaptura = existente.FechaCaptura
+		}
+		cliente.Estado = existente.Estado
+		cliente.EstadoActualizadoEn = existente.EstadoActualizadoEn
+		cliente.EstadoActualizadoPor = existente.EstadoActualizadoPor
+		cliente.ClienteID = existente.ClienteID
+		cliente.Puntaje = existente.Puntaje
+		cliente.FactoresPuntaje = existente.FactoresPuntaje
+		cliente.PuntajeCalculadoEn = existente.PuntajeCalculadoEn
+		cliente.FechaCreacion = existente.FechaCreacion
+		if err := cs.validar(ctx, cliente); err != nil {
+			return err
+		}
+
+		if err := cs.repo.ActualizarDatos(ctx, cliente); err != nil {
+			return err
+		}
+		return cs.emitir(ctx, events.EventClientePotencialActualizado, map[string]interface{}{
+			"cliente_potencial_id": cliente.ID,
+			"sucursal_id":          cliente.SucursalID,
+			"fuente":               cliente.Fuente,
+		})
+	})
+}
+
+// Eliminar borra el cliente potencial con el ID indicado
+func (cs *ClienteService) Eliminar(ctx context.Context, id uint) error {
+	return cs.repo.Eliminar(ctx, id)
+}
+
+// Transicionar pasa el cliente potencial al estado indicado en nombre del
+// usuario, lo registra en su historial y emite EventClientePotencialEstado en
+// la misma transacción. Al pasar a convertido crea el cliente que lo sucede, al
+// que se refieren sus ventas, y emite además EventClientePotencialConvertido.
+func (cs *ClienteService) Transicionar(ctx context.Context, id uint, estado, usuario, motivo string) (*ResultadoTransicion, error) {
+	estado = strings.ToLower(strings.TrimSpace(estado))
+	usuario = strings.TrimSpace(usuario)
+	if usuario == "" {
+		return nil, fmt.Errorf("%w: el usuario es requerido", ErrDatosInvalidos)
+	}
+	if !entities.EsEstadoCliente(estado) {
+		return nil, fmt.Errorf("%w: estado desconocido %q", ErrDatosInvalidos, estado)
+	}
+
+	var resultado *ResultadoTransicion
+	err := cs.enTransaccion(ctx, func(ctx context.Context) error {
+		cliente, err := cs.repo.ObtenerPorID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if estado == entities.EstadoClienteConvertido && cliente.AnonimizadoEn != nil {
+			return fmt.Errorf("%w: el cliente potencial fue anonimizado y no puede convertirse", ErrTransicionInvalida)
+		}
+
+		desde := cliente.Estado
+		enEstadoDesde := cliente.UltimoCambioEstado()
+		transicion, err := cliente.Transicionar(estado, usuario, strings.TrimSpace(motivo), time.Now())
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrTransicionInvalida, err)
+		}
+		resultado = &ResultadoTransicion{ClientePotencial: cliente, Transicion: transicion}
+
+		if estado == entities.EstadoClienteConvertido {
+			resultado.Cliente = cliente.NuevoCliente()
+			if err := cs.clientes.Guardar(ctx, resultado.Cliente); err != nil {
+				return err
+			}
+			cliente.ClienteID = &resultado.Cliente.ID
+		}
+		if err := cs.repo.CambiarEstado(ctx, cliente, desde); err != nil {
+			return err
+		}
+		if err := cs.repo.RegistrarTransicion(ctx, transicion); err != nil {
+			return err
+		}
+
+		err = cs.emitir(ctx, events.EventClientePotencialEstado, map[string]interface{}{
+			"cliente_potencial_id": cliente.ID,
+			"sucursal_id":          cliente.SucursalID,
+			"fuente":               cliente.Fuente,
+			"desde":                transicion.Desde,
+			"hasta":                transicion.Hasta,
+			"usuario":              transicion.Usuario,
+			"fecha":                transicion.Fecha,
+			"segundos_en_estado":   int64(transicion.Fecha.Sub(enEstadoDesde).Seconds()),
+		})
+		if err != nil || resultado.Cliente == nil {
+			return err
+		}
+		return cs.emitir(ctx, events.EventClientePotencialConvertido, map[string]interface{}{
+			"cliente_potencial_id": cliente.ID,
+			"cliente_id":           resultado.Cliente.ID,
+			"sucursal_id":          cliente.SucursalID,
+			"fuente":               cliente.Fuente,
+			"fecha_captura":        cliente.FechaCaptura,
+			"fecha":                transicion.Fecha,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// ListarTransiciones retorna el historial de estados del cliente potencial
+func (cs *ClienteService) ListarTransiciones(ctx context.Context, id uint) ([]entities.TransicionCliente, error) {
+	if _, err := cs.repo.ObtenerPorID(ctx, id); err != nil {
+		return nil, err
+	}
+	transiciones, err := cs.repo.ListarTransiciones(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transiciones == nil {
+		transiciones = []entities.TransicionCliente{}
+	}
+	return transiciones, nil
+}
+
+// RegistrarInteraccion agrega una interacción del cliente potencial a su
+// historial y emite EventClientePotencialInteraccion en la misma transacción.
+// Sin fecha se toma la actual.
+func (cs *ClienteService) RegistrarInteraccion(ctx context.Context, interaccion *entities.InteraccionCliente) error {
+	interaccion.ID = 0
+	interaccion.Tipo = strings.ToLower(strings.TrimSpace(interaccion.Tipo))
+	interaccion.Usuario = strings.TrimSpace(interaccion.Usuario)
+	if interaccion.Tipo == "" {
+		return fmt.Errorf("%w: el tipo de interacción es requerido", ErrDatosInvalidos)
+	}
+	if interaccion.Fecha.IsZero() {
+		interaccion.Fecha = time.Now()
+	}
+
+	return cs.enTransaccion(ctx, func(ctx context.Context) error {
+		cliente, err := cs.repo.ObtenerPorID(ctx, interaccion.ClientePotencialID)
+		if err != nil {
+			return err
+		}
+		if err := cs.repo.RegistrarInteraccion(ctx, interaccion); err != nil {
+			return err
+		}
+		return cs.emitir(ctx, events.EventClientePotencialInteraccion, map[string]interface{}{
+			"cliente_potencial_id": cliente.ID,
+			"sucursal_id":          cliente.SucursalID,
+			"tipo":                 interaccion.Tipo,
+			"fecha":                interaccion.Fecha,
+		})
+	})
+}
+
+// ListarInteracciones retorna las interacciones del cliente potencial
+func (cs *ClienteService) ListarInteracciones(ctx context.Context, id uint) ([]entities.InteraccionCliente, error) {
+	if _, err := cs.repo.ObtenerPorID(ctx, id); err != nil {
+		return nil, err
+	}
+	interacciones, err := cs.repo.ListarInteracciones(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if interacciones == nil {
+		interacciones = []entities.InteraccionCliente{}
+	}
+	return interacciones, nil
+}
+
+// validar verifica los datos del cliente y que su sucursal exista
+func (cs *ClienteService) validar(ctx context.Context, cliente *entities.ClientePotencial) error {
+	if err := cliente.Validar(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatosInvalidos, err)
+	}
+	if _, err := cs.sucursales.ObtenerPorID(ctx, cliente.SucursalID); err != nil {
+		if errors.Is(err, repositories.ErrNoEncontrado) {
+			return fmt.Errorf("%w: la sucursal %d no existe", ErrDatosInvalidos, cliente.SucursalID)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// Alícuotas de IVA que admiten las reglas de impuestos
+const (
+	AlicuotaIVAGeneral      = "21"
+	AlicuotaIVAReducida     = "10.5"
+	AlicuotaIVAIncrementada = "27"
+	AlicuotaIVAExento       = "exento"
+)
+
+// AlicuotasIVA enumera las alícuotas de IVA que admiten las reglas de impuestos
+var AlicuotasIVA = []string{AlicuotaIVAGeneral, AlicuotaIVAReducida, AlicuotaIVAIncrementada, AlicuotaIVAExento}
+
+// ReglasImpuestos definen el IVA de cada línea de una venta según la categoría
+// de su producto y la sucursal, y las percepciones y retenciones que se le
+// aplican. Las reglas de una sucursal prevalecen sobre las de su jurisdicción,
+// y éstas sobre las generales; en cada nivel la alícuota de la categoría
+// prevalece sobre la del nivel.
+type ReglasImpuestos struct {
+	// Reglas generales; la alícuota general es obligatoria
+	ReglaImpuestos
+	// Jurisdicciones agrupa sucursales con reglas comunes, como una provincia
+	Jurisdicciones map[string]JurisdiccionImpuestos `json:"jurisdicciones,omitempty"`
+	// Sucursales asigna reglas propias a cada sucursal
+	Sucursales map[uint]ReglaImpuestos `json:"sucursales,omitempty"`
+
+	// jurisdiccion de cada sucursal, armada al validar
+	jurisdiccion map[uint]string
+}
+
+// ReglaImpuestos es un nivel de las reglas de impuestos. Las alícuotas son una
+// de AlicuotasIVA; vacía, la decide el nivel siguiente.
+type ReglaImpuestos struct {
+	Alicuota     string            `json:"alicuota,omitempty"`
+	Categorias   map[string]string `json:"categorias,omitempty"`
+	Percepciones []ReglaPercepcion `json:"percepciones,omitempty"`
+}
+
+// JurisdiccionImpuestos son las reglas de impuestos de las sucursales de una jurisdicción
+type JurisdiccionImpuestos struct {
+	ReglaImpuestos
+	Sucursales []uint `json:"sucursales"`
+}
+
+// ReglaPercepcion es una percepción o retención del Alicuota por ciento sobre
+// el neto gravado de la venta, que se aplica si éste alcanza MinimoImponible.
+// Una regla de un nivel más específico reemplaza a la del mismo concepto.
+type ReglaPercepcion struct {
+	Tipo            string  `json:"tipo"`     // 'percepcion' o 'retencion'
+	Concepto        string  `json:"concepto"` // 'percepcion_iibb_caba', 'retencion_ganancias', etc.
+	Descripcion     string  `json:"descripcion"`
+	Alicuota        float64 `json:"alicuota"`
+	MinimoImponible float64 `json:"minimo_imponible"`
+}
+
+// ReglasImpuestosPorDefecto retorna las reglas que se usan si no se configuran
+// otras: IVA general para todas las ventas, sin percepciones
+func ReglasImpuestosPorDefecto() *ReglasImpuestos {
+	return &ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: AlicuotaIVAGeneral}}
+}
+
+// CargarReglasImpuestos lee las reglas de impuestos de un archivo JSON
+func CargarReglasImpuestos(ruta string) (*ReglasImpuestos, error) {
+	contenido, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, err
+	}
+
+	var reglas ReglasImpuestos
+	if err := json.Unmarshal(contenido, &reglas); err != nil {
+		return nil, fmt.Errorf("reglas de impuestos inválidas: %v", err)
+	}
+	if err := reglas.Validar(); err != nil {
+		return nil, err
+	}
+	return &reglas, nil
+}
+
+// Validar verifica que las reglas puedan aplicarse y que cada sucursal
+// pertenezca a lo sumo a una jurisdicción. Normaliza a minúsculas las
+// categorías y los conceptos.
+func (r *ReglasImpuestos) Validar() error {
+	var errs []error
+	if r.Alicuota == "" {
+		errs = append(errs, errors.New("la alícuota general es requerida"))
+	}
+	errs = append(errs, r.ReglaImpuestos.validar("general")...)
+
+	r.jurisdiccion = make(map[uint]string)
+	for nombre, jurisdiccion := range r.Jurisdicciones {
+		errs = append(errs, jurisdiccion.validar("jurisdicción "+nombre)...)
+		for _, sucursalID := range jurisdiccion.Sucursales {
+			if otra, existe := r.jurisdiccion[sucursalID]; existe {
+				errs = append(errs, fmt.Errorf("la sucursal %d está en las jurisdicciones %s y %s", sucursalID, otra, nombre))
+			}
+			r.jurisdiccion[sucursalID] = nombre
+		}
+		r.Jurisdicciones[nombre] = jurisdiccion
+	}
+	for sucursalID, regla := range r.Sucursales {
+		errs = append(errs, regla.validar(fmt.Sprintf("sucursal %d", sucursalID))...)
+		r.Sucursales[sucursalID] = regla
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reglas de impuestos inválidas: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validar verifica las alícuotas y percepciones del nivel y normaliza sus claves
+func (r *ReglaImpuestos) validar(nivel string) []error {
+	var errs []error
+	if r.Alicuota != "" && !esAlicuotaIVA(r.Alicuota) {
+		errs = append(errs, fmt.Errorf("%s: alícuota desconocida %q (se admite %s)", nivel, r.Alicuota, strings.Join(AlicuotasIVA, ", ")))
+	}
+
+	categorias := make(map[string]string, len(r.Categorias))
+	for categoria, alicuota := range r.Categorias {
+		if !esAlicuotaIVA(alicuota) {
+			errs = append(errs, fmt.Errorf("%s: alícuota desconocida %q para la categoría %s (se admite %s)", nivel, alicuota, categoria, strings.Join(AlicuotasIVA, ", ")))
+		}
+		categorias[normalizarCategoria(categoria)] = alicuota
+	}
+	r.Categorias = categorias
+
+	for i := range r.Percepciones {
+		percepcion := &r.Percepciones[i]
+		percepcion.Tipo = strings.ToLower(strings.TrimSpace(percepcion.Tipo))
+		percepcion.Concepto = strings.ToLower(strings.TrimSpace(percepcion.Concepto))
+		if percepcion.Tipo != entities.ImpuestoPercepcion && percepcion.Tipo != entities.ImpuestoRetencion {
+			errs = append(errs, fmt.Errorf("%s: tipo de percepción desconocido %q (se admite %s o %s)", nivel, percepcion.Tipo, entities.ImpuestoPercepcion, entities.ImpuestoRetencion))
+		}
+		if percepcion.Concepto == "" {
+			errs = append(errs, fmt.Errorf("%s: la percepción %d requiere concepto", nivel, i+1))
+		}
+		if percepcion.Alicuota <= 0 || percepcion.Alicuota > 100 {
+			errs = append(errs, fmt.Errorf("%s: la alícuota de %s debe estar entre 0 y 100", nivel, percepcion.Concepto))
+		}
+		if percepcion.MinimoImponible < 0 {
+			errs = append(errs, fmt.Errorf("%s: el mínimo imponible de %s no puede ser negativo", nivel, percepcion.Concepto))
+		}
+	}
+	return errs
+}
+
+// Aplicar asigna a cada detalle de la venta la alícuota de IVA que corresponde
+// a la categoría de su producto, en el mismo orden que los detalles, y a la
+// sucursal de la venta, determina sus percepciones y retenciones y calcula
+// sus totales
+func (r *ReglasImpuestos) Aplicar(venta *entities.Venta, categorias []string) {
+	for i := range venta.DetallesVenta {
+		categoria := ""
+		if i < len(categorias) {
+			categoria = categorias[i]
+		}
+		alicuota := r.alicuota(venta.SucursalID, categoria)
+		detalle := &venta.DetallesVenta[i]
+		detalle.ExentoIVA = alicuota == AlicuotaIVAExento
+		detalle.AlicuotaIVA = 0
+		if !detalle.ExentoIVA {
+			detalle.AlicuotaIVA, _ = strconv.ParseFloat(alicuota, 64)
+		}
+	}
+	venta.QuitarPercepciones()
+
+	base := venta.NetoGravado()
+	for _, percepcion := range r.percepciones(venta.SucursalID) {
+		if base <= 0 || base < percepcion.MinimoImponible {
+			continue
+		}
+		venta.AgregarPercepcion(entities.ImpuestoVenta{
+			Tipo:          percepcion.Tipo,
+			Concepto:      percepcion.Concepto,
+			Descripcion:   percepcion.Descripcion,
+			Alicuota:      percepcion.Alicuota,
+			BaseImponible: base,
+			Importe:       base * percepcion.Alicuota / 100,
+		})
+	}
+}
+
+// niveles retorna las reglas que alcanzan a la sucursal, de la más específica
+// a la general
+func (r *ReglasImpuestos) niveles(sucursalID uint) []ReglaImpuestos {
+	var niveles []ReglaImpuestos
+	if regla, existe := r.Sucursales[sucursalID]; existe {
+		niveles = append(niveles, regla)
+	}
+	if nombre, existe := r.jurisdiccion[sucursalID]; existe {
+		niveles = append(niveles, r.Jurisdicciones[nombre].ReglaImpuestos)
+	}
+	return append(niveles, r.ReglaImpuestos)
+}
+
+// alicuota retorna la alícuota de IVA de un producto de la categoría vendido en la sucursal
+func (r *ReglasImpuestos) alicuota(sucursalID uint, categoria string) string {
+	categoria = normalizarCategoria(categoria)
+	for _, nivel := range r.niveles(sucursalID) {
+		if alicuota, existe := nivel.Categorias[categoria]; existe && categoria != "" {
+			return alicuota
+		}
+		if nivel.Alicuota != "" {
+			return nivel.Alicuota
+		}
+	}
+	return AlicuotaIVAGeneral
+}
+
+// percepciones retorna las percepciones y retenciones de la sucursal, una por
+// concepto, en el orden en que las declaran las reglas generales, las de su
+// jurisdicción y las propias
+func (r *ReglasImpuestos) percepciones(sucursalID uint) []ReglaPercepcion {
+	niveles := r.niveles(sucursalID)
+	var percepciones []ReglaPercepcion
+	posicion := make(map[string]int)
+	for i := len(niveles) - 1; i >= 0; i-- {
+		for _, percepcion := range niveles[i].Percepciones {
+			if j, existe := posicion[percepcion.Concepto]; existe {
+				percepciones[j] = percepcion
+				continue
+			}
+			posicion[percepcion.Concepto] = len(percepciones)
+			percepciones = append(percepciones, percepcion)
+		}
+	}
+	return percepciones
+}
+
+func esAlicuotaIVA(alicuota string) bool {
+	for _, valida := range AlicuotasIVA {
+		if alicuota == valida {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizarCategoria(categoria string) string {
+	return strings.ToLower(strings.TrimSpace(categoria))
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// reglasPrueba asigna IVA del 21% por defecto, 10.5% a los alimentos, 27% a los
+// servicios y exime a los libros. La sucursal 2 está en la jurisdicción caba,
+// que grava los alimentos al 21%, y la sucursal 3 exime todas sus ventas.
+func reglasPrueba(t *testing.T) *ReglasImpuestos {
+	t.Helper()
+	reglas := &ReglasImpuestos{
+		ReglaImpuestos: ReglaImpuestos{
+			Alicuota: AlicuotaIVAGeneral,
+			Categorias: map[string]string{
+				"Alimentos": AlicuotaIVAReducida,
+				"servicios": AlicuotaIVAIncrementada,
+				"libros":    AlicuotaIVAExento,
+			},
+			Percepciones: []ReglaPercepcion{
+				{Tipo: "percepcion", Concepto: "percepcion_iibb", Alicuota: 3, MinimoImponible: 1200},
+				{Tipo: "Retencion", Concepto: " retencion_ganancias ", Alicuota: 2},
+			},
+		},
+		Jurisdicciones: map[string]JurisdiccionImpuestos{
+			"caba": {
+				ReglaImpuestos: ReglaImpuestos{
+					Categorias: map[string]string{"alimentos": AlicuotaIVAGeneral},
+					Percepciones: []ReglaPercepcion{
+						{Tipo: "percepcion", Concepto: "percepcion_iibb", Alicuota: 5},
+					},
+				},
+				Sucursales: []uint{2},
+			},
+		},
+		Sucursales: map[uint]ReglaImpuestos{
+			3: {Alicuota: AlicuotaIVAExento},
+		},
+	}
+	if err := reglas.Validar(); err != nil {
+		t.Fatalf("Validar: %v", err)
+	}
+	return reglas
+}
+
+// ventaPrueba arma una venta de la sucursal con una línea por importe; cada
+// línea tiene una unidad a ese precio
+func ventaPrueba(sucursalID uint, descuento float64, importes ...float64) *entities.Venta {
+	venta := &entities.Venta{SucursalID: sucursalID, Descuento: descuento}
+	for i, importe := range importes {
+		detalle := entities.DetalleVenta{ProductoID: uint(i + 1), Cantidad: 1, PrecioUnitario: importe}
+		detalle.CalcularTotal()
+		venta.DetallesVenta = append(venta.DetallesVenta, detalle)
+	}
+	return venta
+}
+
+func casiIgual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestReglasImpuestosAplicar(t *testing.T) {
+	// Una línea de cada alícuota: 1000 general, 200 alimentos, 100 servicios y 50 libros
+	categorias := []string{"electronica", " ALIMENTOS ", "servicios", "libros"}
+	importes := []float64{1000, 200, 100, 50}
+
+	casos := []struct {
+		nombre     string
+		sucursalID uint
+		descuento  float64
+		ivaLineas  []float64
+		desglose   map[string][2]float64 // concepto: base imponible e importe
+		impuestos  float64
+		retencion  float64
+		total      float64
+	}{
+		{
+			nombre:     "alícuotas mezcladas",
+			sucursalID: 1,
+			ivaLineas:  []float64{210, 21, 27, 0},
+			desglose: map[string][2]float64{
+				"iva_21":              {1000, 210},
+				"iva_10.5":            {200, 21},
+				"iva_27":              {100, 27},
+				"iva_exento":          {50, 0},
+				"percepcion_iibb":     {1300, 39},
+				"retencion_ganancias": {1300, 26},
+			},
+			impuestos: 297,
+			retencion: 26,
+			total:     1647,
+		},
+		{
+			// El descuento del 10% se prorratea: 100, 20, 10 y 5. El neto gravado
+			// queda en 1170, por debajo del mínimo de la percepción.
+			nombre:     "descuento general prorrateado",
+			sucursalID: 1,
+			descuento:  135,
+			ivaLineas:  []float64{189, 18.9, 24.3, 0},
+			desglose: map[string][2]float64{
+				"iva_21":              {900, 189},
+				"iva_10.5":            {180, 18.9},
+				"iva_27":              {90, 24.3},
+				"iva_exento":          {45, 0},
+				"retencion_ganancias": {1170, 23.4},
+			},
+			impuestos: 232.2,
+			retencion: 23.4,
+			total:     1447.2,
+		},
+		{
+			// caba grava los alimentos al 21% y reemplaza la percepción de IIBB,
+			// que no tiene mínimo
+			nombre:     "jurisdicción",
+			sucursalID: 2,
+			ivaLineas:  []float64{210, 42, 27, 0},
+			desglose: map[string][2]float64{
+				"iva_21":              {1200, 252},
+				"iva_27":              {100, 27},
+				"iva_exento":          {50, 0},
+				"percepcion_iibb":     {1300, 65},
+				"retencion_ganancias": {1300, 26},
+			},
+			impuestos: 344,
+			retencion: 26,
+			total:     1694,
+		},
+		{
+			// La alícuota de la sucursal prevalece sobre las categorías generales;
+			// sin neto gravado no hay percepciones
+			nombre:     "sucursal exenta",
+			sucursalID: 3,
+			ivaLineas:  []float64{0, 0, 0, 0},
+			desglose: map[string][2]float64{
+				"iva_exento": {1350, 0},
+			},
+			impuestos: 0,
+			total:     1350,
+		},
+	}
+
+	reglas := reglasPrueba(t)
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			venta := ventaPrueba(caso.sucursalID, caso.descuento, importes...)
+			reglas.Aplicar(venta, categorias)
+
+			for i, iva := range caso.ivaLineas {
+				if !casiIgual(venta.DetallesVenta[i].IVA, iva) {
+					t.Errorf("IVA de la línea %d = %v, se esperaba %v", i+1, venta.DetallesVenta[i].IVA, iva)
+				}
+			}
+
+			if len(venta.ImpuestosVenta) != len(caso.desglose) {
+				t.Errorf("el desglose tiene %d líneas, se esperaban %d: %+v", len(venta.ImpuestosVenta), len(caso.desglose), venta.ImpuestosVenta)
+			}
+			for _, impuesto := range venta.ImpuestosVenta {
+				esperado, existe := caso.desglose[impuesto.Concepto]
+				if !existe {
+					t.Errorf("concepto inesperado en el desglose: %s", impuesto.Concepto)
+					continue
+				}
+				if !casiIgual(impuesto.BaseImponible, esperado[0]) || !casiIgual(impuesto.Importe, esperado[1]) {
+					t.Errorf("%s: base %v e importe %v, se esperaban %v y %v",
+						impuesto.Concepto, impuesto.BaseImponible, impuesto.Importe, esperado[0], esperado[1])
+				}
+			}
+
+			if !casiIgual(venta.Subtotal, 1350) {
+				t.Errorf("Subtotal = %v, se esperaba 1350", venta.Subtotal)
+			}
+			if !casiIgual(venta.Impuestos, caso.impuestos) {
+				t.Errorf("Impuestos = %v, se esperaba %v", venta.Impuestos, caso.impuestos)
+			}
+			if !casiIgual(venta.Retenciones, caso.retencion) {
+				t.Errorf("Retenciones = %v, se esperaba %v", venta.Retenciones, caso.retencion)
+			}
+			if !casiIgual(venta.Total, caso.total) {
+				t.Errorf("Total = %v, se esperaba %v", venta.Total, caso.total)
+			}
+		})
+	}
+}
+
+func TestReglasImpuestosAplicarEsIdempotente(t *testing.T) {
+	reglas := reglasPrueba(t)
+	venta := ventaPrueba(1, 0, 1000, 300)
+
+	reglas.Aplicar(venta, []string{"electronica"})
+	primero := venta.Total
+	reglas.Aplicar(venta, []string{"electronica"})
+
+	if !casiIgual(venta.Total, primero) || len(venta.ImpuestosVenta) != 3 {
+		t.Errorf("aplicar de nuevo cambió la venta: total %v (antes %v), desglose %+v", venta.Total, primero, venta.ImpuestosVenta)
+	}
+	// Sin categoría informada la segunda línea lleva la alícuota general
+	if venta.DetallesVenta[1].AlicuotaIVA != 21 {
+		t.Errorf("la línea sin categoría tiene alícuota %v, se esperaba 21", venta.DetallesVenta[1].AlicuotaIVA)
+	}
+}
+
+func TestReglasImpuestosValidar(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		reglas  ReglasImpuestos
+		mensaje string
+	}{
+		{"sin alícuota general", ReglasImpuestos{}, "la alícuota general es requerida"},
+		{"alícuota desconocida", ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "19"}}, "alícuota desconocida \"19\""},
+		{
+			"alícuota de categoría desconocida",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21", Categorias: map[string]string{"vinos": "30"}}},
+			"para la categoría vinos",
+		},
+		{
+			"tipo de percepción desconocido",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21", Percepciones: []ReglaPercepcion{{Tipo: "tasa", Concepto: "x", Alicuota: 1}}}},
+			"tipo de percepción desconocido",
+		},
+		{
+			"percepción sin concepto",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21", Percepciones: []ReglaPercepcion{{Tipo: "percepcion", Alicuota: 1}}}},
+			"requiere concepto",
+		},
+		{
+			"alícuota de percepción fuera de rango",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21", Percepciones: []ReglaPercepcion{{Tipo: "percepcion", Concepto: "x", Alicuota: 120}}}},
+			"entre 0 y 100",
+		},
+		{
+			"mínimo negativo",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21", Percepciones: []ReglaPercepcion{{Tipo: "percepcion", Concepto: "x", Alicuota: 1, MinimoImponible: -1}}}},
+			"no puede ser negativo",
+		},
+		{
+			"sucursal en dos jurisdicciones",
+			ReglasImpuestos{
+				ReglaImpuestos: ReglaImpuestos{Alicuota: "21"},
+				Jurisdicciones: map[string]JurisdiccionImpuestos{
+					"a": {Sucursales: []uint{1}},
+					"b": {Sucursales: []uint{1}},
+				},
+			},
+			"está en las jurisdicciones",
+		},
+		{
+			"alícuota de sucursal desconocida",
+			ReglasImpuestos{ReglaImpuestos: ReglaImpuestos{Alicuota: "21"}, Sucursales: map[uint]ReglaImpuestos{4: {Alicuota: "5"}}},
+			"sucursal 4: alícuota desconocida",
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			err := caso.reglas.Validar()
+			if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+				t.Errorf("Validar = %v, se esperaba un error con %q", err, caso.mensaje)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+)
+
+// TamanoLoteOutbox es la cantidad de eventos pendientes que el relay reclama por consulta
+const TamanoLoteOutbox = 100
+
+// PlazoReclamoOutbox es el tiempo que un relay tiene reservados los eventos que
+// reclamó; si el proceso termina sin entregarlos, otro los reclama al vencer
+const PlazoReclamoOutbox = 5 * time.Minute
+
+// MaxIntentosOutboxPorDefecto es la cantidad de intentos de entrega tras la
+// cual un evento se descarta, si no se configura otra
+const MaxIntentosOutboxPorDefecto = 10
+
+// RelayOutbox publica en el EventBus los eventos pendientes del outbox. Un
+// evento se marca entregado solo después de que sus manejadores lo procesan sin
+// error, por lo que puede entregarse más de una vez: los suscriptores lo
+// reconocen por su ID, "outbox_<id>". Cada relay reclama los eventos antes de
+// publicarlos, por lo que varios procesos pueden entregar el mismo outbox.
+type RelayOutbox struct {
+	outbox      repositories.OutboxRepository
+	eventBus    *events.EventBus
+	intervalo   time.Duration
+	aviso       chan struct{}
+	instancia   string
+	maxIntentos int
+}
+
+// NewRelayOutbox crea un relay que revisa el outbox cada intervalo y cuando se le avisa
+func NewRelayOutbox(outbox repositories.OutboxRepository, eventBus *events.EventBus, intervalo time.Duration) *RelayOutbox {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "relay"
+	}
+	return &RelayOutbox{
+		outbox:      outbox,
+		eventBus:    eventBus,
+		intervalo:   intervalo,
+		aviso:       make(chan struct{}, 1),
+		instancia:   fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano()),
+		maxIntentos: MaxIntentosOutboxPorDefecto,
+	}
+}
+
+// SetMaxIntentos define la cantidad de intentos de entrega tras la cual un
+// evento se descarta y deja de reintentarse
+func (r *RelayOutbox) SetMaxIntentos(maxIntentos int) {
+	if maxIntentos < 1 {
+		maxIntentos = 1
+	}
+	r.maxIntentos = maxIntentos
+}
+
+// Avisar indica al relay que hay eventos nuevos, sin esperar a que los entregue
+func (r *RelayOutbox) Avisar() {
+	select {
+	case r.aviso <- struct{}{}:
+	default:
+	}
+}
+
+// Iniciar entrega los eventos pendientes periódicamente y ante cada aviso hasta
+// que se cancele el contexto
+func (r *RelayOutbox) Iniciar(ctx context.Context) error {
+	log.Printf("Entregando eventos del outbox cada %v", r.intervalo)
+
+	ticker := time.NewTicker(r.intervalo)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Entregar(ctx); err != nil {
+			log.Printf("Error entregando eventos del outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-r.aviso:
+		}
+	}
+}
+
+// Entregar reclama y publica los eventos pendientes en el orden en que se
+// guardaron y retorna cuántos se entregaron. Un evento cuya publicación falla
+// queda pendiente para la próxima revisión, sin detener la entrega de los
+// demás, hasta agotar sus intentos: entonces se descarta y deja de reintentarse.
+func (r *RelayOutbox) Entregar(ctx context.Context) (int, error) {
+	entregados := 0
+	for {
+		pendientes, err := r.outbox.Reclamar(ctx, r.instancia, TamanoLoteOutbox, time.Now().Add(PlazoReclamoOutbox))
+		if err != nil {
+			return entregados, err
+		}
+
+		fallidos := 0
+		for i := range pendientes {
+			if err := r.publicar(&pendientes[i]); err != nil {
+				fallidos++
+				if err := r.registrarFallo(ctx, &pendientes[i], err); err != nil {
+					return entregados, err
+				}
+				continue
+			}
+			if err := r.outbox.MarcarEntregado(ctx, pendientes[i].ID, time.Now()); err != nil {
+				return entregados, err
+			}
+			entregados++
+		}
+
+		// Los fallidos volverían a reclamarse: se reintentan en la próxima revisión
+		if len(pendientes) < TamanoLoteOutbox || fallidos > 0 {
+			return entregados, nil
+		}
+	}
+}
+
+// registrarFallo cuenta el intento fallido del evento y lo descarta si agotó sus intentos
+func (r *RelayOutbox) registrarFallo(ctx context.Context, evento *entities.EventoOutbox, causa error) error {
+	if evento.Intentos+1 >= r.maxIntentos {
+		log.Printf("⚠️ Evento %d del outbox (%s) descartado tras %d intentos de entrega: %v", evento.ID, evento.Tipo, evento.Intentos+1, causa)
+		return r.outbox.Descartar(ctx, evento.ID, causa.Error(), time.Now())
+	}
+	log.Printf("Error publicando el evento %d del outbox (%s): %v", evento.ID, evento.Tipo, causa)
+	return r.outbox.RegistrarFallo(ctx, evento.ID, causa.Error())
+}
+
+// publicar publica el evento en el EventBus
+func (r *RelayOutbox) publicar(pendiente *entities.EventoOutbox) error {
+	datos, err := pendiente.DatosEvento()
+	if err != nil {
+		return err
+	}
+	return r.eventBus.Publish(events.Event{
+		ID:        fmt.Sprintf("outbox_%d", pendiente.ID),
+		Type:      pendiente.Tipo,
+		Data:      datos,
+		Timestamp: pendiente.CreadoEn,
+		Source:    pendiente.Origen,
+		Priority:  2,
+	})
+}
+
+// emisorEventos emite los eventos de dominio de un servicio: los guarda en el
+// outbox dentro de la transacción de los cambios que los originan o, sin
+// outbox, los publica directamente en el EventBus
+type emisorEventos struct {
+	eventBus      *events.EventBus
+	origen        string
+	outbox        repositories.OutboxRepository
+	transacciones repositories.Transacciones
+	relay         *RelayOutbox
+}
+
+// SetOutbox hace que los eventos de dominio se guarden en el outbox dentro de
+// la transacción de los cambios que los originan, y que el relay se entere al
+// confirmarse. Sin outbox se publican directamente.
+func (e *emisorEventos) SetOutbox(outbox repositories.OutboxRepository, transacciones repositories.Transacciones, relay *RelayOutbox) {
+	e.outbox = outbox
+	e.transacciones = transacciones
+	e.relay = relay
+}
+
+// SetTransacciones define las transacciones en que se ejecutan los cambios del
+// servicio, aunque sus eventos se publiquen directamente sin outbox
+func (e *emisorEventos) SetTransacciones(transacciones repositories.Transacciones) {
+	e.transacciones = transacciones
+}
+
+// enTransaccion ejecuta fn en una transacción que incluye los eventos que emita
+func (e *emisorEventos) enTransaccion(ctx context.Context, fn func(ctx context.Context) error) error {
+	if e.transacciones == nil {
+		return fn(ctx)
+	}
+	if err := e.transacciones.Ejecutar(ctx, fn); err != nil {
+		return err
+	}
+	if e.relay != nil && !esSimulacion(ctx) {
+		e.relay.Avisar()
+	}
+	return nil
+}
+
+// emitir guarda un evento de dominio en el outbox o, sin outbox, lo publica.
+// En una simulación sin outbox el evento se descarta.
+func (e *emisorEventos) emitir(ctx context.Context, tipo string, datos map[string]interface{}) error {
+	if e.outbox == nil {
+		if esSimulacion(ctx) {
+			return nil
+		}
+		e.eventBus.Publish(events.CreateEvent(tipo, datos, e.origen))
+		return nil
+	}
+
+	evento, err := entities.NewEventoOutbox(tipo, e.origen, datos)
+	if err != nil {
+		return err
+	}
+	return e.outbox.Agregar(ctx, evento)
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/retry"
+)
+
+// TamanoBloquePorDefecto es la cantidad de registros que se escriben juntos cuando
+// no se configura otra
+const TamanoBloquePorDefecto = 500
+
+// MetricaBloque mide la escritura de un bloque de registros de un lote
+type MetricaBloque struct {
+	Numero       int `json:"numero"`
+	Registros    int `json:"registros"`
+	Persistidos  int `json:"persistidos"`
+	EnBloque     int `json:"en_bloque"`    // persistidos con la escritura conjunta
+	Individuales int `json:"individuales"` // persistidos de a uno, por no poder escribirse en bloque
+
+	DuracionMs          int64   `json:"duracion_ms"`
+	RegistrosPorSegundo float64 `json:"registros_por_segundo"`
+}
+
+// escrituraBloque es la escritura conjunta preparada para un bloque: la
+// operación de cada registro incluido y la función que los guarda a todos
+type escrituraBloque struct {
+	operaciones []operacionPersistencia
+	escribir    func(ctx context.Context) error
+}
+
+// SetTamanoBloque define la cantidad de registros por bloque de escritura. Con 1
+// cada registro se escribe por separado.
+func (pds *ProcesadorDatosService) SetTamanoBloque(tamano int) {
+	if tamano < 1 {
+		tamano = 1
+	}
+	pds.tamanoBloque = tamano
+}
+
+// SetReglasImpuestos define las reglas con que se calculan los impuestos de las
+// ventas que se persisten; sin reglas se aplican ReglasImpuestosPorDefecto
+func (pds *ProcesadorDatosService) SetReglasImpuestos(reglas *ReglasImpuestos) {
+	if reglas == nil {
+		reglas = ReglasImpuestosPorDefecto()
+	}
+	pds.impuestos = reglas
+}
+
+// persistirBloque escribe el bloque con una única escritura conjunta y recurre a
+// la escritura registro por registro para los registros que no pudieron
+// prepararse o si la escritura conjunta falla, aislando así al que la provoca.
+// La escritura conjunta requiere transacciones, para que un bloque fallido no
+// deje cambios parciales; sin ellas los registros se escriben de a uno.
+func (pds *ProcesadorDatosService) persistirBloque(ctx context.Context, destino destinoPersistencia, bloque []map[string]interface{}, conteo *conteoPersistencia) MetricaBloque {
+	inicio := time.Now()
+	metrica := MetricaBloque{Registros: len(bloque)}
+
+	individuales := bloque
+	if len(bloque) > 1 && pds.transacciones != nil {
+		var escritura *escrituraBloque
+		var pendientes []map[string]interface{}
+		err := pds.ejecutarConReintentos(ctx, pds.politicaPersistencia, "persistencia", destino.sucursalID, func(ctx context.Context) error {
+			return pds.enTransaccion(ctx, func(ctx context.Context) error {
+				// Cada intento prepara entidades nuevas: las de un intento revertido
+				// pueden haber recibido ID y ya no sirven para insertarse
+				var err error
+				if escritura, pendientes, err = pds.prepararBloque(ctx, destino, bloque); err != nil {
+					return err
+				}
+				if err := escritura.escribir(ctx); err != nil {
+					return err
+				}
+				return pds.emitirPersistidos(ctx, destino, escritura.operaciones...)
+			})
+		})
+		if err != nil {
+			log.Printf("Error escribiendo bloque de %d registros, se persisten de a uno: %v", len(bloque), err)
+		} else {
+			for _, operacion := range escritura.operaciones {
+				conteo.registrar(operacion)
+			}
+			metrica.EnBloque = len(escritura.operaciones)
+			individuales = pendientes
+		}
+	}
+
+	for _, dato := range individuales {
+		var operacion operacionPersistencia
+		err := pds.ejecutarConReintentos(ctx, pds.politicaPersistencia, "persistencia", destino.sucursalID, func(ctx context.Context) error {
+			return pds.enTransaccion(ctx, func(ctx context.Context) error {
+				var err error
+				if operacion, err = pds.persistirRegistro(ctx, destino, dato); err != nil {
+					return err
+				}
+				return pds.emitirPersistidos(ctx, destino, operacion)
+			})
+		})
+		if err != nil {
+			log.Printf("Error persistiendo registro: %v", err)
+			continue
+		}
+		conteo.registrar(operacion)
+		metrica.Individuales++
+	}
+
+	metrica.Persistidos = metrica.EnBloque + metrica.Individuales
+	duracion := time.Since(inicio)
+	metrica.DuracionMs = duracion.Milliseconds()
+	if duracion > 0 {
+		metrica.RegistrosPorSegundo = float64(metrica.Persistidos) / duracion.Seconds()
+	}
+	return metrica
+}
+
+// emitirPersistidos emite EventDatosPersistidos con las operaciones de una
+// escritura, en su misma transacción. Una escritura sin cambios no lo emite.
+func (pds *ProcesadorDatosService) emitirPersistidos(ctx context.Context, destino destinoPersistencia, operaciones ...operacionPersistencia) error {
+	var conteo conteoPersistencia
+	for _, operacion := range operaciones {
+		conteo.registrar(operacion)
+	}
+	if conteo.insertados+conteo.actualizados == 0 {
+		return nil
+	}
+
+	return pds.emitir(ctx, events.EventDatosPersistidos, map[string]interface{}{
+		"lote_id":               repositories.OrigenCambioDe(ctx).LoteID,
+		"sucursal_id":           destino.sucursalID,
+		"tipo":                  destino.tipo,
+		"registros_persistidos": conteo.total(),
+		"insertados":            conteo.insertados,
+		"actualizados":          conteo.actualizados,
+		"sin_cambios":           conteo.sinCambios,
+	})
+}
+
+// publicarMetricaBloque notifica la métrica de un bloque escrito
+func (pds *ProcesadorDatosService) publicarMetricaBloque(ctx context.Context, destino destinoPersistencia, metrica MetricaBloque) {
+	if esSimulacion(ctx) {
+		return
+	}
+	pds.eventBus.Publish(events.CreateEvent(
+		events.EventBloquePersistido,
+		map[string]interface{}{
+			"sucursal_id":           destino.sucursalID,
+			"tipo":                  destino.tipo,
+			"bloque":                metrica.Numero,
+			"registros":             metrica.Registros,
+			"persistidos":           metrica.Persistidos,
+			"en_bloque":             metrica.EnBloque,
+			"individuales":          metrica.Individuales,
+			"duracion_ms":           metrica.DuracionMs,
+			"registros_por_segundo": metrica.RegistrosPorSegundo,
+		},
+		"procesador_datos",
+	))
+}
+
+// prepararBloque convierte los registros del bloque en entidades listas para una
+// escritura conjunta. Los registros que no pueden prepararse se retornan como
+// pendientes, para persistirlos de a uno y registrar su error.
+func (pds *ProcesadorDatosService) prepararBloque(ctx context.Context, destino destinoPersistencia, bloque []map[string]interface{}) (*escrituraBloque, []map[string]interface{}, error) {
+	switch destino.tipo {
+	case "producto", "stock":
+		if pds.productos != nil {
+			return pds.prepararBloqueProductos(ctx, destino, bloque)
+		}
+	case "venta":
+		if pds.ventas != nil {
+			return pds.prepararBloqueVentas(ctx, destino, bloque)
+		}
+	}
+	// Sin persistencia en bloque: todos los registros quedan pendientes
+	return &escrituraBloque{escribir: func(context.Context) error { return nil }}, bloque, nil
+}
+
+// prepararBloqueProductos resuelve con una sola consulta los productos
+// existentes del bloque y les aplica los registros de producto o de stock. Los
+// registros repetidos de un mismo SKU se aplican en orden sobre el mismo producto,
+// y el stock informado se convierte en movimientos sobre el saldo de la sucursal.
+func (pds *ProcesadorDatosService) prepararBloqueProductos(ctx context.Context, destino destinoPersistencia, bloque []map[string]interface{}) (*escrituraBloque, []map[string]interface{}, error) {
+	skus := make([]string, 0, len(bloque))
+	for _, dato := range bloque {
+		if sku := texto(dato, "sku"); sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+
+	existentes, err := pds.productos.ListarPorSKUs(ctx, skus)
+	if err != nil {
+		return nil, nil, err
+	}
+	porSKU := make(map[string]*entities.Producto, len(existentes))
+	ids := make([]uint, 0, len(existentes))
+	for i := range existentes {
+		porSKU[existentes[i].SKU] = &existentes[i]
+		ids = append(ids, existentes[i].ID)
+	}
+
+	// Saldo de cada producto en la sucursal, actualizado con los movimientos del bloque
+	saldos := make(map[*entities.Producto]int, len(existentes))
+	if destino.tieneAutoridad("stock_actual") && len(ids) > 0 {
+		if pds.movimientos == nil {
+			return nil, nil, retry.Permanente(fmt.Errorf("no hay repositorio de movimientos de stock configurado"))
+		}
+		porID, err := pds.movimientos.SaldosPorSucursal(ctx, destino.sucursalID, ids)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, producto := range porSKU {
+			saldos[producto] = porID[producto.ID]
+		}
+	}
+
+	escritura := &escrituraBloque{}
+	var pendientes []map[string]interface{}
+	var aGuardar []*entities.Producto
+	enEscritura := make(map[*entities.Producto]bool)
+	var movimientos []*entities.MovimientoStock
+	deProducto := make(map[*entities.MovimientoStock]*entities.Producto)
+
+	for _, dato := range bloque {
+		var producto *entities.Producto
+		var operacion operacionPersistencia
+		var registro registroStock
+		informaStock := false
+
+		if destino.tipo == "stock" {
+			if registro, err = mapearStock(dato); err != nil || porSKU[registro.sku] == nil {
+				pendientes = append(pendientes, dato)
+				continue
+			}
+			producto, operacion = porSKU[registro.sku], operacionSinCambios
+			informaStock = destino.tieneAutoridad("stock_actual")
+		} else {
+			nuevo := mapearProducto(dato)
+			producto = porSKU[nuevo.SKU]
+			switch {
+			case nuevo.SKU == "" || (producto == nil && !nuevo.EsValido()):
+				pendientes = append(pendientes, dato)
+				continue
+			case producto == nil:
+				producto, operacion = nuevo, operacionInsercion
+				porSKU[nuevo.SKU] = nuevo
+			default:
+				if operacion, err = fusionarProducto(destino, producto, nuevo, dato); err != nil {
+					pendientes = append(pendientes, dato)
+					continue
+				}
+			}
+			registro.sku = producto.SKU
+			registro.cantidad, informaStock = stockInformado(destino, dato)
+		}
+
+		if informaStock {
+			movimiento, err := registro.movimiento(producto.ID, destino.sucursalID, saldos[producto])
+			if err != nil {
+				pendientes = append(pendientes, dato)
+				continue
+			}
+			if movimiento != nil {
+				saldos[producto] += movimiento.Cantidad
+				movimientos = append(movimientos, movimiento)
+				deProducto[movimiento] = producto
+				if operacion == operacionSinCambios {
+					operacion = operacionActualizacion
+				}
+			}
+		}
+
+		escritura.operaciones = append(escritura.operaciones, operacion)
+		if destino.tipo == "producto" && operacion != operacionSinCambios && !enEscritura[producto] {
+			enEscritura[producto] = true
+			aGuardar = append(aGuardar, producto)
+		}
+	}
+
+	escritura.escribir = func(ctx context.Context) error {
+		if len(aGuardar) > 0 {
+			if err := pds.productos.GuardarLote(ctx, aGuardar); err != nil {
+				return errorRepositorio(err)
+			}
+		}
+		if len(movimientos) == 0 {
+			return nil
+		}
+		// Los productos nuevos reciben su ID al guardarse
+		for _, movimiento := range movimientos {
+			movimiento.ProductoID = deProducto[movimiento].ID
+		}
+		return pds.registrarMovimientos(ctx, movimientos...)
+	}
+	return escritura, pendientes, nil
+}
+
+// prepararBloqueVentas arma las ventas del bloque para guardarlas, con los
+// egresos de stock de las completadas, en una sola transacción
+func (pds *ProcesadorDatosService) prepararBloqueVentas(ctx context.Context, destino destinoPersistencia, bloque []map[string]interface{}) (*escrituraBloque, []map[string]interface{}, error) {
+	escritura := &escrituraBloque{}
+	var pendientes []map[string]interface{}
+	var ventas []*entities.Venta
+
+	for _, dato := range bloque {
+		venta, err := pds.prepararVenta(ctx, destino.sucursalID, dato)
+		if err != nil {
+			pendientes = append(pendientes, dato)
+			continue
+		}
+		ventas = append(ventas, venta)
+		escritura.operaciones = append(escritura.operaciones, operacionInsercion)
+	}
+
+	escritura.escribir = func(ctx context.Context) error {
+		if err := pds.ventas.GuardarLote(ctx, ventas); err != nil {
+			return errorRepositorio(err)
+		}
+		if err := pds.egresarStock(ctx, ventas...); err != nil {
+			return err
+		}
+		for _, venta := range ventas {
+			if err := pds.emitirVentaRegistrada(ctx, venta); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return escritura, pendientes, nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/database"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/persistence"
+	"sistema-gestion-informacion/internal/infrastructure/retry"
+)
+
+// baseBloques abre una base SQLite migrada con la sucursal 1 y el producto 1.
+// Las claves foráneas quedan sin aplicar para que la venta de un producto
+// inexistente se guarde con sus detalles y recién falle al egresar el stock:
+// solo la transacción puede deshacer lo que ya escribió.
+func baseBloques(t *testing.T) *gorm.DB {
+	t.Helper()
+	ruta := filepath.Join(t.TempDir(), "bloques.db")
+	db, err := gorm.Open(sqlite.Open(ruta), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := database.NewMigrador(db).Subir(context.Background()); err != nil {
+		t.Fatalf("Subir migraciones: %v", err)
+	}
+	if err := db.Exec("INSERT INTO sucursales (nombre, estado, api_secret, configuracion) VALUES ('Centro', 'activa', 'secreto', '{}')").Error; err != nil {
+		t.Fatalf("insertando sucursal: %v", err)
+	}
+	producto := &entities.Producto{SKU: "A1", Nombre: "Producto A", Precio: 100, Estado: "activo"}
+	if err := persistence.NewProductoRepositoryGorm(db).Guardar(context.Background(), producto); err != nil {
+		t.Fatalf("Guardar producto: %v", err)
+	}
+	return db
+}
+
+// procesadorBloques crea un procesador de ventas sobre la base, sin repositorio
+// de productos para que las líneas no se validen contra el catálogo al
+// prepararse y un producto inexistente recién falle al escribirse
+func procesadorBloques(db *gorm.DB, ventas repositories.VentaRepository) *ProcesadorDatosService {
+	transacciones := persistence.NewTransaccionesGorm(db)
+	pds := NewProcesadorDatosService(events.NewEventBus(), nil, nil, nil, ventas, nil, persistence.NewMovimientoStockRepositoryGorm(db))
+	pds.SetOutbox(persistence.NewOutboxRepositoryGorm(db), transacciones, nil)
+	politica := retry.NewPolitica(3, time.Millisecond)
+	politica.Transitorio = database.EsErrorTransitorio
+	pds.SetPoliticaPersistencia(politica)
+	return pds
+}
+
+// ventasBloque arma ventas de una línea del producto 1; la de la posición mala
+// vende el producto 99, que no existe
+func ventasBloque(cantidad, mala int) []map[string]interface{} {
+	datos := make([]map[string]interface{}, 0, cantidad)
+	for i := 0; i < cantidad; i++ {
+		productoID := 1
+		if i == mala {
+			productoID = 99
+		}
+		datos = append(datos, map[string]interface{}{
+			"fecha_venta":     "2024-01-15T10:00:00Z",
+			"producto_id":     productoID,
+			"cantidad":        i + 1,
+			"precio_unitario": 100,
+		})
+	}
+	return datos
+}
+
+// contarFilas cuenta las filas que retorna la consulta de conteo
+func contarFilas(t *testing.T, db *gorm.DB, consulta string) int64 {
+	t.Helper()
+	var cantidad int64
+	if err := db.Raw(consulta).Scan(&cantidad).Error; err != nil {
+		t.Fatalf("%s: %v", consulta, err)
+	}
+	return cantidad
+}
+
+// verificarSinHuerfanos falla si quedaron detalles sin venta o ventas sin detalles
+func verificarSinHuerfanos(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if huerfanos := contarFilas(t, db, "SELECT COUNT(*) FROM detalles_venta WHERE venta_id NOT IN (SELECT id FROM ventas)"); huerfanos != 0 {
+		t.Errorf("quedaron %d detalles sin venta", huerfanos)
+	}
+	if vacias := contarFilas(t, db, "SELECT COUNT(*) FROM ventas WHERE id NOT IN (SELECT venta_id FROM detalles_venta)"); vacias != 0 {
+		t.Errorf("quedaron %d ventas sin detalles", vacias)
+	}
+}
+
+func TestPersistirBloqueConUnaVentaInvalida(t *testing.T) {
+	db := baseBloques(t)
+	pds := procesadorBloques(db, persistence.NewVentaRepositoryGorm(db))
+
+	resultado, err := pds.ProcesarLote(context.Background(), &DatosCrudos{
+		Tipo:       "venta",
+		SucursalID: 1,
+		Datos:      ventasBloque(5, 2),
+	})
+	if err != nil {
+		t.Fatalf("ProcesarLote: %v", err)
+	}
+
+	if resultado.RegistrosPersistidos != 4 || resultado.RegistrosFallidos != 1 {
+		t.Errorf("persistidos %d y fallidos %d, se esperaban 4 y 1", resultado.RegistrosPersistidos, resultado.RegistrosFallidos)
+	}
+	// La escritura conjunta se revierte entera y el bloque se persiste de a uno
+	if len(resultado.Bloques) != 1 || resultado.Bloques[0].EnBloque != 0 || resultado.Bloques[0].Individuales != 4 {
+		t.Errorf("bloques = %+v, se esperaba uno con 4 registros persistidos de a uno", resultado.Bloques)
+	}
+	if ventas := contarFilas(t, db, "SELECT COUNT(*) FROM ventas"); ventas != 4 {
+		t.Errorf("se guardaron %d ventas, se esperaban 4", ventas)
+	}
+	if detalles := contarFilas(t, db, "SELECT COUNT(*) FROM detalles_venta"); detalles != 4 {
+		t.Errorf("se guardaron %d detalles, se esperaban 4", detalles)
+	}
+	verificarSinHuerfanos(t, db)
+}
+
+// ventasConFallaTransitoria guarda el primer lote de ventas y luego informa una
+// conexión perdida, como si la transacción se hubiera cortado después de
+// asignarles ID
+type ventasConFallaTransitoria struct {
+	*persistence.VentaRepositoryGorm
+	fallas int
+}
+
+func (r *ventasConFallaTransitoria) GuardarLote(ctx context.Context, ventas []*entities.Venta) error {
+	if err := r.VentaRepositoryGorm.GuardarLote(ctx, ventas); err != nil {
+		return err
+	}
+	if r.fallas > 0 {
+		r.fallas--
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func TestPersistirBloqueReintentoPreparaVentasNuevas(t *testing.T) {
+	db := baseBloques(t)
+	pds := procesadorBloques(db, &ventasConFallaTransitoria{VentaRepositoryGorm: persistence.NewVentaRepositoryGorm(db), fallas: 1})
+
+	resultado, err := pds.ProcesarLote(context.Background(), &DatosCrudos{
+		Tipo:       "venta",
+		SucursalID: 1,
+		Datos:      ventasBloque(3, -1),
+	})
+	if err != nil {
+		t.Fatalf("ProcesarLote: %v", err)
+	}
+
+	// Reusar las ventas del intento revertido fallaría por tener ID y el bloque
+	// terminaría escribiéndose de a uno
+	if len(resultado.Bloques) != 1 || resultado.Bloques[0].EnBloque != 3 || resultado.Bloques[0].Individuales != 0 {
+		t.Errorf("bloques = %+v, se esperaba uno con los 3 registros escritos en bloque", resultado.Bloques)
+	}
+	if ventas := contarFilas(t, db, "SELECT COUNT(*) FROM ventas"); ventas != 3 {
+		t.Errorf("se guardaron %d ventas, se esperaban 3", ventas)
+	}
+	if egresos := contarFilas(t, db, "SELECT COUNT(*) FROM movimientos_stock"); egresos != 3 {
+		t.Errorf("se registraron %d egresos de stock, se esperaban 3", egresos)
+	}
+	verificarSinHuerfanos(t, db)
+}
+
+func TestSimularLoteConUnaVentaInvalida(t *testing.T) {
+	db := baseBloques(t)
+	ventas := persistence.NewVentaRepositoryGorm(db)
+	pds := procesadorBloques(db, ventas)
+	datos := &DatosCrudos{Tipo: "venta", SucursalID: 1, Datos: ventasBloque(5, 2)}
+
+	t.Run("el bloque fallido se revierte hasta su savepoint", func(t *testing.T) {
+		err := pds.transacciones.Ejecutar(conSimulacion(context.Background()), func(ctx context.Context) error {
+			if _, err := pds.procesarLote(ctx, datos); err != nil {
+				return err
+			}
+			// Dentro de la simulación se ven solo las ventas persistidas de a uno
+			guardadas, err := ventas.ListarPorSucursal(ctx, 1)
+			if err != nil {
+				return err
+			}
+			if len(guardadas) != 4 {
+				t.Errorf("la simulación ve %d ventas, se esperaban 4", len(guardadas))
+			}
+			for _, venta := range guardadas {
+				if len(venta.DetallesVenta) != 1 {
+					t.Errorf("la venta %d tiene %d detalles, se esperaba 1", venta.ID, len(venta.DetallesVenta))
+				}
+			}
+			return errSimulacion
+		})
+		if err != errSimulacion {
+			t.Fatalf("Ejecutar = %v, se esperaba errSimulacion", err)
+		}
+	})
+
+	t.Run("el resultado se informa y nada queda guardado", func(t *testing.T) {
+		resultado, err := pds.simularLote(context.Background(), datos)
+		if err != nil {
+			t.Fatalf("simularLote: %v", err)
+		}
+		if resultado.RegistrosPersistidos != 4 || resultado.RegistrosFallidos != 1 {
+			t.Errorf("persistidos %d y fallidos %d, se esperaban 4 y 1", resultado.RegistrosPersistidos, resultado.RegistrosFallidos)
+		}
+	})
+
+	for _, tabla := range []string{"ventas", "detalles_venta", "movimientos_stock", "eventos_outbox"} {
+		if filas := contarFilas(t, db, "SELECT COUNT(*) FROM "+tabla); filas != 0 {
+			t.Errorf("la simulación dejó %d filas en %s", filas, tabla)
+		}
+	}
+}
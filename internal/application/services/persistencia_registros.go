@@ -0,0 +1,719 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/retry"
+)
+
+// formatosFecha son los formatos aceptados para las fechas de los registros
+var formatosFecha = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "02/01/2006"}
+
+// operacionPersistencia indica el efecto que tuvo persistir un registro
+type operacionPersistencia int
+
+const (
+	operacionInsercion operacionPersistencia = iota
+	operacionActualizacion
+	operacionSinCambios
+)
+
+// conteoPersistencia acumula las operaciones realizadas al persistir un lote
+type conteoPersistencia struct {
+	insertados   int
+	actualizados int
+	sinCambios   int
+	bloques      []MetricaBloque
+}
+
+func (c *conteoPersistencia) registrar(operacion operacionPersistencia) {
+	switch operacion {
+	case operacionInsercion:
+		c.insertados++
+	case operacionActualizacion:
+		c.actualizados++
+	case operacionSinCambios:
+		c.sinCambios++
+	}
+}
+
+// total retorna la cantidad de registros persistidos sin error
+func (c conteoPersistencia) total() int {
+	return c.insertados + c.actualizados + c.sinCambios
+}
+
+// destinoPersistencia agrupa lo que se resuelve una vez por lote para persistir sus registros
+type destinoPersistencia struct {
+	sucursalID uint
+	tipo       string
+	// Campos de Producto que la sucursal puede modificar; nil indica todos
+	autoridadProducto []string
+}
+
+// destinoPersistencia resuelve la autoridad de la sucursal sobre los campos de Producto
+func (pds *ProcesadorDatosService) destinoPersistencia(ctx context.Context, sucursalID uint, tipo string) (destinoPersistencia, error) {
+	destino := destinoPersistencia{sucursalID: sucursalID, tipo: tipo}
+	if (tipo != "producto" && tipo != "stock") || pds.sucursales == nil || sucursalID == 0 {
+		return destino, nil
+	}
+
+	sucursal, err := pds.sucursales.ObtenerPorID(ctx, sucursalID)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return destino, nil
+	}
+	if err != nil {
+		return destino, err
+	}
+
+	config, err := sucursal.ObtenerConfiguracion()
+	if err != nil {
+		return destino, err
+	}
+	if len(config.AutoridadProducto) > 0 {
+		destino.autoridadProducto = config.AutoridadProducto
+	}
+	return destino, nil
+}
+
+// tieneAutoridad verifica si la sucursal del lote puede modificar el campo de Producto
+func (d destinoPersistencia) tieneAutoridad(campo string) bool {
+	if d.autoridadProducto == nil {
+		return true
+	}
+	for _, autorizado := range d.autoridadProducto {
+		if autorizado == campo {
+			return true
+		}
+	}
+	return false
+}
+
+// TiposDatos enumera los tipos de registro que el procesador sabe persistir
+var TiposDatos = []string{"venta", "producto", "stock"}
+
+// EsTipoDatos verifica si los registros del tipo indicado pueden persistirse
+func EsTipoDatos(tipo string) bool {
+	for _, valido := range TiposDatos {
+		if tipo == valido {
+			return true
+		}
+	}
+	return false
+}
+
+// persistirRegistro guarda el registro en el repositorio de la entidad que
+// corresponde al tipo del lote. Los registros que no pueden convertirse en una
+// entidad válida fallan con un error permanente, que no se reintenta.
+func (pds *ProcesadorDatosService) persistirRegistro(ctx context.Context, destino destinoPersistencia, dato map[string]interface{}) (operacionPersistencia, error) {
+	switch destino.tipo {
+	case "producto":
+		return pds.persistirProducto(ctx, destino, dato)
+	case "stock":
+		return pds.persistirStock(ctx, destino, dato)
+	case "venta":
+		return operacionInsercion, pds.persistirVenta(ctx, destino.sucursalID, dato)
+	}
+	return operacionSinCambios, retry.Permanente(fmt.Errorf("no hay persistencia para registros de tipo %q", destino.tipo))
+}
+
+// persistirProducto crea el producto o actualiza el existente con el mismo SKU.
+// El stock informado se aplica con un ajuste sobre el stock de la sucursal.
+func (pds *ProcesadorDatosService) persistirProducto(ctx context.Context, destino destinoPersistencia, dato map[string]interface{}) (operacionPersistencia, error) {
+	if pds.productos == nil {
+		return operacionSinCambios, retry.Permanente(fmt.Errorf("no hay repositorio de productos configurado"))
+	}
+
+	producto := mapearProducto(dato)
+	if producto.SKU == "" {
+		return operacionSinCambios, retry.Permanente(fmt.Errorf("%w: producto sin SKU", ErrDatosInvalidos))
+	}
+	stock, informaStock := stockInformado(destino, dato)
+
+	existente, err := pds.productos.ObtenerPorSKU(ctx, producto.SKU)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		if !producto.EsValido() {
+			return operacionSinCambios, retry.Permanente(fmt.Errorf("%w: producto sin nombre o con precio negativo", ErrDatosInvalidos))
+		}
+		err = pds.productos.Guardar(ctx, producto)
+		if !errors.Is(err, repositories.ErrDuplicado) {
+			if err != nil {
+				return operacionInsercion, errorRepositorio(err)
+			}
+			if informaStock {
+				_, err = pds.ajustarStock(ctx, destino, producto, registroStock{sku: producto.SKU, cantidad: stock}, 0)
+			}
+			return operacionInsercion, err
+		}
+		// Otro proceso insertó el mismo SKU: se aplica el registro como actualización
+		existente, err = pds.productos.ObtenerPorSKU(ctx, producto.SKU)
+	}
+	if err != nil {
+		return operacionSinCambios, err
+	}
+
+	operacion, err := fusionarProducto(destino, existente, producto, dato)
+	if err != nil {
+		return operacion, err
+	}
+	if operacion == operacionActualizacion {
+		if err := pds.productos.Guardar(ctx, existente); err != nil {
+			return operacion, errorRepositorio(err)
+		}
+	}
+
+	if informaStock {
+		saldo, err := pds.saldoSucursal(ctx, destino.sucursalID, existente.ID)
+		if err != nil {
+			return operacion, err
+		}
+		movido, err := pds.ajustarStock(ctx, destino, existente, registroStock{sku: existente.SKU, cantidad: stock}, saldo)
+		if err != nil {
+			return operacion, err
+		}
+		if movido {
+			operacion = operacionActualizacion
+		}
+	}
+	return operacion, nil
+}
+
+// fusionarProducto aplica sobre el producto existente los campos presentes en el
+// registro sobre los que la sucursal tiene autoridad. El existente solo se
+// modifica si el resultado es válido.
+func fusionarProducto(destino destinoPersistencia, existente, producto *entities.Producto, dato map[string]interface{}) (operacionPersistencia, error) {
+	var campos []string
+	for _, campo := range camposPresentes(dato) {
+		if destino.tieneAutoridad(campo) {
+			campos = append(campos, campo)
+		}
+	}
+
+	fusionado := *existente
+	if len(fusionado.Fusionar(producto, campos)) == 0 {
+		return operacionSinCambios, nil
+	}
+	if !fusionado.EsValido() {
+		return operacionSinCambios, retry.Permanente(fmt.Errorf("%w: producto sin nombre o con precio negativo", ErrDatosInvalidos))
+	}
+
+	*existente = fusionado
+	return operacionActualizacion, nil
+}
+
+// persistirStock registra el movimiento de stock del producto identificado por SKU
+func (pds *ProcesadorDatosService) persistirStock(ctx context.Context, destino destinoPersistencia, dato map[string]interface{}) (operacionPersistencia, error) {
+	if pds.productos == nil {
+		return operacionSinCambios, retry.Permanente(fmt.Errorf("no hay repositorio de productos configurado"))
+	}
+
+	registro, err := mapearStock(dato)
+	if err != nil {
+		return operacionSinCambios, err
+	}
+
+	producto, err := pds.productos.ObtenerPorSKU(ctx, registro.sku)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return operacionSinCambios, retry.Permanente(fmt.Errorf("producto con SKU %s no encontrado", registro.sku))
+	}
+	if err != nil {
+		return operacionSinCambios, err
+	}
+
+	if !destino.tieneAutoridad("stock_actual") {
+		log.Printf("La sucursal %d no tiene autoridad sobre el stock; se ignora el stock del SKU %s", destino.sucursalID, producto.SKU)
+		return operacionSinCambios, nil
+	}
+
+	saldo := 0
+	if registro.esConteo() {
+		if saldo, err = pds.saldoSucursal(ctx, destino.sucursalID, producto.ID); err != nil {
+			return operacionSinCambios, err
+		}
+	}
+
+	movido, err := pds.ajustarStock(ctx, destino, producto, registro, saldo)
+	if err != nil || !movido {
+		return operacionSinCambios, err
+	}
+	return operacionActualizacion, nil
+}
+
+// ajustarStock registra el movimiento que corresponde al registro de stock e
+// indica si hubo movimiento; un conteo igual al saldo de la sucursal no lo genera
+func (pds *ProcesadorDatosService) ajustarStock(ctx context.Context, destino destinoPersistencia, producto *entities.Producto, registro registroStock, saldo int) (bool, error) {
+	movimiento, err := registro.movimiento(producto.ID, destino.sucursalID, saldo)
+	if err != nil || movimiento == nil {
+		return false, err
+	}
+	return true, pds.registrarMovimientos(ctx, movimiento)
+}
+
+// registrarMovimientos guarda los movimientos de stock y emite un evento por cada uno
+func (pds *ProcesadorDatosService) registrarMovimientos(ctx context.Context, movimientos ...*entities.MovimientoStock) error {
+	if pds.movimientos == nil {
+		return retry.Permanente(fmt.Errorf("no hay repositorio de movimientos de stock configurado"))
+	}
+	if err := pds.movimientos.Registrar(ctx, movimientos...); err != nil {
+		return errorRepositorio(err)
+	}
+
+	for _, movimiento := range movimientos {
+		err := pds.emitir(ctx, events.EventStockActualizado, map[string]interface{}{
+			"movimiento_id":    movimiento.ID,
+			"producto_id":      movimiento.ProductoID,
+			"sucursal_id":      movimiento.SucursalID,
+			"tipo":             movimiento.Tipo,
+			"cantidad":         movimiento.Cantidad,
+			"stock_resultante": movimiento.StockResultante,
+			"lote_id":          movimiento.LoteID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saldoSucursal retorna el stock del producto en la sucursal según sus movimientos
+func (pds *ProcesadorDatosService) saldoSucursal(ctx context.Context, sucursalID, productoID uint) (int, error) {
+	if pds.movimientos == nil {
+		return 0, retry.Permanente(fmt.Errorf("no hay repositorio de movimientos de stock configurado"))
+	}
+	saldos, err := pds.movimientos.SaldosPorSucursal(ctx, sucursalID, []uint{productoID})
+	if err != nil {
+		return 0, err
+	}
+	return saldos[productoID], nil
+}
+
+// persistirVenta guarda la venta con sus detalles en una única transacción y,
+// si se registra completada, egresa su mercadería del stock de la sucursal
+func (pds *ProcesadorDatosService) persistirVenta(ctx context.Context, sucursalID uint, dato map[string]interface{}) error {
+	if pds.ventas == nil {
+		return retry.Permanente(fmt.Errorf("no hay repositorio de ventas configurado"))
+	}
+
+	venta, err := pds.prepararVenta(ctx, sucursalID, dato)
+	if err != nil {
+		return err
+	}
+	if err := pds.ventas.Guardar(ctx, venta); err != nil {
+		return errorRepositorio(err)
+	}
+	if err := pds.egresarStock(ctx, venta); err != nil {
+		return err
+	}
+	return pds.emitirVentaRegistrada(ctx, venta)
+}
+
+// egresarStock registra los movimientos de venta de las ventas completadas,
+// que ya deben estar guardadas para referenciarlas
+func (pds *ProcesadorDatosService) egresarStock(ctx context.Context, ventas ...*entities.Venta) error {
+	var movimientos []*entities.MovimientoStock
+	for _, venta := range ventas {
+		if venta.Estado != entities.EstadoVentaCompletada {
+			continue
+		}
+		egresos, err := egresosVenta(venta)
+		if err != nil {
+			return retry.Permanente(fmt.Errorf("%w: %v", ErrDatosInvalidos, err))
+		}
+		movimientos = append(movimientos, egresos...)
+	}
+	if len(movimientos) == 0 {
+		return nil
+	}
+	return pds.registrarMovimientos(ctx, movimientos...)
+}
+
+// egresosVenta retorna un movimiento de venta por cada detalle con unidades sin
+// devolver, que descuenta del stock de la sucursal la mercadería entregada
+func egresosVenta(venta *entities.Venta) ([]*entities.MovimientoStock, error) {
+	var movimientos []*entities.MovimientoStock
+	for _, detalle := range venta.DetallesVenta {
+		if detalle.CantidadPendiente() <= 0 {
+			continue
+		}
+		movimiento, err := entities.NewMovimientoStock(entities.MovimientoVenta, detalle.ProductoID, venta.SucursalID, detalle.CantidadPendiente())
+		if err != nil {
+			return nil, err
+		}
+		movimiento.Referencia = venta.ReferenciaStock()
+		movimientos = append(movimientos, movimiento)
+	}
+	return movimientos, nil
+}
+
+// emitirVentaRegistrada emite EventVentaRegistrada con la venta ya guardada,
+// en la transacción que la guarda
+func (pds *ProcesadorDatosService) emitirVentaRegistrada(ctx context.Context, venta *entities.Venta) error {
+	return pds.emitir(ctx, events.EventVentaRegistrada, map[string]interface{}{
+		"venta_id":    venta.ID,
+		"sucursal_id": venta.SucursalID,
+		"cliente_id":  venta.ClienteID,
+		"fecha_venta": venta.FechaVenta,
+		"estado":      venta.Estado,
+		"total":       venta.Total,
+		"impuestos":   venta.Impuestos,
+		"retenciones": venta.Retenciones,
+		"detalles":    len(venta.DetallesVenta),
+	})
+}
+
+// prepararVenta convierte el registro en una venta con sus detalles. Los
+// detalles se toman del arreglo "detalles" (o "detalles_venta"); un registro sin
+// arreglo describe una venta de una sola línea. Los impuestos se calculan con
+// las reglas de impuestos según la categoría de cada producto y la sucursal, y
+// si el origen informa el total de la venta, debe coincidir con el calculado.
+func (pds *ProcesadorDatosService) prepararVenta(ctx context.Context, sucursalID uint, dato map[string]interface{}) (*entities.Venta, error) {
+	venta := mapearVenta(sucursalID, dato)
+
+	lineas, conDetalles := lineasVenta(dato)
+	if conDetalles {
+		// Con detalles propios, "descuento" es el descuento general de la venta
+		venta.Descuento = numero(dato, "descuento_total", "descuento")
+	}
+	categorias := make([]string, 0, len(lineas))
+	for _, linea := range lineas {
+		detalle, categoria, err := pds.mapearDetalleVenta(ctx, linea, venta.FechaVenta)
+		if err != nil {
+			return nil, err
+		}
+		venta.DetallesVenta = append(venta.DetallesVenta, *detalle)
+		categorias = append(categorias, categoria)
+	}
+	pds.impuestos.Aplicar(venta, categorias)
+
+	if !venta.EsValida() {
+		return nil, retry.Permanente(fmt.Errorf("%w: la venta requiere sucursal, fecha y al menos un detalle", ErrDatosInvalidos))
+	}
+	if !entities.EsEstadoVentaInicial(venta.Estado) {
+		return nil, retry.Permanente(fmt.Errorf("%w: estado de venta %q inválido (se admite %s)", ErrDatosInvalidos, venta.Estado, strings.Join(entities.EstadosVentaIniciales, ", ")))
+	}
+	if total, informado := totalInformado(dato, conDetalles); informado && !venta.CoincideTotal(total) {
+		return nil, retry.Permanente(fmt.Errorf("%w: el total informado %.2f no coincide con el calculado %.2f", ErrDatosInvalidos, total, venta.Total))
+	}
+	return venta, nil
+}
+
+// mapearDetalleVenta convierte una línea en un detalle, resolviendo el producto
+// por ID o SKU, y retorna también la categoría del producto. Una línea sin precio
+// toma el del producto vigente a la fecha de la venta.
+func (pds *ProcesadorDatosService) mapearDetalleVenta(ctx context.Context, linea map[string]interface{}, fechaVenta time.Time) (*entities.DetalleVenta, string, error) {
+	detalle := &entities.DetalleVenta{
+		PrecioUnitario: numero(linea, "precio_unitario", "precio"),
+		Descuento:      numero(linea, "descuento"),
+	}
+	detalle.Cantidad, _ = entero(linea, "cantidad")
+
+	var producto *entities.Producto
+	var err error
+	if productoID, ok := entero(linea, "producto_id"); ok && productoID > 0 {
+		detalle.ProductoID = uint(productoID)
+		if pds.productos != nil {
+			producto, err = pds.productos.ObtenerPorID(ctx, detalle.ProductoID)
+			if errors.Is(err, repositories.ErrNoEncontrado) {
+				return nil, "", retry.Permanente(fmt.Errorf("producto %d no encontrado", productoID))
+			}
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	} else if sku := texto(linea, "sku"); sku != "" && pds.productos != nil {
+		producto, err = pds.productos.ObtenerPorSKU(ctx, sku)
+		if errors.Is(err, repositories.ErrNoEncontrado) {
+			return nil, "", retry.Permanente(fmt.Errorf("producto con SKU %s no encontrado", sku))
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		detalle.ProductoID = producto.ID
+	}
+
+	// Sin precio informado se usa el vigente del producto en la fecha de la
+	// venta; si no hay catálogo para consultarlo la línea no puede valorizarse
+	var categoria string
+	if producto != nil {
+		categoria = producto.Categoria
+		if detalle.PrecioUnitario == 0 {
+			if detalle.PrecioUnitario, err = pds.precioVigente(ctx, producto, fechaVenta); err != nil {
+				return nil, "", err
+			}
+		}
+	} else if detalle.ProductoID != 0 && detalle.PrecioUnitario == 0 {
+		return nil, "", retry.Permanente(fmt.Errorf("%w: el detalle del producto %d no informa precio y no hay catálogo para obtenerlo", ErrDatosInvalidos, detalle.ProductoID))
+	}
+
+	if detalle.ProductoID == 0 || detalle.Cantidad <= 0 || detalle.PrecioUnitario < 0 {
+		return nil, "", retry.Permanente(fmt.Errorf("%w: cada detalle requiere producto, cantidad positiva y precio", ErrDatosInvalidos))
+	}
+
+	detalle.CalcularTotal()
+	return detalle, categoria, nil
+}
+
+// precioVigente retorna el precio final del producto vigente en la fecha
+// indicada, o el actual si el historial no tiene un precio para esa fecha
+func (pds *ProcesadorDatosService) precioVigente(ctx context.Context, producto *entities.Producto, fecha time.Time) (float64, error) {
+	if pds.precios == nil || fecha.IsZero() {
+		return producto.CalcularPrecioFinal(), nil
+	}
+
+	precio, err := pds.precios.ObtenerVigente(ctx, producto.ID, fecha)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return producto.CalcularPrecioFinal(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return precio.CalcularPrecioFinal(), nil
+}
+
+// mapearProducto convierte un registro en un Producto
+func mapearProducto(dato map[string]interface{}) *entities.Producto {
+	producto := &entities.Producto{
+		SKU:          texto(dato, "sku"),
+		Nombre:       texto(dato, "nombre"),
+		Descripcion:  texto(dato, "descripcion"),
+		Categoria:    texto(dato, "categoria"),
+		Fabricante:   texto(dato, "fabricante"),
+		Precio:       numero(dato, "precio"),
+		PrecioOferta: numero(dato, "precio_oferta"),
+		Estado:       texto(dato, "estado"),
+	}
+	producto.StockMinimo, _ = entero(dato, "stock_minimo")
+	if producto.Estado == "" {
+		producto.Estado = "activo"
+	}
+	return producto
+}
+
+// camposPresentes retorna los campos de Producto que trae el registro; "stock"
+// se acepta como sinónimo de stock_actual
+func camposPresentes(dato map[string]interface{}) []string {
+	var campos []string
+	for _, campo := range entities.CamposProducto {
+		_, presente := dato[campo]
+		if !presente && campo == "stock_actual" {
+			_, presente = dato["stock"]
+		}
+		if presente {
+			campos = append(campos, campo)
+		}
+	}
+	return campos
+}
+
+// registroStock es un registro de stock: un movimiento del tipo indicado o, sin
+// tipo, el stock contado en la sucursal
+type registroStock struct {
+	sku        string
+	tipo       string
+	cantidad   int
+	referencia string
+}
+
+// esConteo indica si el registro informa el stock contado en lugar de un movimiento
+func (r registroStock) esConteo() bool {
+	return r.tipo == ""
+}
+
+// movimiento retorna el movimiento que corresponde al registro. Un conteo se
+// convierte en un ajuste por la diferencia con el saldo de la sucursal, y no
+// genera movimiento si coinciden.
+func (r registroStock) movimiento(productoID, sucursalID uint, saldo int) (*entities.MovimientoStock, error) {
+	tipo, cantidad := r.tipo, r.cantidad
+	if r.esConteo() {
+		tipo, cantidad = entities.MovimientoAjuste, r.cantidad-saldo
+		if cantidad == 0 {
+			return nil, nil
+		}
+	}
+
+	movimiento, err := entities.NewMovimientoStock(tipo, productoID, sucursalID, cantidad)
+	if err != nil {
+		return nil, retry.Permanente(fmt.Errorf("%w: %v", ErrDatosInvalidos, err))
+	}
+	movimiento.Referencia = r.referencia
+	return movimiento, nil
+}
+
+// mapearStock convierte un registro de stock. Con "movimiento" (venta,
+// devolucion, ajuste, transferencia o recepcion) "cantidad" es la variación; sin
+// él, stock_actual es el stock contado en la sucursal.
+func mapearStock(dato map[string]interface{}) (registroStock, error) {
+	registro := registroStock{
+		sku:        texto(dato, "sku"),
+		tipo:       strings.ToLower(texto(dato, "movimiento", "tipo_movimiento")),
+		referencia: texto(dato, "referencia"),
+	}
+
+	var tieneCantidad bool
+	if registro.esConteo() {
+		registro.cantidad, tieneCantidad = entero(dato, "stock_actual", "stock", "cantidad")
+	} else {
+		registro.cantidad, tieneCantidad = entero(dato, "cantidad")
+		if !entities.EsTipoMovimiento(registro.tipo) {
+			return registro, retry.Permanente(fmt.Errorf("%w: tipo de movimiento de stock desconocido %q", ErrDatosInvalidos, registro.tipo))
+		}
+	}
+	if registro.sku == "" || !tieneCantidad {
+		return registro, retry.Permanente(fmt.Errorf("%w: el registro de stock requiere sku y stock_actual, o movimiento y cantidad", ErrDatosInvalidos))
+	}
+	return registro, nil
+}
+
+// stockInformado retorna el stock que trae un registro de producto, si la
+// sucursal tiene autoridad sobre él
+func stockInformado(destino destinoPersistencia, dato map[string]interface{}) (int, bool) {
+	if !destino.tieneAutoridad("stock_actual") {
+		return 0, false
+	}
+	return entero(dato, "stock_actual", "stock")
+}
+
+// mapearVenta convierte la cabecera de un registro en una Venta de la sucursal del lote
+func mapearVenta(sucursalID uint, dato map[string]interface{}) *entities.Venta {
+	venta := &entities.Venta{
+		SucursalID: sucursalID,
+		FechaVenta: fecha(dato, "fecha_venta", "fecha"),
+		Descuento:  numero(dato, "descuento_total"),
+		Estado:     strings.ToLower(texto(dato, "estado")),
+		MetodoPago: texto(dato, "metodo_pago"),
+	}
+	if clienteID, ok := entero(dato, "cliente_id"); ok && clienteID > 0 {
+		venta.ClienteID = uint(clienteID)
+	}
+	if venta.SucursalID == 0 {
+		if id, ok := entero(dato, "sucursal_id"); ok && id > 0 {
+			venta.SucursalID = uint(id)
+		}
+	}
+	if venta.Estado == "" {
+		venta.Estado = entities.EstadoVentaCompletada
+	}
+	return venta
+}
+
+// lineasVenta retorna las líneas de detalle del registro de venta e indica si
+// venían en un arreglo o si el propio registro es la única línea
+func lineasVenta(dato map[string]interface{}) ([]map[string]interface{}, bool) {
+	for _, campo := range []string{"detalles", "detalles_venta"} {
+		elementos, ok := dato[campo].([]interface{})
+		if !ok {
+			continue
+		}
+		lineas := make([]map[string]interface{}, 0, len(elementos))
+		for _, elemento := range elementos {
+			if linea, ok := elemento.(map[string]interface{}); ok {
+				lineas = append(lineas, linea)
+			}
+		}
+		return lineas, true
+	}
+	return []map[string]interface{}{dato}, false
+}
+
+// totalInformado retorna el total de la venta declarado por el origen. En una
+// venta de una sola línea "total" puede ser el de la línea, por lo que solo se
+// toma "total_venta".
+func totalInformado(dato map[string]interface{}, conDetalles bool) (float64, bool) {
+	campos := []string{"total_venta"}
+	if conDetalles {
+		campos = append(campos, "total")
+	}
+	for _, campo := range campos {
+		if total, ok := aNumero(dato[campo]); ok {
+			return total, true
+		}
+	}
+	return 0, false
+}
+
+// errorRepositorio marca como permanentes los errores que no se resuelven reintentando
+func errorRepositorio(err error) error {
+	if errors.Is(err, repositories.ErrDuplicado) || errors.Is(err, repositories.ErrReferenciaInvalida) ||
+		errors.Is(err, repositories.ErrEntidadInvalida) {
+		return retry.Permanente(err)
+	}
+	return err
+}
+
+// texto retorna el primer campo presente como texto. Los números enteros
+// (por ejemplo un SKU numérico leído de un CSV) se formatean sin decimales.
+func texto(dato map[string]interface{}, campos ...string) string {
+	for _, campo := range campos {
+		switch v := dato[campo].(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			return strconv.Itoa(v)
+		}
+	}
+	return ""
+}
+
+// numero retorna el primer campo presente como número, o 0
+func numero(dato map[string]interface{}, campos ...string) float64 {
+	for _, campo := range campos {
+		if valor, ok := aNumero(dato[campo]); ok {
+			return valor
+		}
+	}
+	return 0
+}
+
+// entero retorna el primer campo presente como entero e indica si lo encontró
+func entero(dato map[string]interface{}, campos ...string) (int, bool) {
+	for _, campo := range campos {
+		if valor, ok := aNumero(dato[campo]); ok {
+			return int(valor), true
+		}
+	}
+	return 0, false
+}
+
+func aNumero(valor interface{}) (float64, bool) {
+	switch v := valor.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case string:
+		numero, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", "."), 64)
+		return numero, err == nil
+	}
+	return 0, false
+}
+
+// fecha retorna el primer campo presente como fecha, o la fecha cero
+func fecha(dato map[string]interface{}, campos ...string) time.Time {
+	for _, campo := range campos {
+		switch v := dato[campo].(type) {
+		case time.Time:
+			return v
+		case string:
+			for _, formato := range formatosFecha {
+				if valor, err := time.Parse(formato, strings.TrimSpace(v)); err == nil {
+					return valor
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/persistence"
+)
+
+// procesadorProductos crea un procesador sobre repositorios en memoria con la
+// casa central (sucursal 1), con autoridad sobre el catálogo pero no sobre el
+// stock, y una sucursal (2) que solo informa su stock. Los productos A1 y C3
+// existen con precio 100 y A1 tiene 5 unidades en la sucursal 2.
+func procesadorProductos(t *testing.T, tamanoBloque int) (*ProcesadorDatosService, *persistence.ProductoRepositoryMemoria) {
+	t.Helper()
+	ctx := context.Background()
+
+	sucursales := persistence.NewSucursalRepositoryMemoria()
+	for _, sucursal := range []*entities.Sucursal{
+		{Nombre: "Casa central", Estado: "activa", Configuracion: `{"autoridad_producto":["nombre","categoria","precio","precio_oferta","estado"]}`},
+		{Nombre: "Sucursal norte", Estado: "activa", Configuracion: `{"autoridad_producto":["stock_actual"]}`},
+	} {
+		if err := sucursales.Guardar(ctx, sucursal); err != nil {
+			t.Fatalf("Guardar sucursal: %v", err)
+		}
+	}
+
+	productos := persistence.NewProductoRepositoryMemoria(persistence.NewPrecioHistoricoRepositoryMemoria())
+	movimientos := persistence.NewMovimientoStockRepositoryMemoria(productos)
+	for _, sku := range []string{"A1", "C3"} {
+		producto := &entities.Producto{SKU: sku, Nombre: "Producto " + sku, Categoria: "general", Precio: 100, Estado: "activo"}
+		if err := productos.Guardar(ctx, producto); err != nil {
+			t.Fatalf("Guardar producto: %v", err)
+		}
+	}
+	recepcion, _ := entities.NewMovimientoStock(entities.MovimientoRecepcion, 1, 2, 5)
+	if err := movimientos.Registrar(ctx, recepcion); err != nil {
+		t.Fatalf("Registrar recepción: %v", err)
+	}
+
+	pds := NewProcesadorDatosService(events.NewEventBus(), nil, sucursales, productos, nil, nil, movimientos)
+	pds.SetOutbox(persistence.NewOutboxRepositoryMemoria(), persistence.TransaccionesMemoria{}, nil)
+	pds.SetTamanoBloque(tamanoBloque)
+	return pds, productos
+}
+
+func TestPersistirProductosPorSKUConAutoridad(t *testing.T) {
+	casos := []struct {
+		nombre       string
+		sucursalID   uint
+		datos        []map[string]interface{}
+		insertados   int
+		actualizados int
+		sinCambios   int
+		precio       float64 // precio de A1 después del lote
+		stock        int     // stock de A1 después del lote
+	}{
+		{
+			nombre:     "casa central inserta, actualiza y deja sin cambios",
+			sucursalID: 1,
+			datos: []map[string]interface{}{
+				{"sku": "B2", "nombre": "Producto B2", "precio": 50},
+				{"sku": "A1", "nombre": "Producto A1", "precio": 120},
+				{"sku": "C3", "nombre": "Producto C3", "precio": 100},
+			},
+			insertados: 1, actualizados: 1, sinCambios: 1,
+			precio: 120, stock: 5,
+		},
+		{
+			nombre:     "casa central no sobrescribe el stock",
+			sucursalID: 1,
+			datos: []map[string]interface{}{
+				{"sku": "A1", "precio": 100, "stock_actual": 50},
+			},
+			sinCambios: 1,
+			precio:     100, stock: 5,
+		},
+		{
+			nombre:     "sucursal no sobrescribe el precio",
+			sucursalID: 2,
+			datos: []map[string]interface{}{
+				{"sku": "A1", "nombre": "Otro nombre", "precio": 1, "stock_actual": 8},
+			},
+			actualizados: 1,
+			precio:       100, stock: 8,
+		},
+		{
+			nombre:     "sucursal con el mismo stock",
+			sucursalID: 2,
+			datos: []map[string]interface{}{
+				{"sku": "A1", "precio": 1, "stock_actual": 5},
+				{"sku": "C3", "precio": 1},
+			},
+			sinCambios: 2,
+			precio:     100, stock: 5,
+		},
+	}
+
+	modos := []struct {
+		nombre       string
+		tamanoBloque int
+	}{
+		{"de a uno", 1},
+		{"en bloque", TamanoBloquePorDefecto},
+	}
+
+	for _, modo := range modos {
+		for _, caso := range casos {
+			t.Run(modo.nombre+"/"+caso.nombre, func(t *testing.T) {
+				ctx := context.Background()
+				pds, productos := procesadorProductos(t, modo.tamanoBloque)
+
+				resultado, err := pds.ProcesarLote(ctx, &DatosCrudos{Tipo: "producto", SucursalID: caso.sucursalID, Datos: caso.datos})
+				if err != nil {
+					t.Fatalf("ProcesarLote: %v", err)
+				}
+				if resultado.RegistrosInsertados != caso.insertados || resultado.RegistrosActualizados != caso.actualizados || resultado.RegistrosSinCambios != caso.sinCambios {
+					t.Errorf("insertados %d, actualizados %d y sin cambios %d; se esperaban %d, %d y %d",
+						resultado.RegistrosInsertados, resultado.RegistrosActualizados, resultado.RegistrosSinCambios,
+						caso.insertados, caso.actualizados, caso.sinCambios)
+				}
+				if resultado.RegistrosFallidos != 0 {
+					t.Errorf("RegistrosFallidos = %d, se esperaba 0", resultado.RegistrosFallidos)
+				}
+				// Un bloque de un solo registro se escribe de a uno
+				if enBloque := modo.tamanoBloque > 1 && len(caso.datos) > 1; enBloque && resultado.Bloques[0].EnBloque != len(caso.datos) {
+					t.Errorf("bloque = %+v, se esperaban los %d registros escritos en bloque", resultado.Bloques[0], len(caso.datos))
+				}
+
+				producto, err := productos.ObtenerPorSKU(ctx, "A1")
+				if err != nil {
+					t.Fatalf("ObtenerPorSKU: %v", err)
+				}
+				if !casiIgual(producto.Precio, caso.precio) {
+					t.Errorf("precio de A1 = %v, se esperaba %v", producto.Precio, caso.precio)
+				}
+				if producto.StockActual != caso.stock {
+					t.Errorf("stock de A1 = %d, se esperaba %d", producto.StockActual, caso.stock)
+				}
+				if caso.sucursalID == 2 && producto.Nombre != "Producto A1" {
+					t.Errorf("la sucursal cambió el nombre de A1 a %q", producto.Nombre)
+				}
+			})
+		}
+	}
+}
@@ -2,32 +2,86 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
+	"sistema-gestion-informacion/internal/domain/filtros"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/connectors"
 	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/retry"
 )
 
 // ProcesadorDatosService implementa la lógica de procesamiento de datos
 type ProcesadorDatosService struct {
-	eventBus *events.EventBus
+	emisorEventos
+	politicaReintento    *retry.Politica
+	politicaPersistencia *retry.Politica
+	sucursales           repositories.SucursalRepository
+	productos            repositories.ProductoRepository
+	ventas               repositories.VentaRepository
+	precios              repositories.PrecioHistoricoRepository
+	movimientos          repositories.MovimientoStockRepository
+	archivo              repositories.LoteArchivadoRepository
+	lotes                *SeguimientoLotes
+	tamanoBloque         int
+	impuestos            *ReglasImpuestos
+
+	// filtros compilados por sucursal, junto con la configuración de la que provienen
+	filtros      map[uint]filtrosSucursal
+	filtrosMutex sync.Mutex
+}
+
+// filtrosSucursal guarda los filtros compilados de una sucursal
+type filtrosSucursal struct {
+	configuracion string
+	expresiones   []*filtros.Expresion
 }
 
 // NewProcesadorDatosService crea una nueva instancia del servicio
-func NewProcesadorDatosService(eventBus *events.EventBus) *ProcesadorDatosService {
+func NewProcesadorDatosService(
+	eventBus *events.EventBus,
+	politicaReintento *retry.Politica,
+	sucursales repositories.SucursalRepository,
+	productos repositories.ProductoRepository,
+	ventas repositories.VentaRepository,
+	precios repositories.PrecioHistoricoRepository,
+	movimientos repositories.MovimientoStockRepository,
+) *ProcesadorDatosService {
+	if politicaReintento == nil {
+		politicaReintento = retry.PoliticaPorDefecto()
+	}
 	return &ProcesadorDatosService{
-		eventBus: eventBus,
+		emisorEventos:        emisorEventos{eventBus: eventBus, origen: "procesador_datos"},
+		politicaReintento:    politicaReintento,
+		politicaPersistencia: retry.PoliticaPersistenciaPorDefecto(),
+		sucursales:           sucursales,
+		productos:            productos,
+		ventas:               ventas,
+		precios:              precios,
+		movimientos:          movimientos,
+		lotes:                NewSeguimientoLotes(),
+		tamanoBloque:         TamanoBloquePorDefecto,
+		impuestos:            ReglasImpuestosPorDefecto(),
+		filtros:              make(map[uint]filtrosSucursal),
 	}
 }
 
 // DatosCrudos representa los datos brutos recibidos de las fuentes
 type DatosCrudos struct {
+	LoteID     string                   `json:"lote_id"`
 	Origen     string                   `json:"origen"`
 	Tipo       string                   `json:"tipo"` // 'cliente', 'venta', 'producto', 'stock'
 	Datos      []map[string]interface{} `json:"datos"`
 	Timestamp  time.Time                `json:"timestamp"`
 	SucursalID uint                     `json:"sucursal_id"`
+
+	// ReprocesoDe es el lote archivado que se vuelve a procesar, si lo es
+	ReprocesoDe string `json:"-"`
 }
 
 // RegistroProcesado representa un registro después del procesamiento
@@ -39,72 +93,240 @@ type RegistroProcesado struct {
 	Enriquecido bool                   `json:"enriquecido"`
 }
 
-// ProcesarLote procesa un lote de datos brutos
-func (pds *ProcesadorDatosService) ProcesarLote(ctx context.Context, datosCrudos *DatosCrudos) error {
+// ResultadoLote resume el resultado del procesamiento de un lote
+type ResultadoLote struct {
+	LoteID               string    `json:"lote_id"`
+	Origen               string    `json:"origen"`
+	Tipo                 string    `json:"tipo"`
+	SucursalID           uint      `json:"sucursal_id"`
+	RegistrosRecibidos   int       `json:"registros_recibidos"`
+	RegistrosValidos     int       `json:"registros_validos"`
+	RegistrosFiltrados   int       `json:"registros_filtrados"`
+	RegistrosUnicos      int       `json:"registros_unicos"`
+	RegistrosPersistidos int       `json:"registros_persistidos"`
+	RegistrosFallidos    int       `json:"registros_fallidos"`
+	Inicio               time.Time `json:"inicio"`
+	Fin                  time.Time `json:"fin"`
+
+	// Desglose de los registros persistidos
+	RegistrosInsertados   int `json:"registros_insertados"`
+	RegistrosActualizados int `json:"registros_actualizados"`
+	RegistrosSinCambios   int `json:"registros_sin_cambios"`
+
+	// Métricas de escritura de cada bloque
+	Bloques []MetricaBloque `json:"bloques,omitempty"`
+
+	RegistrosConAdvertencias int                  `json:"registros_con_advertencias"`
+	Advertencias             []AdvertenciaCalidad `json:"advertencias,omitempty"`
+}
+
+// AdvertenciaCalidad señala un problema de calidad de datos que no impide procesar el registro
+type AdvertenciaCalidad struct {
+	Registro int    `json:"registro"` // posición del registro en el lote, desde 1
+	Campo    string `json:"campo"`
+	Mensaje  string `json:"mensaje"`
+}
+
+// maxAdvertenciasPorLote limita las advertencias detalladas incluidas en el resultado
+const maxAdvertenciasPorLote = 100
+
+// NuevoLoteID genera un identificador para un lote de datos
+func NuevoLoteID() string {
+	return fmt.Sprintf("lote_%d", time.Now().UnixNano())
+}
+
+// ProcesarLote procesa un lote de datos brutos. Con un archivo de lotes
+// configurado, el lote se archiva antes de procesarse junto con su resultado.
+func (pds *ProcesadorDatosService) ProcesarLote(ctx context.Context, datosCrudos *DatosCrudos) (*ResultadoLote, error) {
+	if datosCrudos.LoteID == "" {
+		datosCrudos.LoteID = NuevoLoteID()
+	}
+
+	archivado := pds.archivarLote(ctx, datosCrudos)
+	resultado, err := pds.procesarLote(ctx, datosCrudos)
+	if archivado {
+		pds.registrarResultadoArchivo(ctx, resultado, err)
+	}
+	return resultado, err
+}
+
+// procesarLote ejecuta las etapas del procesamiento sobre el lote. En una
+// simulación no publica los eventos del lote.
+func (pds *ProcesadorDatosService) procesarLote(ctx context.Context, datosCrudos *DatosCrudos) (*ResultadoLote, error) {
 	log.Printf("Iniciando procesamiento de lote desde %s", datosCrudos.Origen)
+	simulacion := esSimulacion(ctx)
+
+	resultado := &ResultadoLote{
+		LoteID:             datosCrudos.LoteID,
+		Origen:             datosCrudos.Origen,
+		Tipo:               datosCrudos.Tipo,
+		SucursalID:         datosCrudos.SucursalID,
+		RegistrosRecibidos: len(datosCrudos.Datos),
+		Inicio:             time.Now(),
+	}
+
+	// Detectar problemas de calidad en los datos recibidos
+	pds.detectarAdvertenciasCalidad(datosCrudos.Datos, resultado)
 
 	// Publicar evento de inicio de procesamiento
-	pds.eventBus.Publish(events.CreateEvent(
-		events.EventDatosRecolectados,
-		map[string]interface{}{
-			"origen":      datosCrudos.Origen,
-			"tipo":        datosCrudos.Tipo,
-			"cantidad":    len(datosCrudos.Datos),
-			"sucursal_id": datosCrudos.SucursalID,
-		},
-		"procesador_datos",
-	))
+	if !simulacion {
+		pds.eventBus.Publish(events.CreateEvent(
+			events.EventDatosRecolectados,
+			map[string]interface{}{
+				"lote_id":     datosCrudos.LoteID,
+				"origen":      datosCrudos.Origen,
+				"tipo":        datosCrudos.Tipo,
+				"cantidad":    len(datosCrudos.Datos),
+				"sucursal_id": datosCrudos.SucursalID,
+			},
+			"procesador_datos",
+		))
+	}
 
 	// Normalizar datos
 	datosNormalizados, err := pds.normalizarDatos(datosCrudos)
 	if err != nil {
-		pds.publicarError("error_normalizacion", err)
-		return fmt.Errorf("error normalizando datos: %v", err)
+		pds.publicarError(ctx, "error_normalizacion", err)
+		return resultado, fmt.Errorf("error normalizando datos: %v", err)
 	}
 
 	// Validar datos
 	datosValidados, err := pds.validarDatos(datosNormalizados)
 	if err != nil {
-		pds.publicarError("error_validacion", err)
-		return fmt.Errorf("error validando datos: %v", err)
+		pds.publicarError(ctx, "error_validacion", err)
+		return resultado, fmt.Errorf("error validando datos: %v", err)
 	}
+	resultado.RegistrosValidos = len(datosValidados)
+
+	// Aplicar filtros configurados para la sucursal
+	datosFiltrados, err := pds.filtrarDatos(ctx, datosCrudos.SucursalID, datosValidados)
+	if err != nil {
+		pds.publicarError(ctx, "error_filtrado", err)
+		return resultado, fmt.Errorf("error filtrando datos: %v", err)
+	}
+	resultado.RegistrosFiltrados = len(datosValidados) - len(datosFiltrados)
 
 	// Enriquecer datos
-	datosEnriquecidos, err := pds.enriquecerDatos(datosValidados)
+	datosEnriquecidos, err := pds.enriquecerDatos(datosFiltrados)
 	if err != nil {
-		pds.publicarError("error_enriquecimiento", err)
-		return fmt.Errorf("error enriqueciendo datos: %v", err)
+		pds.publicarError(ctx, "error_enriquecimiento", err)
+		return resultado, fmt.Errorf("error enriqueciendo datos: %v", err)
 	}
 
 	// Eliminar duplicados
 	datosFinales, err := pds.eliminarDuplicados(datosEnriquecidos)
 	if err != nil {
-		pds.publicarError("error_deduplicacion", err)
-		return fmt.Errorf("error eliminando duplicados: %v", err)
+		pds.publicarError(ctx, "error_deduplicacion", err)
+		return resultado, fmt.Errorf("error eliminando duplicados: %v", err)
 	}
-
-	// Persistir datos
-	err = pds.persistirDatos(ctx, datosFinales)
+	resultado.RegistrosUnicos = len(datosFinales)
+
+	// Persistir datos, informando el lote como origen de los cambios
+	ctxPersistencia := repositories.ConOrigenCambio(ctx, repositories.OrigenCambio{
+		SucursalID: datosCrudos.SucursalID,
+		LoteID:     datosCrudos.LoteID,
+	})
+	conteo, err := pds.persistirDatos(ctxPersistencia, datosCrudos.SucursalID, datosCrudos.Tipo, datosFinales)
+	resultado.RegistrosPersistidos = conteo.total()
+	resultado.RegistrosInsertados = conteo.insertados
+	resultado.RegistrosActualizados = conteo.actualizados
+	resultado.RegistrosSinCambios = conteo.sinCambios
+	resultado.Bloques = conteo.bloques
+	resultado.RegistrosFallidos = len(datosFinales) - conteo.total()
 	if err != nil {
-		pds.publicarError("error_persistencia", err)
-		return fmt.Errorf("error persistiendo datos: %v", err)
+		pds.publicarError(ctx, "error_persistencia", err)
+		return resultado, fmt.Errorf("error persistiendo datos: %v", err)
 	}
 
 	// Publicar evento de procesamiento completado
+	if !simulacion {
+		pds.eventBus.Publish(events.CreateEvent(
+			events.EventDatosProcesados,
+			map[string]interface{}{
+				"lote_id":           datosCrudos.LoteID,
+				"origen":            datosCrudos.Origen,
+				"tipo":              datosCrudos.Tipo,
+				"registros_inicial": len(datosCrudos.Datos),
+				"registros_final":   len(datosFinales),
+				"sucursal_id":       datosCrudos.SucursalID,
+			},
+			"procesador_datos",
+		))
+	}
+
+	resultado.Fin = time.Now()
+	log.Printf("Procesamiento completado: %d registros procesados", len(datosFinales))
+	return resultado, nil
+}
+
+// EncolarLote registra el lote y lo procesa en segundo plano. Retorna el ID
+// del lote para consultar su estado con ObtenerEstadoLote.
+func (pds *ProcesadorDatosService) EncolarLote(datosCrudos *DatosCrudos) string {
+	if datosCrudos.LoteID == "" {
+		datosCrudos.LoteID = NuevoLoteID()
+	}
+	pds.lotes.Registrar(datosCrudos)
+
+	go func() {
+		pds.lotes.Actualizar(datosCrudos.LoteID, EstadoLoteProcesando, nil, nil)
+
+		resultado, err := pds.ProcesarLote(context.Background(), datosCrudos)
+		if err != nil {
+			log.Printf("Error procesando lote %s: %v", datosCrudos.LoteID, err)
+			pds.lotes.Actualizar(datosCrudos.LoteID, EstadoLoteFallido, resultado, err)
+			return
+		}
+		pds.lotes.Actualizar(datosCrudos.LoteID, EstadoLoteCompletado, resultado, nil)
+	}()
+
+	return datosCrudos.LoteID
+}
+
+// ObtenerEstadoLote retorna el estado de un lote encolado
+func (pds *ProcesadorDatosService) ObtenerEstadoLote(loteID string) (EstadoLote, bool) {
+	return pds.lotes.Obtener(loteID)
+}
+
+// SincronizarSucursal obtiene los datos de una sucursal mediante su conector,
+// reintentando las fallas transitorias, y los procesa como un lote
+func (pds *ProcesadorDatosService) SincronizarSucursal(ctx context.Context, sucursalID uint, tipo string, conector connectors.Conector) (*ResultadoLote, error) {
+	var datos []map[string]interface{}
+
+	err := pds.ejecutarConReintentos(ctx, pds.politicaReintento, "obtencion_datos", sucursalID, func(ctx context.Context) error {
+		var err error
+		datos, err = conector.Obtener(ctx)
+		return err
+	})
+	if err != nil {
+		pds.publicarError(ctx, "error_obtencion", err)
+		return nil, fmt.Errorf("error obteniendo datos de la sucursal %d: %v", sucursalID, err)
+	}
+
+	datosCrudos := &DatosCrudos{
+		Origen:     conector.GetTipo(),
+		Tipo:       tipo,
+		Datos:      datos,
+		Timestamp:  time.Now(),
+		SucursalID: sucursalID,
+	}
+
+	resultado, err := pds.ProcesarLote(ctx, datosCrudos)
+	if err != nil {
+		return resultado, err
+	}
+
 	pds.eventBus.Publish(events.CreateEvent(
-		events.EventDatosProcesados,
+		events.EventSincronizacionCompletada,
 		map[string]interface{}{
-			"origen":            datosCrudos.Origen,
-			"tipo":              datosCrudos.Tipo,
-			"registros_inicial": len(datosCrudos.Datos),
-			"registros_final":   len(datosFinales),
-			"sucursal_id":       datosCrudos.SucursalID,
+			"sucursal_id": sucursalID,
+			"lote_id":     resultado.LoteID,
+			"origen":      conector.GetTipo(),
+			"cantidad":    len(datos),
 		},
 		"procesador_datos",
 	))
 
-	log.Printf("Procesamiento completado: %d registros procesados", len(datosFinales))
-	return nil
+	return resultado, nil
 }
 
 // normalizarDatos convierte los datos a un formato estándar
@@ -164,6 +386,74 @@ func (pds *ProcesadorDatosService) validarDatos(datos []map[string]interface{})
 	return datosValidados, nil
 }
 
+// filtrarDatos descarta los registros que no cumplen todos los filtros de la sucursal
+func (pds *ProcesadorDatosService) filtrarDatos(ctx context.Context, sucursalID uint, datos []map[string]interface{}) ([]map[string]interface{}, error) {
+	expresiones, err := pds.obtenerFiltros(ctx, sucursalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(expresiones) == 0 {
+		return datos, nil
+	}
+
+	log.Printf("Filtrando %d registros con %d filtros", len(datos), len(expresiones))
+
+	var datosFiltrados []map[string]interface{}
+	for _, dato := range datos {
+		cumple := true
+		for _, expresion := range expresiones {
+			if !expresion.Evaluar(dato) {
+				cumple = false
+				break
+			}
+		}
+		if cumple {
+			datosFiltrados = append(datosFiltrados, dato)
+		}
+	}
+
+	return datosFiltrados, nil
+}
+
+// obtenerFiltros retorna los filtros compilados de la sucursal, compilándolos
+// solo la primera vez o cuando cambia su configuración
+func (pds *ProcesadorDatosService) obtenerFiltros(ctx context.Context, sucursalID uint) ([]*filtros.Expresion, error) {
+	if pds.sucursales == nil || sucursalID == 0 {
+		return nil, nil
+	}
+
+	sucursal, err := pds.sucursales.ObtenerPorID(ctx, sucursalID)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pds.filtrosMutex.Lock()
+	defer pds.filtrosMutex.Unlock()
+
+	if compilados, existe := pds.filtros[sucursalID]; existe && compilados.configuracion == sucursal.Configuracion {
+		return compilados.expresiones, nil
+	}
+
+	config, err := sucursal.ObtenerConfiguracion()
+	if err != nil {
+		return nil, err
+	}
+
+	expresiones, err := config.CompilarFiltros()
+	if err != nil {
+		return nil, fmt.Errorf("filtros inválidos para la sucursal %d: %v", sucursalID, err)
+	}
+
+	pds.filtros[sucursalID] = filtrosSucursal{
+		configuracion: sucursal.Configuracion,
+		expresiones:   expresiones,
+	}
+	return expresiones, nil
+}
+
 // enriquecerDatos enriquece los datos con información adicional
 func (pds *ProcesadorDatosService) enriquecerDatos(datos []map[string]interface{}) ([]map[string]interface{}, error) {
 	log.Printf("Enriqueciendo %d registros", len(datos))
@@ -188,6 +478,37 @@ func (pds *ProcesadorDatosService) enriquecerDatos(datos []map[string]interface{
 	return datos, nil
 }
 
+// detectarAdvertenciasCalidad señala los registros con caracteres de reemplazo
+// (U+FFFD), que indican texto convertido desde una codificación equivocada
+func (pds *ProcesadorDatosService) detectarAdvertenciasCalidad(datos []map[string]interface{}, resultado *ResultadoLote) {
+	for i, dato := range datos {
+		conAdvertencia := false
+		for campo, valor := range dato {
+			texto, ok := valor.(string)
+			if !ok || !strings.ContainsRune(texto, '\uFFFD') {
+				continue
+			}
+
+			conAdvertencia = true
+			if len(resultado.Advertencias) < maxAdvertenciasPorLote {
+				resultado.Advertencias = append(resultado.Advertencias, AdvertenciaCalidad{
+					Registro: i + 1,
+					Campo:    campo,
+					Mensaje:  "contiene caracteres no decodificables; revisar la codificación de origen",
+				})
+			}
+		}
+
+		if conAdvertencia {
+			resultado.RegistrosConAdvertencias++
+		}
+	}
+
+	if resultado.RegistrosConAdvertencias > 0 {
+		log.Printf("Lote %s: %d registros con advertencias de calidad", resultado.LoteID, resultado.RegistrosConAdvertencias)
+	}
+}
+
 // eliminarDuplicados elimina registros duplicados
 func (pds *ProcesadorDatosService) eliminarDuplicados(datos []map[string]interface{}) ([]map[string]interface{}, error) {
 	log.Printf("Eliminando duplicados de %d registros", len(datos))
@@ -206,27 +527,30 @@ func (pds *ProcesadorDatosService) eliminarDuplicados(datos []map[string]interfa
 	return datosUnicos, nil
 }
 
-// persistirDatos persiste los datos en la base de datos
-func (pds *ProcesadorDatosService) persistirDatos(ctx context.Context, datos []map[string]interface{}) error {
-	log.Printf("Persistiendo %d registros", len(datos))
+// persistirDatos persiste los datos en la base de datos, en bloques de
+// tamanoBloque registros que se escriben juntos. Cada escritura emite su
+// EventDatosPersistidos en su misma transacción
+func (pds *ProcesadorDatosService) persistirDatos(ctx context.Context, sucursalID uint, tipo string, datos []map[string]interface{}) (conteoPersistencia, error) {
+	log.Printf("Persistiendo %d registros en bloques de %d", len(datos), pds.tamanoBloque)
 
-	for _, dato := range datos {
-		if err := pds.persistirRegistro(ctx, dato); err != nil {
-			log.Printf("Error persistiendo registro: %v", err)
-			continue
-		}
+	var conteo conteoPersistencia
+	destino, err := pds.destinoPersistencia(ctx, sucursalID, tipo)
+	if err != nil {
+		return conteo, err
 	}
 
-	// Publicar evento de persistencia completada
-	pds.eventBus.Publish(events.CreateEvent(
-		events.EventDatosPersistidos,
-		map[string]interface{}{
-			"registros_persistidos": len(datos),
-		},
-		"procesador_datos",
-	))
+	for inicio := 0; inicio < len(datos); inicio += pds.tamanoBloque {
+		bloque := datos[inicio:min(inicio+pds.tamanoBloque, len(datos))]
+
+		metrica := pds.persistirBloque(ctx, destino, bloque, &conteo)
+		metrica.Numero = len(conteo.bloques) + 1
+		conteo.bloques = append(conteo.bloques, metrica)
+		log.Printf("Bloque %d: %d de %d registros persistidos en %d ms (%.0f registros/s)",
+			metrica.Numero, metrica.Persistidos, metrica.Registros, metrica.DuracionMs, metrica.RegistrosPorSegundo)
+		pds.publicarMetricaBloque(ctx, destino, metrica)
+	}
 
-	return nil
+	return conteo, nil
 }
 
 // Métodos auxiliares
@@ -250,12 +574,57 @@ func (pds *ProcesadorDatosService) generarClaveUnica(dato map[string]interface{}
 	return fmt.Sprintf("%v", dato)
 }
 
-func (pds *ProcesadorDatosService) persistirRegistro(ctx context.Context, dato map[string]interface{}) error {
-	// Implementar persistencia específica según el tipo de dato
-	return nil
+// SetPoliticaPersistencia define la política de reintentos de las escrituras
+// en la base de datos, independiente de la de obtención de datos
+func (pds *ProcesadorDatosService) SetPoliticaPersistencia(politica *retry.Politica) {
+	if politica == nil {
+		politica = retry.PoliticaPersistenciaPorDefecto()
+	}
+	pds.politicaPersistencia = politica
+}
+
+// ejecutarConReintentos aplica la política de reintentos a una operación,
+// publicando un evento por cada reintento y otro si se agotan los intentos
+func (pds *ProcesadorDatosService) ejecutarConReintentos(ctx context.Context, politica *retry.Politica, operacion string, sucursalID uint, fn func(ctx context.Context) error) error {
+	err := politica.Ejecutar(ctx, fn, func(intento retry.Intento) {
+		log.Printf("Reintentando %s de sucursal %d (intento %d/%d) en %v: %v",
+			operacion, sucursalID, intento.Numero, politica.MaxIntentos, intento.Demora, intento.Error)
+
+		pds.eventBus.Publish(events.CreateEvent(
+			events.EventReintentoProgramado,
+			map[string]interface{}{
+				"operacion":    operacion,
+				"sucursal_id":  sucursalID,
+				"intento":      intento.Numero,
+				"max_intentos": politica.MaxIntentos,
+				"demora_ms":    intento.Demora.Milliseconds(),
+				"error":        intento.Error.Error(),
+			},
+			"procesador_datos",
+		))
+	})
+
+	var agotados *retry.ErrorReintentosAgotados
+	if errors.As(err, &agotados) {
+		pds.eventBus.Publish(events.CreateEvent(
+			events.EventReintentosAgotados,
+			map[string]interface{}{
+				"operacion":   operacion,
+				"sucursal_id": sucursalID,
+				"intento":     agotados.Intentos,
+				"error":       agotados.Ultimo.Error(),
+			},
+			"procesador_datos",
+		))
+	}
+
+	return err
 }
 
-func (pds *ProcesadorDatosService) publicarError(tipo string, err error) {
+func (pds *ProcesadorDatosService) publicarError(ctx context.Context, tipo string, err error) {
+	if esSimulacion(ctx) {
+		return
+	}
 	pds.eventBus.Publish(events.CreateEvent(
 		events.EventErrorProcesamiento,
 		map[string]interface{}{
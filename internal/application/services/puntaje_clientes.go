@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+)
+
+// TamanoLotePuntajes es la cantidad de clientes potenciales que se recalculan por consulta
+const TamanoLotePuntajes = 200
+
+// ModeloPuntaje define cuántos puntos aporta cada factor al puntaje de un
+// cliente potencial. Las secciones omitidas no aportan puntos.
+type ModeloPuntaje struct {
+	// Fuentes asigna puntos a cada fuente de captación; las que no figuran
+	// reciben FuenteDesconocida
+	Fuentes           map[string]float64 `json:"fuentes"`
+	FuenteDesconocida float64            `json:"fuente_desconocida"`
+	// Intereses asigna puntos a palabras clave del interés; se toma la de más
+	// puntos que aparezca, o InteresSinCoincidencia si el interés no contiene ninguna
+	Intereses              map[string]float64 `json:"intereses"`
+	InteresSinCoincidencia float64            `json:"interes_sin_coincidencia"`
+	// Sucursales asigna puntos a los clientes captados por cada sucursal
+	Sucursales    map[uint]float64     `json:"sucursales"`
+	Recencia      RecenciaPuntaje      `json:"recencia"`
+	Interacciones InteraccionesPuntaje `json:"interacciones"`
+}
+
+// RecenciaPuntaje otorga Maximo puntos a un cliente recién captado, que se
+// reducen a la mitad cada VidaMediaDias
+type RecenciaPuntaje struct {
+	Maximo        float64 `json:"maximo"`
+	VidaMediaDias float64 `json:"vida_media_dias"`
+}
+
+// InteraccionesPuntaje suma los puntos de las interacciones de los últimos
+// VentanaDias días, según su tipo, hasta Maximo. Sin ventana cuentan todas.
+type InteraccionesPuntaje struct {
+	Tipos       map[string]float64 `json:"tipos"`
+	PorDefecto  float64            `json:"por_defecto"`
+	Maximo      float64            `json:"maximo"`
+	VentanaDias int                `json:"ventana_dias"`
+}
+
+// ModeloPuntajePorDefecto retorna el modelo que se usa si no se configura otro
+func ModeloPuntajePorDefecto() *ModeloPuntaje {
+	return &ModeloPuntaje{
+		Fuentes: map[string]float64{
+			"referido":       30,
+			"evento":         20,
+			"formulario_web": 15,
+			"redes_sociales": 10,
+		},
+		FuenteDesconocida:      5,
+		InteresSinCoincidencia: 5,
+		Recencia:               RecenciaPuntaje{Maximo: 25, VidaMediaDias: 14},
+		Interacciones: InteraccionesPuntaje{
+			Tipos: map[string]float64{
+				"visita":           10,
+				"llamada":          8,
+				"email_respondido": 6,
+			},
+			PorDefecto:  3,
+			Maximo:      30,
+			VentanaDias: 30,
+		},
+	}
+}
+
+// CargarModeloPuntaje lee el modelo de puntaje de un archivo JSON
+func CargarModeloPuntaje(ruta string) (*ModeloPuntaje, error) {
+	contenido, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, err
+	}
+
+	var modelo ModeloPuntaje
+	if err := json.Unmarshal(contenido, &modelo); err != nil {
+		return nil, fmt.Errorf("modelo de puntaje inválido: %v", err)
+	}
+	if err := modelo.Validar(); err != nil {
+		return nil, err
+	}
+	return &modelo, nil
+}
+
+// Validar verifica que el modelo pueda aplicarse. Normaliza a minúsculas las
+// fuentes, palabras clave y tipos de interacción, como se guardan los clientes.
+func (m *ModeloPuntaje) Validar() error {
+	var errs []error
+	if m.Recencia.Maximo != 0 && m.Recencia.VidaMediaDias <= 0 {
+		errs = append(errs, errors.New("recencia: vida_media_dias debe ser positiva"))
+	}
+	if m.Interacciones.Maximo < 0 {
+		errs = append(errs, errors.New("interacciones: el máximo no puede ser negativo"))
+	}
+	if m.Interacciones.VentanaDias < 0 {
+		errs = append(errs, errors.New("interacciones: ventana_dias no puede ser negativa"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("modelo de puntaje inválido: %w", errors.Join(errs...))
+	}
+
+	m.Fuentes = claveMinusculas(m.Fuentes)
+	m.Intereses = claveMinusculas(m.Intereses)
+	m.Interacciones.Tipos = claveMinusculas(m.Interacciones.Tipos)
+	return nil
+}
+
+// claveMinusculas retorna el mapa con las claves sin espacios sobrantes y en minúsculas
+func claveMinusculas(puntos map[string]float64) map[string]float64 {
+	normalizado := make(map[string]float64, len(puntos))
+	for clave, valor := range puntos {
+		normalizado[strings.ToLower(strings.TrimSpace(clave))] = valor
+	}
+	return normalizado
+}
+
+// Calcular retorna el puntaje del cliente a la fecha indicada y los factores
+// que lo componen, siempre en el mismo orden
+func (m *ModeloPuntaje) Calcular(cliente *entities.ClientePotencial, interacciones []entities.InteraccionCliente, ahora time.Time) (float64, []entities.FactorPuntaje) {
+	factores := []entities.FactorPuntaje{
+		m.factorFuente(cliente),
+		m.factorInteres(cliente),
+		m.factorRecencia(cliente, ahora),
+		m.factorInteracciones(interacciones, ahora),
+		m.factorSucursal(cliente),
+	}
+
+	total := 0.0
+	for _, factor := range factores {
+		total += factor.Puntos
+	}
+	return redondearPuntos(total), factores
+}
+
+func (m *ModeloPuntaje) factorFuente(cliente *entities.ClientePotencial) entities.FactorPuntaje {
+	factor := entities.FactorPuntaje{Factor: "fuente", Valor: cliente.Fuente}
+	if puntos, existe := m.Fuentes[cliente.Fuente]; existe {
+		factor.Puntos = puntos
+		factor.Explicacion = fmt.Sprintf("la fuente %q aporta %s puntos", cliente.Fuente, formatearPuntos(puntos))
+	} else {
+		factor.Puntos = m.FuenteDesconocida
+		factor.Explicacion = fmt.Sprintf("la fuente %q no tiene puntaje propio: aporta %s puntos", cliente.Fuente, formatearPuntos(m.FuenteDesconocida))
+	}
+	return factor
+}
+
+func (m *ModeloPuntaje) factorInteres(cliente *entities.ClientePotencial) entities.FactorPuntaje {
+	factor := entities.FactorPuntaje{Factor: "interes", Valor: cliente.Interes}
+	if cliente.Interes == "" {
+		factor.Explicacion = "sin interés informado"
+		return factor
+	}
+
+	// Las palabras clave se recorren ordenadas para que un empate elija siempre la misma
+	claves := make([]string, 0, len(m.Intereses))
+	for clave := range m.Intereses {
+		claves = append(claves, clave)
+	}
+	sort.Strings(claves)
+
+	interes := strings.ToLower(cliente.Interes)
+	coincidencia := ""
+	for _, clave := range claves {
+		if clave != "" && strings.Contains(interes, clave) && (coincidencia == "" || m.Intereses[clave] > m.Intereses[coincidencia]) {
+			coincidencia = clave
+		}
+	}
+	if coincidencia == "" {
+		factor.Puntos = m.InteresSinCoincidencia
+		factor.Explicacion = fmt.Sprintf("el interés no coincide con palabras clave: aporta %s puntos", formatearPuntos(m.InteresSinCoincidencia))
+		return factor
+	}
+	factor.Puntos = m.Intereses[coincidencia]
+	factor.Explicacion = fmt.Sprintf("el interés menciona %q: aporta %s puntos", coincidencia, formatearPuntos(factor.Puntos))
+	return factor
+}
+
+func (m *ModeloPuntaje) factorRecencia(cliente *entities.ClientePotencial, ahora time.Time) entities.FactorPuntaje {
+	dias := math.Max(ahora.Sub(cliente.FechaCaptura).Hours()/24, 0)
+	factor := entities.FactorPuntaje{Factor: "recencia", Valor: fmt.Sprintf("%.0f días", math.Floor(dias))}
+	if m.Recencia.Maximo == 0 {
+		factor.Explicacion = "la recencia no puntúa"
+		return factor
+	}
+	factor.Puntos = redondearPuntos(m.Recencia.Maximo * math.Pow(0.5, dias/m.Recencia.VidaMediaDias))
+	factor.Explicacion = fmt.Sprintf("captado hace %.0f días: aporta %s de %s puntos, que se reducen a la mitad cada %s días",
+		math.Floor(dias), formatearPuntos(factor.Puntos), formatearPuntos(m.Recencia.Maximo), formatearPuntos(m.Recencia.VidaMediaDias))
+	return factor
+}
+
+func (m *ModeloPuntaje) factorInteracciones(interacciones []entities.InteraccionCliente, ahora time.Time) entities.FactorPuntaje {
+	desde := time.Time{}
+	if m.Interacciones.VentanaDias > 0 {
+		desde = ahora.AddDate(0, 0, -m.Interacciones.VentanaDias)
+	}
+
+	cantidad := 0
+	puntos := 0.0
+	for _, interaccion := range interacciones {
+		if interaccion.Fecha.Before(desde) {
+			continue
+		}
+		cantidad++
+		if valor, existe := m.Interacciones.Tipos[interaccion.Tipo]; existe {
+			puntos += valor
+		} else {
+			puntos += m.Interacciones.PorDefecto
+		}
+	}
+
+	factor := entities.FactorPuntaje{Factor: "interacciones", Valor: fmt.Sprintf("%d", cantidad)}
+	periodo := "en total"
+	if m.Interacciones.VentanaDias > 0 {
+		periodo = fmt.Sprintf("en los últimos %d días", m.Interacciones.VentanaDias)
+	}
+	factor.Puntos = redondearPuntos(math.Min(puntos, m.Interacciones.Maximo))
+	factor.Explicacion = fmt.Sprintf("%d interacciones %s suman %s puntos", cantidad, periodo, formatearPuntos(puntos))
+	if puntos > m.Interacciones.Maximo {
+		factor.Explicacion += fmt.Sprintf(", limitados a %s", formatearPuntos(m.Interacciones.Maximo))
+	}
+	return factor
+}
+
+func (m *ModeloPuntaje) factorSucursal(cliente *entities.ClientePotencial) entities.FactorPuntaje {
+	factor := entities.FactorPuntaje{Factor: "sucursal", Valor: fmt.Sprintf("%d", cliente.SucursalID)}
+	factor.Puntos = m.Sucursales[cliente.SucursalID]
+	factor.Explicacion = fmt.Sprintf("la sucursal %d aporta %s puntos", cliente.SucursalID, formatearPuntos(factor.Puntos))
+	return factor
+}
+
+// redondearPuntos redondea los puntos a dos decimales
+func redondearPuntos(puntos float64) float64 {
+	return math.Round(puntos*100) / 100
+}
+
+// formatearPuntos muestra los puntos con hasta dos decimales
+func formatearPuntos(puntos float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", puntos), "0"), ".")
+}
+
+// PuntajeClientesService calcula el puntaje de los clientes potenciales para
+// priorizarlos. Lo recalcula ante los eventos que lo afectan y periódicamente,
+// porque la recencia cambia con el paso del tiempo.
+type PuntajeClientesService struct {
+	modelo    *ModeloPuntaje
+	clientes  repositories.ClientePotencialRepository
+	intervalo time.Duration
+}
+
+// NewPuntajeClientesService crea el servicio con el modelo indicado o, sin modelo, el por defecto
+func NewPuntajeClientesService(modelo *ModeloPuntaje, clientes repositories.ClientePotencialRepository, intervalo time.Duration) *PuntajeClientesService {
+	if modelo == nil {
+		modelo = ModeloPuntajePorDefecto()
+	}
+	return &PuntajeClientesService{modelo: modelo, clientes: clientes, intervalo: intervalo}
+}
+
+// Recalcular calcula y guarda el puntaje del cliente potencial
+func (ps *PuntajeClientesService) Recalcular(ctx context.Context, id uint) error {
+	cliente, err := ps.clientes.ObtenerPorID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ps.recalcular(ctx, cliente, time.Now())
+}
+
+// RecalcularActivos recalcula el puntaje de los clientes que no están
+// convertidos ni descartados y retorna cuántos recalculó
+func (ps *PuntajeClientesService) RecalcularActivos(ctx context.Context) (int, error) {
+	ahora := time.Now()
+	recalculados := 0
+	var ultimoID uint
+	for {
+		clientes, err := ps.clientes.ListarActivos(ctx, ultimoID, TamanoLotePuntajes)
+		if err != nil {
+			return recalculados, err
+		}
+		for i := range clientes {
+			if err := ps.recalcular(ctx, &clientes[i], ahora); err != nil {
+				return recalculados, err
+			}
+			recalculados++
+		}
+		if len(clientes) < TamanoLotePuntajes {
+			return recalculados, nil
+		}
+		ultimoID = clientes[len(clientes)-1].ID
+	}
+}
+
+// recalcular calcula y guarda el puntaje del cliente a la fecha indicada
+func (ps *PuntajeClientesService) recalcular(ctx context.Context, cliente *entities.ClientePotencial, ahora time.Time) error {
+	interacciones, err := ps.clientes.ListarInteracciones(ctx, cliente.ID)
+	if err != nil {
+		return err
+	}
+	puntaje, factores := ps.modelo.Calcular(cliente, interacciones, ahora)
+	return ps.clientes.GuardarPuntaje(ctx, cliente.ID, puntaje, factores, ahora)
+}
+
+// Iniciar recalcula los puntajes de los clientes activos cada intervalo hasta
+// que se cancele el contexto
+func (ps *PuntajeClientesService) Iniciar(ctx context.Context) error {
+	log.Printf("Recalculando los puntajes de los clientes potenciales cada %v", ps.intervalo)
+
+	ticker := time.NewTicker(ps.intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := ps.RecalcularActivos(ctx); err != nil {
+				log.Printf("Error recalculando los puntajes de los clientes potenciales: %v", err)
+			}
+		}
+	}
+}
+
+// EventosPuntaje son los eventos después de los cuales se recalcula el puntaje
+// del cliente potencial al que se refieren
+var EventosPuntaje = []string{
+	events.EventClientePotencialCreado,
+	events.EventClientePotencialActualizado,
+	events.EventClientePotencialEstado,
+	events.EventClientePotencialInteraccion,
+}
+
+// Manejador retorna el manejador que recalcula el puntaje ante el tipo de evento indicado
+func (ps *PuntajeClientesService) Manejador(tipo string) events.EventHandler {
+	return &manejadorPuntaje{servicio: ps, tipo: tipo}
+}
+
+// manejadorPuntaje recalcula el puntaje del cliente potencial al que se refiere un evento
+type manejadorPuntaje struct {
+	servicio *PuntajeClientesService
+	tipo     string
+}
+
+// Handle recalcula el puntaje del cliente del evento. Un cliente que ya no
+// existe no tiene puntaje que recalcular.
+func (m *manejadorPuntaje) Handle(event events.Event) error {
+	id, ok := idEvento(event.Data["cliente_potencial_id"])
+	if !ok {
+		return fmt.Errorf("evento %s sin cliente_potencial_id", event.Type)
+	}
+	err := m.servicio.Recalcular(context.Background(), id)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return nil
+	}
+	return err
+}
+
+func (m *manejadorPuntaje) GetEventType() string {
+	return m.tipo
+}
+
+// idEvento convierte un ID de los datos de un evento, que al pasar por el
+// outbox llega como número JSON
+func idEvento(valor interface{}) (uint, bool) {
+	switch id := valor.(type) {
+	case uint:
+		return id, id > 0
+	case int:
+		return uint(id), id > 0
+	case float64:
+		return uint(id), id > 0 && id == math.Trunc(id)
+	case json.Number:
+		entero, err := id.Int64()
+		return uint(entero), err == nil && entero > 0
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/builders"
+)
+
+// ReporteService genera reportes a partir de los datos persistidos
+type ReporteService struct {
+	ventas    repositories.VentaRepository
+	productos repositories.ProductoRepository
+	precios   repositories.PrecioHistoricoRepository
+}
+
+// NewReporteService crea un servicio de reportes sobre los repositorios indicados
+func NewReporteService(
+	ventas repositories.VentaRepository,
+	productos repositories.ProductoRepository,
+	precios repositories.PrecioHistoricoRepository,
+) *ReporteService {
+	return &ReporteService{
+		ventas:    ventas,
+		productos: productos,
+		precios:   precios,
+	}
+}
+
+// ResumenProductoReporte resume las ventas de un producto en un reporte
+type ResumenProductoReporte struct {
+	ProductoID       uint    `json:"producto_id"`
+	SKU              string  `json:"sku"`
+	Nombre           string  `json:"nombre"`
+	Cantidad         int     `json:"cantidad"`
+	Total            float64 `json:"total"`
+	TotalPrecioLista float64 `json:"total_precio_lista"` // a precio de lista vigente en cada venta
+}
+
+// VentasPorSucursal genera el reporte de ventas completadas de la sucursal en el
+// período [desde, hasta). Cada línea se compara con el precio de lista vigente a
+// la fecha de su venta, no con el precio actual del producto. Las ventas
+// devueltas parcialmente cuentan sólo por las unidades no devueltas.
+func (rs *ReporteService) VentasPorSucursal(ctx context.Context, sucursalID uint, desde, hasta time.Time) (*builders.Reporte, error) {
+	ventas, err := rs.ventas.ListarPorSucursal(ctx, sucursalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalVentas, totalLineas, totalPrecioLista float64
+	transacciones := 0
+	porProducto := make(map[uint]*ResumenProductoReporte)
+
+	for _, venta := range ventas {
+		if !cuentaEnReporte(venta.Estado) || venta.FechaVenta.Before(desde) || !venta.FechaVenta.Before(hasta) {
+			continue
+		}
+		transacciones++
+		totalVentas += venta.TotalNeto()
+
+		for _, detalle := range venta.DetallesVenta {
+			resumen, err := rs.resumenProducto(ctx, porProducto, detalle.ProductoID)
+			if err != nil {
+				return nil, err
+			}
+			precioLista, err := rs.precioLista(ctx, detalle, venta.FechaVenta)
+			if err != nil {
+				return nil, err
+			}
+
+			cantidad := detalle.CantidadPendiente()
+			resumen.Cantidad += cantidad
+			resumen.Total += detalle.TotalNeto()
+			resumen.TotalPrecioLista += precioLista * float64(cantidad)
+			totalLineas += detalle.TotalNeto()
+			totalPrecioLista += precioLista * float64(cantidad)
+		}
+	}
+
+	productos := make([]ResumenProductoReporte, 0, len(porProducto))
+	for _, resumen := range porProducto {
+		productos = append(productos, *resumen)
+	}
+	sort.Slice(productos, func(i, j int) bool {
+		if productos[i].Cantidad != productos[j].Cantidad {
+			return productos[i].Cantidad > productos[j].Cantidad
+		}
+		return productos[i].SKU < productos[j].SKU
+	})
+
+	builder := builders.NewReporteBuilder().
+		BuildVentasPorSucursal(sucursalID, desde, hasta).
+		SetFormato("json").
+		AddDato("total_ventas", totalVentas).
+		AddDato("cantidad_transacciones", transacciones).
+		AddDato("total_precio_lista", totalPrecioLista).
+		AddDato("descuento_sobre_lista", totalPrecioLista-totalLineas).
+		AddDato("productos", productos).
+		SetEstado("completado")
+	if len(productos) > 0 {
+		builder.AddDato("producto_mas_vendido", productos[0].Nombre)
+	}
+	return builder.Build()
+}
+
+// cuentaEnReporte indica si las ventas en el estado indicado suman al reporte
+func cuentaEnReporte(estado string) bool {
+	return estado == entities.EstadoVentaCompletada || estado == entities.EstadoVentaDevueltaParcial
+}
+
+// resumenProducto retorna el resumen del producto, creándolo con sus datos la primera vez
+func (rs *ReporteService) resumenProducto(ctx context.Context, porProducto map[uint]*ResumenProductoReporte, productoID uint) (*ResumenProductoReporte, error) {
+	if resumen, existe := porProducto[productoID]; existe {
+		return resumen, nil
+	}
+
+	resumen := &ResumenProductoReporte{ProductoID: productoID}
+	producto, err := rs.productos.ObtenerPorID(ctx, productoID)
+	switch {
+	case err == nil:
+		resumen.SKU = producto.SKU
+		resumen.Nombre = producto.Nombre
+	case !errors.Is(err, repositories.ErrNoEncontrado):
+		return nil, err
+	}
+
+	porProducto[productoID] = resumen
+	return resumen, nil
+}
+
+// precioLista retorna el precio final del producto vigente a la fecha de la
+// venta; sin historial para esa fecha se usa el precio unitario de la línea
+func (rs *ReporteService) precioLista(ctx context.Context, detalle entities.DetalleVenta, fecha time.Time) (float64, error) {
+	if rs.precios == nil {
+		return detalle.PrecioUnitario, nil
+	}
+
+	precio, err := rs.precios.ObtenerVigente(ctx, detalle.ProductoID, fecha)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		return detalle.PrecioUnitario, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("precio del producto %d al %s: %w", detalle.ProductoID, fecha.Format("2006-01-02"), err)
+	}
+	return precio.CalcularPrecioFinal(), nil
+}
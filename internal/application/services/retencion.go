@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+)
+
+// Clases de datos a las que se puede asignar una política de retención
+const (
+	ClaseLotesCrudos = "lotes_crudos"
+	ClaseEventos     = "eventos"
+	ClaseClientes    = "clientes_potenciales"
+)
+
+// clasesRetencion son las clases de datos admitidas en las políticas: solo las
+// que el sistema guarda y sabe purgar
+var clasesRetencion = []string{ClaseLotesCrudos, ClaseEventos, ClaseClientes}
+
+// TamanoLotePurgaPorDefecto es la cantidad de registros que se purgan por
+// tanda cuando no se configura otra
+const TamanoLotePurgaPorDefecto = 1000
+
+// PoliticaRetencion indica durante cuánto tiempo se conservan los datos de una clase
+type PoliticaRetencion struct {
+	Clase     string
+	Retencion time.Duration
+}
+
+// EstadoPolitica describe una política de retención configurada. Una política
+// inactiva corresponde a una clase sin datos registrados para purgar.
+type EstadoPolitica struct {
+	Clase     string `json:"clase" example:"lotes_crudos"`
+	Retencion string `json:"retencion" example:"90d"`
+	Accion    string `json:"accion,omitempty" example:"eliminar"`
+	Activa    bool   `json:"activa" example:"true"`
+}
+
+// ParsearPoliticasRetencion interpreta una lista "clase:retencion" separada por
+// comas, con la retención en días ("90d") o como duración de Go ("36h")
+func ParsearPoliticasRetencion(texto string) ([]PoliticaRetencion, error) {
+	var politicas []PoliticaRetencion
+	vistas := make(map[string]bool)
+
+	for _, definicion := range strings.Split(texto, ",") {
+		definicion = strings.TrimSpace(definicion)
+		if definicion == "" {
+			continue
+		}
+		clase, valor, ok := strings.Cut(definicion, ":")
+		clase, valor = strings.TrimSpace(clase), strings.TrimSpace(valor)
+		if !ok || clase == "" || valor == "" {
+			return nil, fmt.Errorf("política %q: se espera clase:retencion", definicion)
+		}
+		if !esClaseRetencion(clase) {
+			return nil, fmt.Errorf("política %q: clase desconocida (se admite %s)", definicion, strings.Join(clasesRetencion, ", "))
+		}
+		if vistas[clase] {
+			return nil, fmt.Errorf("política %q: la clase %s ya tiene política", definicion, clase)
+		}
+		retencion, err := parsearRetencion(valor)
+		if err != nil {
+			return nil, fmt.Errorf("política %q: %v", definicion, err)
+		}
+
+		vistas[clase] = true
+		politicas = append(politicas, PoliticaRetencion{Clase: clase, Retencion: retencion})
+	}
+	return politicas, nil
+}
+
+// esClaseRetencion indica si la clase admite una política de retención
+func esClaseRetencion(clase string) bool {
+	for _, admitida := range clasesRetencion {
+		if clase == admitida {
+			return true
+		}
+	}
+	return false
+}
+
+// parsearRetencion interpreta una retención en días ("90d") o como duración de Go
+func parsearRetencion(valor string) (time.Duration, error) {
+	var retencion time.Duration
+	if dias, ok := strings.CutSuffix(valor, "d"); ok {
+		n, err := strconv.Atoi(dias)
+		if err != nil {
+			return 0, fmt.Errorf("retención inválida: %s", valor)
+		}
+		retencion = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if retencion, err = time.ParseDuration(valor); err != nil {
+			return 0, fmt.Errorf("retención inválida: %s", valor)
+		}
+	}
+	if retencion <= 0 {
+		return 0, fmt.Errorf("la retención debe ser positiva: %s", valor)
+	}
+	return retencion, nil
+}
+
+// formatearRetencion muestra la retención en días cuando es una cantidad entera de días
+func formatearRetencion(retencion time.Duration) string {
+	if retencion%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", retencion/(24*time.Hour))
+	}
+	return retencion.String()
+}
+
+// PurgaFunc elimina o anonimiza hasta limite registros de una clase anteriores
+// a la fecha y retorna a cuántos alcanzó
+type PurgaFunc func(ctx context.Context, antesDe time.Time, limite int) (int, error)
+
+// purgaClase es la forma de purgar los datos de una clase
+type purgaClase struct {
+	accion string
+	purgar PurgaFunc
+}
+
+// RetencionService aplica periódicamente las políticas de retención: purga en
+// tandas los datos vencidos de cada clase y registra cada purga en la auditoría
+type RetencionService struct {
+	politicas  []PoliticaRetencion
+	clases     map[string]purgaClase
+	auditoria  repositories.PurgaRepository
+	eventBus   *events.EventBus
+	intervalo  time.Duration
+	tamanoLote int
+
+	// una sola purga a la vez
+	mutex sync.Mutex
+}
+
+// NewRetencionService crea un servicio que aplica las políticas cada intervalo
+func NewRetencionService(politicas []PoliticaRetencion, auditoria repositories.PurgaRepository, eventBus *events.EventBus, intervalo time.Duration) *RetencionService {
+	return &RetencionService{
+		politicas:  politicas,
+		clases:     make(map[string]purgaClase),
+		auditoria:  auditoria,
+		eventBus:   eventBus,
+		intervalo:  intervalo,
+		tamanoLote: TamanoLotePurgaPorDefecto,
+	}
+}
+
+// SetTamanoLote define la cantidad de registros que se purgan por tanda
+func (rs *RetencionService) SetTamanoLote(tamano int) {
+	if tamano < 1 {
+		tamano = 1
+	}
+	rs.tamanoLote = tamano
+}
+
+// RegistrarClase indica cómo purgar los datos de una clase y si se eliminan o anonimizan
+func (rs *RetencionService) RegistrarClase(clase, accion string, purgar PurgaFunc) {
+	rs.clases[clase] = purgaClase{accion: accion, purgar: purgar}
+}
+
+// Politicas retorna las políticas configuradas y si se aplican
+func (rs *RetencionService) Politicas() []EstadoPolitica {
+	estados := make([]EstadoPolitica, 0, len(rs.politicas))
+	for _, politica := range rs.politicas {
+		clase, activa := rs.clases[politica.Clase]
+		estados = append(estados, EstadoPolitica{
+			Clase:     politica.Clase,
+			Retencion: formatearRetencion(politica.Retencion),
+			Accion:    clase.accion,
+			Activa:    activa,
+		})
+	}
+	return estados
+}
+
+// ListarPurgas retorna los últimos registros de la auditoría de purgas
+func (rs *RetencionService) ListarPurgas(ctx context.Context, clase string, limite int) ([]entities.RegistroPurga, error) {
+	return rs.auditoria.Listar(ctx, clase, limite)
+}
+
+// Iniciar aplica las políticas periódicamente hasta que se cancele el contexto
+func (rs *RetencionService) Iniciar(ctx context.Context) error {
+	for _, estado := range rs.Politicas() {
+		if !estado.Activa {
+			log.Printf("⚠️ La política de retención de %s no se aplica: no hay datos de esa clase para purgar", estado.Clase)
+		}
+	}
+	log.Printf("Aplicando políticas de retención cada %v", rs.intervalo)
+
+	ticker := time.NewTicker(rs.intervalo)
+	defer ticker.Stop()
+
+	for {
+		if _, err := rs.Purgar(ctx); err != nil {
+			log.Printf("Error aplicando políticas de retención: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purgar aplica cada política activa sobre los datos anteriores a su corte y
+// retorna los registros de auditoría de las clases en que hubo algo que purgar
+func (rs *RetencionService) Purgar(ctx context.Context) ([]entities.RegistroPurga, error) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	var registros []entities.RegistroPurga
+	var errs []error
+	ahora := time.Now()
+
+	for _, politica := range rs.politicas {
+		clase, activa := rs.clases[politica.Clase]
+		if !activa {
+			continue
+		}
+
+		registro := rs.purgarClase(ctx, politica.Clase, clase, ahora.Add(-politica.Retencion))
+		if registro.Registros == 0 && registro.Error == "" {
+			continue
+		}
+		if registro.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", registro.Clase, registro.Error))
+		}
+		if err := rs.auditoria.Registrar(ctx, &registro); err != nil {
+			errs = append(errs, fmt.Errorf("error registrando la purga de %s: %v", registro.Clase, err))
+		}
+
+		log.Printf("🧹 Purga de %s (%s antes de %s): %d registros en %d tandas",
+			registro.Clase, registro.Accion, registro.AntesDe.Format(time.RFC3339), registro.Registros, registro.Lotes)
+		rs.eventBus.Publish(events.CreateEvent(
+			events.EventDatosPurgados,
+			map[string]interface{}{
+				"clase":     registro.Clase,
+				"accion":    registro.Accion,
+				"antes_de":  registro.AntesDe,
+				"registros": registro.Registros,
+				"error":     registro.Error,
+			},
+			"retencion",
+		))
+		registros = append(registros, registro)
+	}
+	return registros, errors.Join(errs...)
+}
+
+// purgarClase purga en tandas los datos de la clase anteriores a la fecha,
+// hasta que una tanda no se completa o falla
+func (rs *RetencionService) purgarClase(ctx context.Context, nombre string, clase purgaClase, antesDe time.Time) entities.RegistroPurga {
+	registro := entities.RegistroPurga{
+		Clase:      nombre,
+		Accion:     clase.accion,
+		AntesDe:    antesDe,
+		IniciadaEn: time.Now(),
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			registro.Error = err.Error()
+			break
+		}
+		purgados, err := clase.purgar(ctx, antesDe, rs.tamanoLote)
+		registro.Registros += purgados
+		if purgados > 0 {
+			registro.Lotes++
+		}
+		if err != nil {
+			registro.Error = err.Error()
+			break
+		}
+		if purgados < rs.tamanoLote {
+			break
+		}
+	}
+
+	registro.FinalizadaEn = time.Now()
+	return registro
+}
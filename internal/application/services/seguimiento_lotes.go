@@ -0,0 +1,115 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Estados posibles de un lote encolado
+const (
+	EstadoLotePendiente  = "pendiente"
+	EstadoLoteProcesando = "procesando"
+	EstadoLoteCompletado = "completado"
+	EstadoLoteFallido    = "fallido"
+)
+
+// EstadoLote describe el avance de un lote procesado en segundo plano
+type EstadoLote struct {
+	LoteID        string         `json:"lote_id"`
+	Origen        string         `json:"origen"`
+	Tipo          string         `json:"tipo"`
+	SucursalID    uint           `json:"sucursal_id"`
+	Estado        string         `json:"estado"`
+	Resultado     *ResultadoLote `json:"resultado,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	CreadoEn      time.Time      `json:"creado_en"`
+	ActualizadoEn time.Time      `json:"actualizado_en"`
+}
+
+// RetencionLotesTerminados es el tiempo que se conserva el estado de un lote
+// completado o fallido antes de descartarlo
+const RetencionLotesTerminados = 24 * time.Hour
+
+// intervaloPurgaLotes es el tiempo mínimo entre dos revisiones de lotes vencidos
+const intervaloPurgaLotes = time.Minute
+
+// SeguimientoLotes mantiene en memoria el estado de los lotes encolados. Los
+// lotes terminados se descartan pasada RetencionLotesTerminados, para que la
+// memoria no crezca mientras el servidor esté en marcha.
+type SeguimientoLotes struct {
+	lotes       map[string]*EstadoLote
+	ultimaPurga time.Time
+	mutex       sync.RWMutex
+}
+
+// NewSeguimientoLotes crea un registro de seguimiento vacío
+func NewSeguimientoLotes() *SeguimientoLotes {
+	return &SeguimientoLotes{
+		lotes: make(map[string]*EstadoLote),
+	}
+}
+
+// Registrar agrega un lote en estado pendiente y descarta los terminados vencidos
+func (sl *SeguimientoLotes) Registrar(datosCrudos *DatosCrudos) {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	ahora := time.Now()
+	sl.purgarVencidos(ahora)
+	sl.lotes[datosCrudos.LoteID] = &EstadoLote{
+		LoteID:        datosCrudos.LoteID,
+		Origen:        datosCrudos.Origen,
+		Tipo:          datosCrudos.Tipo,
+		SucursalID:    datosCrudos.SucursalID,
+		Estado:        EstadoLotePendiente,
+		CreadoEn:      ahora,
+		ActualizadoEn: ahora,
+	}
+}
+
+// purgarVencidos descarta los lotes completados o fallidos que terminaron hace
+// más de RetencionLotesTerminados, revisándolos a lo sumo una vez por intervaloPurgaLotes
+func (sl *SeguimientoLotes) purgarVencidos(ahora time.Time) {
+	if ahora.Sub(sl.ultimaPurga) < intervaloPurgaLotes {
+		return
+	}
+	sl.ultimaPurga = ahora
+
+	corte := ahora.Add(-RetencionLotesTerminados)
+	for loteID, lote := range sl.lotes {
+		terminado := lote.Estado == EstadoLoteCompletado || lote.Estado == EstadoLoteFallido
+		if terminado && lote.ActualizadoEn.Before(corte) {
+			delete(sl.lotes, loteID)
+		}
+	}
+}
+
+// Actualizar cambia el estado de un lote y registra su resultado o error
+func (sl *SeguimientoLotes) Actualizar(loteID, estado string, resultado *ResultadoLote, err error) {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+
+	lote, existe := sl.lotes[loteID]
+	if !existe {
+		return
+	}
+
+	lote.Estado = estado
+	lote.Resultado = resultado
+	if err != nil {
+		lote.Error = err.Error()
+	}
+	lote.ActualizadoEn = time.Now()
+}
+
+// Obtener retorna una copia del estado de un lote
+func (sl *SeguimientoLotes) Obtener(loteID string) (EstadoLote, bool) {
+	sl.mutex.RLock()
+	defer sl.mutex.RUnlock()
+
+	lote, existe := sl.lotes[loteID]
+	if !existe {
+		return EstadoLote{}, false
+	}
+	return *lote, true
+}
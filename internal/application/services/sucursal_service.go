@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ErrDatosInvalidos indica que los datos recibidos no superan la validación de dominio
+var ErrDatosInvalidos = errors.New("datos inválidos")
+
+// SucursalService implementa la gestión de sucursales
+type SucursalService struct {
+	repo repositories.SucursalRepository
+}
+
+// NewSucursalService crea una nueva instancia del servicio
+func NewSucursalService(repo repositories.SucursalRepository) *SucursalService {
+	return &SucursalService{
+		repo: repo,
+	}
+}
+
+// Guardar valida la sucursal y su configuración antes de persistirla, de modo
+// que los filtros inválidos se reporten al guardar y no al procesar un lote
+func (ss *SucursalService) Guardar(ctx context.Context, sucursal *entities.Sucursal) error {
+	if !sucursal.EsValida() {
+		return fmt.Errorf("%w: nombre y tipo de sistema son requeridos", ErrDatosInvalidos)
+	}
+
+	if err := sucursal.ValidarConfiguracion(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatosInvalidos, err)
+	}
+
+	return ss.repo.Guardar(ctx, sucursal)
+}
+
+// ObtenerPorID retorna la sucursal con el ID indicado
+func (ss *SucursalService) ObtenerPorID(ctx context.Context, id uint) (*entities.Sucursal, error) {
+	return ss.repo.ObtenerPorID(ctx, id)
+}
+
+// Listar retorna todas las sucursales
+func (ss *SucursalService) Listar(ctx context.Context) ([]entities.Sucursal, error) {
+	return ss.repo.Listar(ctx)
+}
+
+// Actualizar reemplaza los datos de una sucursal existente. Las credenciales son
+// de solo escritura: si no se informan se conservan las guardadas.
+func (ss *SucursalService) Actualizar(ctx context.Context, sucursal *entities.Sucursal) error {
+	existente, err := ss.repo.ObtenerPorID(ctx, sucursal.ID)
+	if err != nil {
+		return err
+	}
+
+	if sucursal.APIKey == "" {
+		sucursal.APIKey = existente.APIKey
+	}
+	if sucursal.APISecret == "" {
+		sucursal.APISecret = existente.APISecret
+	}
+	sucursal.UltimaSincronizacion = existente.UltimaSincronizacion
+	return ss.Guardar(ctx, sucursal)
+}
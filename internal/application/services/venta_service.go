@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+)
+
+// DevolucionDetalle indica cuántas unidades de un detalle de la venta se devuelven
+type DevolucionDetalle struct {
+	DetalleID uint
+	Cantidad  int
+}
+
+// SolicitudTransicionVenta es el pedido de cambio de estado de una venta. Las
+// devoluciones indican las unidades devueltas al pasar a devuelta_parcial; al
+// pasar a devuelta sin indicarlas se devuelve todo lo pendiente.
+type SolicitudTransicionVenta struct {
+	Estado       string
+	Usuario      string
+	Motivo       string
+	Devoluciones []DevolucionDetalle
+}
+
+// ResultadoTransicionVenta es la venta después de cambiar de estado, con la
+// transición registrada y los movimientos de stock que generó
+type ResultadoTransicionVenta struct {
+	Venta       *entities.Venta
+	Transicion  *entities.TransicionVenta
+	Movimientos []*entities.MovimientoStock
+}
+
+// VentaService implementa la consulta de ventas y sus cambios de estado
+type VentaService struct {
+	emisorEventos
+	repo        repositories.VentaRepository
+	movimientos repositories.MovimientoStockRepository
+}
+
+// NewVentaService crea una nueva instancia del servicio
+func NewVentaService(
+	eventBus *events.EventBus,
+	repo repositories.VentaRepository,
+	movimientos repositories.MovimientoStockRepository,
+) *VentaService {
+	return &VentaService{
+		emisorEventos: emisorEventos{eventBus: eventBus, origen: "ventas"},
+		repo:          repo,
+		movimientos:   movimientos,
+	}
+}
+
+// ObtenerPorID retorna la venta con el ID indicado junto con sus detalles
+func (vs *VentaService) ObtenerPorID(ctx context.Context, id uint) (*entities.Venta, error) {
+	return vs.repo.ObtenerPorID(ctx, id)
+}
+
+// Transicionar pasa la venta al estado pedido en nombre del usuario, lo
+// registra en su historial y emite EventVentaEstado en la misma transacción.
+// Completar una venta pendiente egresa su mercadería del stock de la sucursal.
+// Anular una venta completada y devolver unidades la reingresan, con un
+// movimiento de devolución por detalle, pero sólo hasta las unidades que el
+// registro de movimientos muestra egresadas por la venta. Una devolución
+// parcial que deja todo devuelto pasa a devuelta.
+func (vs *VentaService) Transicionar(ctx context.Context, id uint, solicitud SolicitudTransicionVenta) (*ResultadoTransicionVenta, error) {
+	estado := strings.ToLower(strings.TrimSpace(solicitud.Estado))
+	usuario := strings.TrimSpace(solicitud.Usuario)
+	if usuario == "" {
+		return nil, fmt.Errorf("%w: el usuario es requerido", ErrDatosInvalidos)
+	}
+	if !entities.EsEstadoVenta(estado) {
+		return nil, fmt.Errorf("%w: estado desconocido %q", ErrDatosInvalidos, estado)
+	}
+	if estado == entities.EstadoVentaDevueltaParcial && len(solicitud.Devoluciones) == 0 {
+		return nil, fmt.Errorf("%w: una devolución parcial requiere los detalles devueltos", ErrDatosInvalidos)
+	}
+	if len(solicitud.Devoluciones) > 0 && estado != entities.EstadoVentaDevueltaParcial && estado != entities.EstadoVentaDevuelta {
+		return nil, fmt.Errorf("%w: sólo se indican devoluciones al pasar a %s o %s", ErrDatosInvalidos, entities.EstadoVentaDevueltaParcial, entities.EstadoVentaDevuelta)
+	}
+
+	var resultado *ResultadoTransicionVenta
+	err := vs.enTransaccion(ctx, func(ctx context.Context) error {
+		venta, err := vs.repo.ObtenerPorID(ctx, id)
+		if err != nil {
+			return err
+		}
+		desde := venta.Estado
+		if !venta.PuedePasarA(estado) {
+			return fmt.Errorf("%w: %v", ErrTransicionInvalida, venta.ActualizarEstado(estado))
+		}
+
+		reingresos, err := vs.reingresos(venta, estado, solicitud.Devoluciones)
+		if err != nil {
+			return err
+		}
+		if estado == entities.EstadoVentaDevueltaParcial && venta.TodoDevuelto() {
+			estado = entities.EstadoVentaDevuelta
+		}
+		if err := venta.ActualizarEstado(estado); err != nil {
+			return fmt.Errorf("%w: %v", ErrTransicionInvalida, err)
+		}
+
+		transicion := &entities.TransicionVenta{
+			VentaID: venta.ID,
+			Desde:   desde,
+			Hasta:   venta.Estado,
+			Usuario: usuario,
+			Motivo:  strings.TrimSpace(solicitud.Motivo),
+			Fecha:   time.Now(),
+		}
+		if err := vs.repo.CambiarEstado(ctx, venta, desde); err != nil {
+			return err
+		}
+		if err := vs.repo.RegistrarTransicion(ctx, transicion); err != nil {
+			return err
+		}
+		resultado = &ResultadoTransicionVenta{Venta: venta, Transicion: transicion}
+
+		movimientos, err := vs.movimientosTransicion(ctx, venta, desde, reingresos)
+		if err != nil {
+			return err
+		}
+		if resultado.Movimientos, err = vs.registrarMovimientos(ctx, venta, movimientos); err != nil {
+			return err
+		}
+		return vs.emitir(ctx, events.EventVentaEstado, map[string]interface{}{
+			"venta_id":    venta.ID,
+			"sucursal_id": venta.SucursalID,
+			"desde":       transicion.Desde,
+			"hasta":       transicion.Hasta,
+			"usuario":     transicion.Usuario,
+			"motivo":      transicion.Motivo,
+			"fecha":       transicion.Fecha,
+			"total":       venta.Total,
+			"total_neto":  venta.TotalNeto(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// reingresos aplica a la venta las devoluciones pedidas y retorna las unidades
+// de cada detalle que vuelven al stock
+func (vs *VentaService) reingresos(venta *entities.Venta, estado string, devoluciones []DevolucionDetalle) (map[uint]int, error) {
+	reingresos := make(map[uint]int)
+	switch {
+	case estado == entities.EstadoVentaAnulada && venta.Estado == entities.EstadoVentaCompletada:
+		// La mercadería de una venta pendiente no se entregó: no hay nada que reingresar
+		for _, detalle := range venta.DetallesVenta {
+			if detalle.CantidadPendiente() > 0 {
+				reingresos[detalle.ID] = detalle.CantidadPendiente()
+			}
+		}
+
+	case len(devoluciones) > 0:
+		for _, devolucion := range devoluciones {
+			if err := venta.Devolver(devolucion.DetalleID, devolucion.Cantidad); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrDatosInvalidos, err)
+			}
+			reingresos[devolucion.DetalleID] += devolucion.Cantidad
+		}
+		if estado == entities.EstadoVentaDevuelta && !venta.TodoDevuelto() {
+			return nil, fmt.Errorf("%w: las devoluciones no cubren todas las unidades de la venta", ErrDatosInvalidos)
+		}
+
+	case estado == entities.EstadoVentaDevuelta:
+		for _, detalle := range venta.DetallesVenta {
+			if pendiente := detalle.CantidadPendiente(); pendiente > 0 {
+				if err := venta.Devolver(detalle.ID, pendiente); err != nil {
+					return nil, err
+				}
+				reingresos[detalle.ID] = pendiente
+			}
+		}
+	}
+	return reingresos, nil
+}
+
+// movimientosTransicion retorna los movimientos de stock que genera la
+// transición: los egresos al completar una venta pendiente o los reingresos
+// pedidos, limitados a las unidades de cada producto que egresaron por la venta
+// y todavía no se reingresaron
+func (vs *VentaService) movimientosTransicion(ctx context.Context, venta *entities.Venta, desde string, reingresos map[uint]int) ([]*entities.MovimientoStock, error) {
+	if desde == entities.EstadoVentaPendiente && venta.Estado == entities.EstadoVentaCompletada {
+		return egresosVenta(venta)
+	}
+	if len(reingresos) == 0 {
+		return nil, nil
+	}
+
+	registrados, err := vs.movimientos.ListarPorReferencia(ctx, venta.ReferenciaStock())
+	if err != nil {
+		return nil, err
+	}
+	egresados := make(map[uint]int)
+	for _, movimiento := range registrados {
+		if movimiento.SucursalID == venta.SucursalID {
+			// Las ventas restan y las devoluciones suman: el saldo negado es lo que falta reingresar
+			egresados[movimiento.ProductoID] -= movimiento.Cantidad
+		}
+	}
+
+	var movimientos []*entities.MovimientoStock
+	for _, detalle := range venta.DetallesVenta {
+		cantidad := reingresos[detalle.ID]
+		if cantidad > egresados[detalle.ProductoID] {
+			cantidad = egresados[detalle.ProductoID]
+		}
+		if cantidad <= 0 {
+			continue
+		}
+		egresados[detalle.ProductoID] -= cantidad
+
+		movimiento, err := entities.NewMovimientoStock(entities.MovimientoDevolucion, detalle.ProductoID, venta.SucursalID, cantidad)
+		if err != nil {
+			return nil, err
+		}
+		movimiento.Referencia = venta.ReferenciaStock()
+		movimientos = append(movimientos, movimiento)
+	}
+	return movimientos, nil
+}
+
+// registrarMovimientos guarda los movimientos de stock de la venta y emite
+// EventStockActualizado por cada uno
+func (vs *VentaService) registrarMovimientos(ctx context.Context, venta *entities.Venta, movimientos []*entities.MovimientoStock) ([]*entities.MovimientoStock, error) {
+	if len(movimientos) == 0 {
+		return nil, nil
+	}
+	if err := vs.movimientos.Registrar(ctx, movimientos...); err != nil {
+		return nil, err
+	}
+
+	for _, movimiento := range movimientos {
+		err := vs.emitir(ctx, events.EventStockActualizado, map[string]interface{}{
+			"movimiento_id":    movimiento.ID,
+			"producto_id":      movimiento.ProductoID,
+			"sucursal_id":      movimiento.SucursalID,
+			"tipo":             movimiento.Tipo,
+			"cantidad":         movimiento.Cantidad,
+			"stock_resultante": movimiento.StockResultante,
+			"venta_id":         venta.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return movimientos, nil
+}
+
+// ListarTransiciones retorna el historial de estados de la venta
+func (vs *VentaService) ListarTransiciones(ctx context.Context, id uint) ([]entities.TransicionVenta, error) {
+	if _, err := vs.repo.ObtenerPorID(ctx, id); err != nil {
+		return nil, err
+	}
+	transiciones, err := vs.repo.ListarTransiciones(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transiciones == nil {
+		transiciones = []entities.TransicionVenta{}
+	}
+	return transiciones, nil
+}
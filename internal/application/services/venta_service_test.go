@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/events"
+	"sistema-gestion-informacion/internal/infrastructure/persistence"
+)
+
+// escenarioVenta reúne el servicio de ventas y los repositorios en memoria de
+// una prueba
+type escenarioVenta struct {
+	servicio    *VentaService
+	ventas      *persistence.VentaRepositoryMemoria
+	productos   *persistence.ProductoRepositoryMemoria
+	movimientos *persistence.MovimientoStockRepositoryMemoria
+	venta       *entities.Venta
+}
+
+// nuevoEscenarioVenta guarda los productos 1 y 2 con 10 unidades en la sucursal
+// 1 y una venta en el estado indicado con 3 unidades del primero (detalle 1) y
+// 2 del segundo (detalle 2). egresados son las unidades de cada producto que el
+// registro de movimientos muestra egresadas por la venta.
+func nuevoEscenarioVenta(t *testing.T, estado string, egresados map[uint]int) *escenarioVenta {
+	t.Helper()
+	ctx := context.Background()
+
+	e := &escenarioVenta{
+		ventas:    persistence.NewVentaRepositoryMemoria(persistence.NewDetalleVentaRepositoryMemoria()),
+		productos: persistence.NewProductoRepositoryMemoria(nil),
+	}
+	e.movimientos = persistence.NewMovimientoStockRepositoryMemoria(e.productos)
+	e.servicio = NewVentaService(events.NewEventBus(), e.ventas, e.movimientos)
+	e.servicio.SetOutbox(persistence.NewOutboxRepositoryMemoria(), nil, nil)
+
+	for _, sku := range []string{"A1", "B2"} {
+		producto := &entities.Producto{SKU: sku, Nombre: sku, Precio: 100, Estado: "activo"}
+		if err := e.productos.Guardar(ctx, producto); err != nil {
+			t.Fatalf("Guardar producto: %v", err)
+		}
+		recepcion, _ := entities.NewMovimientoStock(entities.MovimientoRecepcion, producto.ID, 1, 10)
+		if err := e.movimientos.Registrar(ctx, recepcion); err != nil {
+			t.Fatalf("Registrar recepción: %v", err)
+		}
+	}
+
+	e.venta = &entities.Venta{SucursalID: 1, FechaVenta: time.Now(), Estado: estado}
+	for i, cantidad := range []int{3, 2} {
+		detalle := entities.DetalleVenta{ProductoID: uint(i + 1), Cantidad: cantidad, PrecioUnitario: 100}
+		detalle.CalcularTotal()
+		e.venta.DetallesVenta = append(e.venta.DetallesVenta, detalle)
+	}
+	if err := e.ventas.Guardar(ctx, e.venta); err != nil {
+		t.Fatalf("Guardar venta: %v", err)
+	}
+
+	for productoID, cantidad := range egresados {
+		egreso, _ := entities.NewMovimientoStock(entities.MovimientoVenta, productoID, 1, cantidad)
+		egreso.Referencia = e.venta.ReferenciaStock()
+		if err := e.movimientos.Registrar(ctx, egreso); err != nil {
+			t.Fatalf("Registrar egreso: %v", err)
+		}
+	}
+	return e
+}
+
+// stock retorna el stock actual de cada producto del escenario
+func (e *escenarioVenta) stock(t *testing.T) map[uint]int {
+	t.Helper()
+	stock := make(map[uint]int)
+	for _, id := range []uint{1, 2} {
+		producto, err := e.productos.ObtenerPorID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("ObtenerPorID(%d): %v", id, err)
+		}
+		stock[id] = producto.StockActual
+	}
+	return stock
+}
+
+// cantidadesPorProducto suma las cantidades de los movimientos por producto
+func cantidadesPorProducto(movimientos []*entities.MovimientoStock) map[uint]int {
+	cantidades := make(map[uint]int)
+	for _, movimiento := range movimientos {
+		cantidades[movimiento.ProductoID] += movimiento.Cantidad
+	}
+	return cantidades
+}
+
+func igualesPorProducto(a, b map[uint]int) bool {
+	for _, id := range []uint{1, 2} {
+		if a[id] != b[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestVentaServiceTransicionar(t *testing.T) {
+	casos := []struct {
+		nombre      string
+		estado      string
+		egresados   map[uint]int
+		solicitud   SolicitudTransicionVenta
+		err         error
+		estadoFinal string
+		movimientos map[uint]int // cantidad movida por producto: negativa si egresa
+		stock       map[uint]int
+	}{
+		{
+			nombre:      "completar una pendiente egresa el stock",
+			estado:      entities.EstadoVentaPendiente,
+			solicitud:   SolicitudTransicionVenta{Estado: "completada", Usuario: "ana"},
+			estadoFinal: entities.EstadoVentaCompletada,
+			movimientos: map[uint]int{1: -3, 2: -2},
+			stock:       map[uint]int{1: 7, 2: 8},
+		},
+		{
+			nombre:      "anular una pendiente no mueve stock",
+			estado:      entities.EstadoVentaPendiente,
+			solicitud:   SolicitudTransicionVenta{Estado: "anulada", Usuario: "ana"},
+			estadoFinal: entities.EstadoVentaAnulada,
+			movimientos: map[uint]int{},
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			nombre:      "anular una completada reingresa lo egresado",
+			estado:      entities.EstadoVentaCompletada,
+			egresados:   map[uint]int{1: 3, 2: 2},
+			solicitud:   SolicitudTransicionVenta{Estado: " Anulada ", Usuario: "ana", Motivo: "error de carga"},
+			estadoFinal: entities.EstadoVentaAnulada,
+			movimientos: map[uint]int{1: 3, 2: 2},
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			// Una venta registrada como completada antes de egresar stock no
+			// tiene egresos en el registro de movimientos
+			nombre:      "completada sin egresos registrados no reingresa",
+			estado:      entities.EstadoVentaCompletada,
+			solicitud:   SolicitudTransicionVenta{Estado: "anulada", Usuario: "ana"},
+			estadoFinal: entities.EstadoVentaAnulada,
+			movimientos: map[uint]int{},
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			nombre:      "el reingreso se limita a lo egresado",
+			estado:      entities.EstadoVentaCompletada,
+			egresados:   map[uint]int{1: 1, 2: 2},
+			solicitud:   SolicitudTransicionVenta{Estado: "devuelta", Usuario: "ana"},
+			estadoFinal: entities.EstadoVentaDevuelta,
+			movimientos: map[uint]int{1: 1, 2: 2},
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			nombre:    "devolución parcial que cubre todo pasa a devuelta",
+			estado:    entities.EstadoVentaCompletada,
+			egresados: map[uint]int{1: 3, 2: 2},
+			solicitud: SolicitudTransicionVenta{Estado: "devuelta_parcial", Usuario: "ana", Devoluciones: []DevolucionDetalle{
+				{DetalleID: 1, Cantidad: 3}, {DetalleID: 2, Cantidad: 2},
+			}},
+			estadoFinal: entities.EstadoVentaDevuelta,
+			movimientos: map[uint]int{1: 3, 2: 2},
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			nombre:    "devolver más unidades que las vendidas",
+			estado:    entities.EstadoVentaCompletada,
+			egresados: map[uint]int{1: 3, 2: 2},
+			solicitud: SolicitudTransicionVenta{Estado: "devuelta_parcial", Usuario: "ana", Devoluciones: []DevolucionDetalle{
+				{DetalleID: 1, Cantidad: 4},
+			}},
+			err:         ErrDatosInvalidos,
+			estadoFinal: entities.EstadoVentaCompletada,
+			stock:       map[uint]int{1: 7, 2: 8},
+		},
+		{
+			nombre:    "devolución total que no cubre todo",
+			estado:    entities.EstadoVentaCompletada,
+			egresados: map[uint]int{1: 3, 2: 2},
+			solicitud: SolicitudTransicionVenta{Estado: "devuelta", Usuario: "ana", Devoluciones: []DevolucionDetalle{
+				{DetalleID: 1, Cantidad: 3},
+			}},
+			err:         ErrDatosInvalidos,
+			estadoFinal: entities.EstadoVentaCompletada,
+			stock:       map[uint]int{1: 7, 2: 8},
+		},
+		{
+			nombre:      "transición no admitida",
+			estado:      entities.EstadoVentaAnulada,
+			solicitud:   SolicitudTransicionVenta{Estado: "completada", Usuario: "ana"},
+			err:         ErrTransicionInvalida,
+			estadoFinal: entities.EstadoVentaAnulada,
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+		{
+			nombre:      "sin usuario",
+			estado:      entities.EstadoVentaPendiente,
+			solicitud:   SolicitudTransicionVenta{Estado: "completada"},
+			err:         ErrDatosInvalidos,
+			estadoFinal: entities.EstadoVentaPendiente,
+			stock:       map[uint]int{1: 10, 2: 10},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			ctx := context.Background()
+			e := nuevoEscenarioVenta(t, caso.estado, caso.egresados)
+
+			resultado, err := e.servicio.Transicionar(ctx, e.venta.ID, caso.solicitud)
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("Transicionar = %v, se esperaba %v", err, caso.err)
+			}
+
+			if caso.err == nil {
+				if resultado.Transicion.Desde != caso.estado || resultado.Transicion.Hasta != caso.estadoFinal {
+					t.Errorf("transición de %s a %s, se esperaba de %s a %s",
+						resultado.Transicion.Desde, resultado.Transicion.Hasta, caso.estado, caso.estadoFinal)
+				}
+				if movidas := cantidadesPorProducto(resultado.Movimientos); !igualesPorProducto(movidas, caso.movimientos) {
+					t.Errorf("movimientos por producto = %v, se esperaba %v", movidas, caso.movimientos)
+				}
+			}
+
+			guardada, err := e.ventas.ObtenerPorID(ctx, e.venta.ID)
+			if err != nil {
+				t.Fatalf("ObtenerPorID: %v", err)
+			}
+			if guardada.Estado != caso.estadoFinal {
+				t.Errorf("estado guardado = %s, se esperaba %s", guardada.Estado, caso.estadoFinal)
+			}
+			if stock := e.stock(t); !igualesPorProducto(stock, caso.stock) {
+				t.Errorf("stock = %v, se esperaba %v", stock, caso.stock)
+			}
+		})
+	}
+}
+
+func TestVentaServiceDevolucionesParcialesCompletanLaDevolucion(t *testing.T) {
+	ctx := context.Background()
+	e := nuevoEscenarioVenta(t, entities.EstadoVentaCompletada, map[uint]int{1: 3, 2: 2})
+
+	pasos := []struct {
+		devoluciones []DevolucionDetalle
+		estado       string
+		err          error
+	}{
+		{[]DevolucionDetalle{{DetalleID: 1, Cantidad: 2}}, entities.EstadoVentaDevueltaParcial, nil},
+		// Quedó una unidad del detalle 1 sin devolver
+		{[]DevolucionDetalle{{DetalleID: 1, Cantidad: 2}}, entities.EstadoVentaDevueltaParcial, ErrDatosInvalidos},
+		{[]DevolucionDetalle{{DetalleID: 1, Cantidad: 1}, {DetalleID: 2, Cantidad: 2}}, entities.EstadoVentaDevuelta, nil},
+	}
+
+	var reingresos []*entities.MovimientoStock
+	for i, paso := range pasos {
+		resultado, err := e.servicio.Transicionar(ctx, e.venta.ID, SolicitudTransicionVenta{
+			Estado:       entities.EstadoVentaDevueltaParcial,
+			Usuario:      "ana",
+			Devoluciones: paso.devoluciones,
+		})
+		if !errors.Is(err, paso.err) {
+			t.Fatalf("paso %d: Transicionar = %v, se esperaba %v", i+1, err, paso.err)
+		}
+		if err == nil {
+			reingresos = append(reingresos, resultado.Movimientos...)
+		}
+
+		guardada, _ := e.ventas.ObtenerPorID(ctx, e.venta.ID)
+		if guardada.Estado != paso.estado {
+			t.Errorf("paso %d: estado = %s, se esperaba %s", i+1, guardada.Estado, paso.estado)
+		}
+	}
+
+	if reingresados := cantidadesPorProducto(reingresos); !igualesPorProducto(reingresados, map[uint]int{1: 3, 2: 2}) {
+		t.Errorf("unidades reingresadas = %v, se esperaba 3 y 2", reingresados)
+	}
+	if stock := e.stock(t); !igualesPorProducto(stock, map[uint]int{1: 10, 2: 10}) {
+		t.Errorf("stock = %v, se esperaba 10 y 10", stock)
+	}
+
+	transiciones, _ := e.servicio.ListarTransiciones(ctx, e.venta.ID)
+	if len(transiciones) != 2 || transiciones[1].Hasta != entities.EstadoVentaDevuelta {
+		t.Errorf("transiciones = %+v, se esperaban dos terminando en devuelta", transiciones)
+	}
+}
+
+// ventasConCambioConcurrente simula otra transición confirmada entre la
+// lectura de la venta y el cambio de estado
+type ventasConCambioConcurrente struct {
+	*persistence.VentaRepositoryMemoria
+	estado string
+}
+
+func (r *ventasConCambioConcurrente) ObtenerPorID(ctx context.Context, id uint) (*entities.Venta, error) {
+	venta, err := r.VentaRepositoryMemoria.ObtenerPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	otra := *venta
+	otra.Estado = r.estado
+	if err := r.VentaRepositoryMemoria.CambiarEstado(ctx, &otra, venta.Estado); err != nil {
+		return nil, err
+	}
+	return venta, nil
+}
+
+func TestVentaServiceTransicionarConCambioConcurrente(t *testing.T) {
+	ctx := context.Background()
+	e := nuevoEscenarioVenta(t, entities.EstadoVentaPendiente, nil)
+	ventas := &ventasConCambioConcurrente{VentaRepositoryMemoria: e.ventas, estado: entities.EstadoVentaAnulada}
+	servicio := NewVentaService(events.NewEventBus(), ventas, e.movimientos)
+	servicio.SetOutbox(persistence.NewOutboxRepositoryMemoria(), nil, nil)
+
+	_, err := servicio.Transicionar(ctx, e.venta.ID, SolicitudTransicionVenta{Estado: "completada", Usuario: "ana"})
+	if !errors.Is(err, repositories.ErrModificacionConcurrente) {
+		t.Fatalf("Transicionar = %v, se esperaba ErrModificacionConcurrente", err)
+	}
+
+	guardada, _ := e.ventas.ObtenerPorID(ctx, e.venta.ID)
+	if guardada.Estado != entities.EstadoVentaAnulada {
+		t.Errorf("estado = %s, se esperaba el de la otra transición (anulada)", guardada.Estado)
+	}
+	if stock := e.stock(t); !igualesPorProducto(stock, map[uint]int{1: 10, 2: 10}) {
+		t.Errorf("stock = %v, la venta no debía egresar", stock)
+	}
+}
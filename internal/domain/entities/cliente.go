@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// Cliente es un cliente de la empresa, al que se refieren las ventas por
+// Venta.ClienteID. Los que surgen de convertir un cliente potencial lo
+// referencian por ClientePotencialID.
+type Cliente struct {
+	ID                  uint              `json:"id" gorm:"primaryKey"`
+	Nombre              string            `json:"nombre" gorm:"size:150;not null"`
+	Email               string            `json:"email" gorm:"size:150;index"`
+	Telefono            string            `json:"telefono" gorm:"size:30"`
+	SucursalID          uint              `json:"sucursal_id" gorm:"not null;index"`
+	Sucursal            *Sucursal         `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ClientePotencialID  *uint             `json:"cliente_potencial_id,omitempty" gorm:"uniqueIndex"`
+	ClientePotencial    *ClientePotencial `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	FechaCreacion       time.Time         `json:"fecha_creacion" gorm:"autoCreateTime"`
+	UltimaActualizacion time.Time         `json:"ultima_actualizacion" gorm:"autoUpdateTime"`
+}
+
+// TableName define el nombre de la tabla de clientes
+func (Cliente) TableName() string {
+	return "clientes"
+}
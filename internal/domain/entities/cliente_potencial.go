@@ -0,0 +1,238 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Estados de un cliente potencial
+const (
+	EstadoClienteNuevo      = "nuevo"
+	EstadoClienteContactado = "contactado"
+	EstadoClienteCalificado = "calificado"
+	EstadoClienteConvertido = "convertido"
+	EstadoClienteDescartado = "descartado"
+)
+
+// EstadosCliente enumera los estados posibles de un cliente potencial
+var EstadosCliente = []string{
+	EstadoClienteNuevo, EstadoClienteContactado, EstadoClienteCalificado,
+	EstadoClienteConvertido, EstadoClienteDescartado,
+}
+
+// transicionesCliente indica a qué estados puede pasar un cliente potencial
+// desde cada estado; convertido y descartado son finales
+var transicionesCliente = map[string][]string{
+	EstadoClienteNuevo:      {EstadoClienteContactado, EstadoClienteDescartado},
+	EstadoClienteContactado: {EstadoClienteCalificado, EstadoClienteDescartado},
+	EstadoClienteCalificado: {EstadoClienteConvertido, EstadoClienteDescartado},
+}
+
+// NombreAnonimizado reemplaza el nombre de un cliente potencial anonimizado
+const NombreAnonimizado = "anonimizado"
+
+// ClientePotencial es una persona interesada captada por una sucursal, que
+// todavía no es cliente
+type ClientePotencial struct {
+	ID                   uint            `json:"id" gorm:"primaryKey"`
+	Nombre               string          `json:"nombre" gorm:"size:150;not null"`
+	Email                string          `json:"email" gorm:"size:150;index"`
+	Telefono             string          `json:"telefono" gorm:"size:30"`
+	Fuente               string          `json:"fuente" gorm:"size:50;index"` // 'formulario_web', 'referido', 'evento', etc.
+	FechaCaptura         time.Time       `json:"fecha_captura" gorm:"not null;index"`
+	Interes              string          `json:"interes" gorm:"size:100"`
+	Notas                string          `json:"notas" gorm:"type:text"`
+	Estado               string          `json:"estado" gorm:"size:20;not null;index"`
+	SucursalID           uint            `json:"sucursal_id" gorm:"not null;index"`
+	Sucursal             *Sucursal       `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	EstadoActualizadoEn  *time.Time      `json:"estado_actualizado_en,omitempty"`
+	EstadoActualizadoPor string          `json:"estado_actualizado_por,omitempty" gorm:"size:100"`
+	ClienteID            *uint           `json:"cliente_id,omitempty" gorm:"index"` // cliente en que se convirtió
+	Puntaje              float64         `json:"puntaje" gorm:"index"`
+	FactoresPuntaje      []FactorPuntaje `json:"factores_puntaje,omitempty" gorm:"serializer:json;type:text"` // explicación del puntaje
+	PuntajeCalculadoEn   *time.Time      `json:"puntaje_calculado_en,omitempty"`
+	AnonimizadoEn        *time.Time      `json:"anonimizado_en,omitempty"` // los datos personales se borraron al vencer su retención
+	FechaCreacion        time.Time       `json:"fecha_creacion" gorm:"autoCreateTime"`
+	UltimaActualizacion  time.Time       `json:"ultima_actualizacion" gorm:"autoUpdateTime"`
+}
+
+// TableName define el nombre de la tabla de clientes potenciales
+func (ClientePotencial) TableName() string {
+	return "clientes_potenciales"
+}
+
+// EsEstadoCliente verifica si el estado es uno de los de un cliente potencial
+func EsEstadoCliente(estado string) bool {
+	for _, valido := range EstadosCliente {
+		if estado == valido {
+			return true
+		}
+	}
+	return false
+}
+
+// TransicionesPosibles retorna los estados a los que puede pasar el cliente potencial
+func (c *ClientePotencial) TransicionesPosibles() []string {
+	return transicionesCliente[c.Estado]
+}
+
+// PuedePasarA verifica si el cliente potencial puede pasar al estado indicado
+func (c *ClientePotencial) PuedePasarA(estado string) bool {
+	for _, posible := range transicionesCliente[c.Estado] {
+		if estado == posible {
+			return true
+		}
+	}
+	return false
+}
+
+// Transicionar pasa el cliente potencial al estado indicado, registrando quién
+// y cuándo lo cambió, y retorna la transición para el historial
+func (c *ClientePotencial) Transicionar(estado, usuario, motivo string, fecha time.Time) (*TransicionCliente, error) {
+	if !c.PuedePasarA(estado) {
+		if len(c.TransicionesPosibles()) == 0 {
+			return nil, fmt.Errorf("el cliente potencial está %s y no admite cambios de estado", c.Estado)
+		}
+		return nil, fmt.Errorf("no se puede pasar de %s a %q (se admite %s)", c.Estado, estado, strings.Join(c.TransicionesPosibles(), ", "))
+	}
+
+	transicion := &TransicionCliente{
+		ClientePotencialID: c.ID,
+		Desde:              c.Estado,
+		Hasta:              estado,
+		Usuario:            usuario,
+		Motivo:             motivo,
+		Fecha:              fecha,
+	}
+	c.Estado = estado
+	c.EstadoActualizadoEn = &fecha
+	c.EstadoActualizadoPor = usuario
+	return transicion, nil
+}
+
+// UltimoCambioEstado retorna cuándo el cliente potencial entró a su estado actual
+func (c *ClientePotencial) UltimoCambioEstado() time.Time {
+	if c.EstadoActualizadoEn != nil {
+		return *c.EstadoActualizadoEn
+	}
+	return c.FechaCaptura
+}
+
+// NuevoCliente crea el cliente que surge de convertir el cliente potencial
+func (c *ClientePotencial) NuevoCliente() *Cliente {
+	return &Cliente{
+		Nombre:             c.Nombre,
+		Email:              c.Email,
+		Telefono:           c.Telefono,
+		SucursalID:         c.SucursalID,
+		ClientePotencialID: &c.ID,
+	}
+}
+
+// Normalizar quita los espacios sobrantes de los datos y pasa el email a minúsculas
+func (c *ClientePotencial) Normalizar() {
+	c.Nombre = strings.TrimSpace(c.Nombre)
+	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
+	c.Telefono = strings.TrimSpace(c.Telefono)
+	c.Fuente = strings.ToLower(strings.TrimSpace(c.Fuente))
+	c.Interes = strings.TrimSpace(c.Interes)
+	c.Estado = strings.ToLower(strings.TrimSpace(c.Estado))
+}
+
+// Validar verifica que el cliente potencial tenga nombre, sucursal y un medio
+// de contacto válido, y un estado conocido
+func (c *ClientePotencial) Validar() error {
+	var errs []error
+	if c.Nombre == "" {
+		errs = append(errs, errors.New("el nombre es requerido"))
+	}
+	if c.SucursalID == 0 {
+		errs = append(errs, errors.New("la sucursal es requerida"))
+	}
+	if c.Email == "" && c.Telefono == "" {
+		errs = append(errs, errors.New("se requiere email o teléfono"))
+	}
+	if c.Email != "" {
+		if direccion, err := mail.ParseAddress(c.Email); err != nil || direccion.Address != c.Email {
+			errs = append(errs, fmt.Errorf("email inválido: %s", c.Email))
+		}
+	}
+	if c.Telefono != "" && !esTelefono(c.Telefono) {
+		errs = append(errs, fmt.Errorf("teléfono inválido: %s", c.Telefono))
+	}
+	if !EsEstadoCliente(c.Estado) {
+		errs = append(errs, fmt.Errorf("estado desconocido %q (se admite %s)", c.Estado, strings.Join(EstadosCliente, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// esTelefono verifica que el teléfono tenga entre 6 y 15 dígitos, con un +
+// inicial opcional y espacios, guiones, puntos o paréntesis como separadores
+func esTelefono(telefono string) bool {
+	digitos := 0
+	for i, r := range telefono {
+		switch {
+		case r >= '0' && r <= '9':
+			digitos++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return false
+		}
+	}
+	return digitos >= 6 && digitos <= 15
+}
+
+// Anonimizar borra los datos personales del cliente potencial, conservando los
+// datos necesarios para las estadísticas
+func (c *ClientePotencial) Anonimizar(fecha time.Time) {
+	c.Nombre = NombreAnonimizado
+	c.Email = ""
+	c.Telefono = ""
+	c.Notas = ""
+	c.AnonimizadoEn = &fecha
+}
+
+// TransicionCliente registra un cambio de estado de un cliente potencial
+type TransicionCliente struct {
+	ID                 uint              `json:"id" gorm:"primaryKey"`
+	ClientePotencialID uint              `json:"cliente_potencial_id" gorm:"not null;index"`
+	ClientePotencial   *ClientePotencial `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Desde              string            `json:"desde" gorm:"size:20;not null"`
+	Hasta              string            `json:"hasta" gorm:"size:20;not null;index"`
+	Usuario            string            `json:"usuario" gorm:"size:100;not null"`
+	Motivo             string            `json:"motivo,omitempty" gorm:"size:500"`
+	Fecha              time.Time         `json:"fecha" gorm:"not null;index"`
+}
+
+// TableName define el nombre de la tabla del historial de estados de los clientes potenciales
+func (TransicionCliente) TableName() string {
+	return "transiciones_clientes"
+}
+
+// FactorPuntaje explica cuánto aporta un factor al puntaje de un cliente potencial
+type FactorPuntaje struct {
+	Factor      string  `json:"factor"` // 'fuente', 'interes', 'recencia', 'interacciones', 'sucursal'
+	Valor       string  `json:"valor"`
+	Puntos      float64 `json:"puntos"`
+	Explicacion string  `json:"explicacion"`
+}
+
+// InteraccionCliente registra una interacción de un cliente potencial con la
+// empresa, como una visita, una llamada o la respuesta a un email
+type InteraccionCliente struct {
+	ID                 uint              `json:"id" gorm:"primaryKey"`
+	ClientePotencialID uint              `json:"cliente_potencial_id" gorm:"not null;index"`
+	ClientePotencial   *ClientePotencial `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tipo               string            `json:"tipo" gorm:"size:30;not null"` // 'visita', 'llamada', 'email_respondido', etc.
+	Usuario            string            `json:"usuario,omitempty" gorm:"size:100"`
+	Fecha              time.Time         `json:"fecha" gorm:"not null;index"`
+}
+
+// TableName define el nombre de la tabla de interacciones de los clientes potenciales
+func (InteraccionCliente) TableName() string {
+	return "interacciones_clientes"
+}
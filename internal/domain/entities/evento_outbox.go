@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EventoOutbox es un evento de dominio guardado en la misma transacción que los
+// cambios que lo originan, pendiente de publicarse mientras no se entregue ni
+// se descarte por agotar sus intentos de entrega
+type EventoOutbox struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Tipo         string     `json:"tipo" gorm:"size:100;not null"`
+	Origen       string     `json:"origen" gorm:"size:100"`
+	Datos        string     `json:"datos" gorm:"type:text"` // JSON
+	CreadoEn     time.Time  `json:"creado_en" gorm:"not null"`
+	EntregadoEn  *time.Time `json:"entregado_en,omitempty" gorm:"index"`
+	DescartadoEn *time.Time `json:"descartado_en,omitempty" gorm:"index"`
+	Intentos     int        `json:"intentos"`
+	UltimoError  string     `json:"ultimo_error,omitempty" gorm:"size:500"`
+
+	// Proceso que está entregando el evento y hasta cuándo lo tiene reservado
+	ReclamadoPor   string     `json:"reclamado_por,omitempty" gorm:"size:100"`
+	ReclamadoHasta *time.Time `json:"reclamado_hasta,omitempty"`
+}
+
+// TableName define el nombre de la tabla del outbox de eventos
+func (EventoOutbox) TableName() string {
+	return "eventos_outbox"
+}
+
+// NewEventoOutbox crea un evento pendiente con sus datos serializados en JSON
+func NewEventoOutbox(tipo, origen string, datos map[string]interface{}) (*EventoOutbox, error) {
+	serializados, err := json.Marshal(datos)
+	if err != nil {
+		return nil, fmt.Errorf("datos del evento %s: %w", tipo, err)
+	}
+	return &EventoOutbox{
+		Tipo:     tipo,
+		Origen:   origen,
+		Datos:    string(serializados),
+		CreadoEn: time.Now(),
+	}, nil
+}
+
+// DatosEvento retorna los datos del evento. Los números se obtienen como float64.
+func (e *EventoOutbox) DatosEvento() (map[string]interface{}, error) {
+	var datos map[string]interface{}
+	if err := json.Unmarshal([]byte(e.Datos), &datos); err != nil {
+		return nil, fmt.Errorf("datos del evento %d: %w", e.ID, err)
+	}
+	return datos, nil
+}
+
+// FueEntregado indica si el evento ya se publicó
+func (e *EventoOutbox) FueEntregado() bool {
+	return e.EntregadoEn != nil
+}
+
+// EstaPendiente indica si el evento aún debe entregarse
+func (e *EventoOutbox) EstaPendiente() bool {
+	return e.EntregadoEn == nil && e.DescartadoEn == nil
+}
+
+// EstaReclamado indica si otro proceso tiene reservada la entrega del evento en la fecha
+func (e *EventoOutbox) EstaReclamado(fecha time.Time) bool {
+	return e.ReclamadoHasta != nil && !e.ReclamadoHasta.Before(fecha)
+}
@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// LoteArchivado describe un lote de datos crudos tal como se recibió. Su
+// contenido se guarda comprimido e identificado por Hash, de modo que los lotes
+// con los mismos registros comparten un único archivo.
+type LoteArchivado struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	LoteID           string    `json:"lote_id" gorm:"size:64;not null;uniqueIndex"`
+	Hash             string    `json:"hash" gorm:"size:64;not null;index"` // SHA-256 del contenido sin comprimir
+	SucursalID       uint      `json:"sucursal_id" gorm:"index"`
+	Tipo             string    `json:"tipo" gorm:"size:20"`
+	Origen           string    `json:"origen" gorm:"size:255"`
+	Registros        int       `json:"registros"`
+	TamanoOriginal   int64     `json:"tamano_original"`
+	TamanoComprimido int64     `json:"tamano_comprimido"`
+	RecibidoEn       time.Time `json:"recibido_en" gorm:"not null;index"`
+	ReprocesoDe      string    `json:"reproceso_de,omitempty" gorm:"size:64"` // lote archivado del que es un reproceso
+
+	// Resultado del procesamiento, para compararlo al reprocesar el lote
+	ProcesadoEn *time.Time           `json:"procesado_en,omitempty"`
+	Resumen     ResumenProcesamiento `json:"resumen" gorm:"embedded;embeddedPrefix:registros_"`
+	Error       string               `json:"error,omitempty" gorm:"size:500"`
+}
+
+// TableName define el nombre de la tabla del archivo de lotes
+func (LoteArchivado) TableName() string {
+	return "lotes_archivados"
+}
+
+// ResumenProcesamiento cuenta los registros de un lote en cada etapa del procesamiento
+type ResumenProcesamiento struct {
+	Validos      int `json:"validos"`
+	Filtrados    int `json:"filtrados"`
+	Unicos       int `json:"unicos"`
+	Persistidos  int `json:"persistidos"`
+	Insertados   int `json:"insertados"`
+	Actualizados int `json:"actualizados"`
+	SinCambios   int `json:"sin_cambios"`
+	Fallidos     int `json:"fallidos"`
+}
+
+// DiferenciaResumen es una cuenta que cambió entre dos procesamientos de un lote
+type DiferenciaResumen struct {
+	Campo      string `json:"campo"`
+	Antes      int    `json:"antes"`
+	Despues    int    `json:"despues"`
+	Diferencia int    `json:"diferencia"`
+}
+
+// Diferencias retorna las cuentas que cambian del resumen al resumen posterior
+func (r ResumenProcesamiento) Diferencias(posterior ResumenProcesamiento) []DiferenciaResumen {
+	campos := []struct {
+		nombre         string
+		antes, despues int
+	}{
+		{"validos", r.Validos, posterior.Validos},
+		{"filtrados", r.Filtrados, posterior.Filtrados},
+		{"unicos", r.Unicos, posterior.Unicos},
+		{"persistidos", r.Persistidos, posterior.Persistidos},
+		{"insertados", r.Insertados, posterior.Insertados},
+		{"actualizados", r.Actualizados, posterior.Actualizados},
+		{"sin_cambios", r.SinCambios, posterior.SinCambios},
+		{"fallidos", r.Fallidos, posterior.Fallidos},
+	}
+
+	diferencias := []DiferenciaResumen{}
+	for _, campo := range campos {
+		if campo.antes != campo.despues {
+			diferencias = append(diferencias, DiferenciaResumen{
+				Campo:      campo.nombre,
+				Antes:      campo.antes,
+				Despues:    campo.despues,
+				Diferencia: campo.despues - campo.antes,
+			})
+		}
+	}
+	return diferencias
+}
+
+// HashContenido retorna el identificador del contenido de un lote archivado
+func HashContenido(contenido []byte) string {
+	suma := sha256.Sum256(contenido)
+	return hex.EncodeToString(suma[:])
+}
@@ -0,0 +1,78 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos de movimiento de stock
+const (
+	MovimientoVenta         = "venta"
+	MovimientoDevolucion    = "devolucion"
+	MovimientoAjuste        = "ajuste"
+	MovimientoTransferencia = "transferencia"
+	MovimientoRecepcion     = "recepcion"
+)
+
+// MovimientoStock registra una variación del stock de un producto en una
+// sucursal. El stock de un producto es la suma de sus movimientos: Producto.StockActual
+// solo cambia al registrar uno.
+type MovimientoStock struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ProductoID      uint      `json:"producto_id" gorm:"not null;index:idx_movimientos_producto_sucursal"`
+	Producto        *Producto `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	SucursalID      uint      `json:"sucursal_id" gorm:"index:idx_movimientos_producto_sucursal"` // 0 para el saldo inicial sin sucursal
+	Tipo            string    `json:"tipo" gorm:"size:20;not null"`
+	Cantidad        int       `json:"cantidad"`         // positiva si ingresa stock, negativa si egresa
+	StockResultante int       `json:"stock_resultante"` // stock total del producto después del movimiento
+	Referencia      string    `json:"referencia,omitempty" gorm:"size:100;index:idx_movimientos_referencia"`
+	LoteID          string    `json:"lote_id,omitempty" gorm:"size:64"`
+	Fecha           time.Time `json:"fecha" gorm:"not null;index"`
+}
+
+// TableName define el nombre de la tabla de movimientos de stock
+func (MovimientoStock) TableName() string {
+	return "movimientos_stock"
+}
+
+// NewMovimientoStock crea un movimiento del tipo indicado. Las ventas siempre
+// egresan y las devoluciones y recepciones siempre ingresan stock, sin importar
+// el signo de la cantidad; los ajustes y transferencias conservan su signo.
+func NewMovimientoStock(tipo string, productoID, sucursalID uint, cantidad int) (*MovimientoStock, error) {
+	switch tipo {
+	case MovimientoVenta:
+		cantidad = -abs(cantidad)
+	case MovimientoDevolucion, MovimientoRecepcion:
+		cantidad = abs(cantidad)
+	case MovimientoAjuste, MovimientoTransferencia:
+	default:
+		return nil, fmt.Errorf("tipo de movimiento de stock desconocido %q", tipo)
+	}
+	if cantidad == 0 {
+		return nil, fmt.Errorf("el movimiento de stock requiere una cantidad distinta de cero")
+	}
+
+	return &MovimientoStock{
+		ProductoID: productoID,
+		SucursalID: sucursalID,
+		Tipo:       tipo,
+		Cantidad:   cantidad,
+		Fecha:      time.Now(),
+	}, nil
+}
+
+// EsTipoMovimiento verifica si el tipo corresponde a un movimiento de stock conocido
+func EsTipoMovimiento(tipo string) bool {
+	switch tipo {
+	case MovimientoVenta, MovimientoDevolucion, MovimientoAjuste, MovimientoTransferencia, MovimientoRecepcion:
+		return true
+	}
+	return false
+}
+
+func abs(valor int) int {
+	if valor < 0 {
+		return -valor
+	}
+	return valor
+}
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+)
+
+// PrecioHistorico registra un cambio de precio de un producto y el período en
+// que estuvo vigente. VigenteHasta es nil mientras el precio sigue vigente.
+type PrecioHistorico struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	ProductoID           uint       `json:"producto_id" gorm:"not null;index:idx_precios_producto_vigencia"`
+	Producto             *Producto  `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	PrecioAnterior       float64    `json:"precio_anterior"`
+	PrecioOfertaAnterior float64    `json:"precio_oferta_anterior"`
+	Precio               float64    `json:"precio"`
+	PrecioOferta         float64    `json:"precio_oferta"`
+	SucursalID           uint       `json:"sucursal_id,omitempty"`
+	LoteID               string     `json:"lote_id,omitempty" gorm:"size:64"`
+	VigenteDesde         time.Time  `json:"vigente_desde" gorm:"not null;index:idx_precios_producto_vigencia"`
+	VigenteHasta         *time.Time `json:"vigente_hasta,omitempty"`
+}
+
+// TableName define el nombre de la tabla del historial de precios
+func (PrecioHistorico) TableName() string {
+	return "precios_historicos"
+}
+
+// NewPrecioHistorico crea el registro del cambio de precio del producto, vigente
+// desde la fecha indicada
+func NewPrecioHistorico(anterior, actual *Producto, vigenteDesde time.Time) *PrecioHistorico {
+	cambio := &PrecioHistorico{
+		ProductoID:   actual.ID,
+		Precio:       actual.Precio,
+		PrecioOferta: actual.PrecioOferta,
+		VigenteDesde: vigenteDesde,
+	}
+	if anterior != nil {
+		cambio.PrecioAnterior = anterior.Precio
+		cambio.PrecioOfertaAnterior = anterior.PrecioOferta
+	}
+	return cambio
+}
+
+// CambiaPrecio verifica si el precio o la oferta del producto difieren entre ambas versiones
+func CambiaPrecio(anterior, actual *Producto) bool {
+	return anterior == nil || anterior.Precio != actual.Precio || anterior.PrecioOferta != actual.PrecioOferta
+}
+
+// VigenteEn verifica si el precio estaba vigente en la fecha indicada
+func (p *PrecioHistorico) VigenteEn(fecha time.Time) bool {
+	return !fecha.Before(p.VigenteDesde) && (p.VigenteHasta == nil || fecha.Before(*p.VigenteHasta))
+}
+
+// CalcularPrecioFinal calcula el precio final del período considerando la oferta
+func (p *PrecioHistorico) CalcularPrecioFinal() float64 {
+	if p.PrecioOferta > 0 && p.PrecioOferta < p.Precio {
+		return p.PrecioOferta
+	}
+	return p.Precio
+}
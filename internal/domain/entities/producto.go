@@ -6,18 +6,42 @@ import (
 
 // Producto representa un producto en el sistema
 type Producto struct {
-	SKU                 string    `json:"sku"`
-	Nombre              string    `json:"nombre"`
-	Descripcion         string    `json:"descripcion"`
-	Categoria           string    `json:"categoria"`
-	Fabricante          string    `json:"fabricante"`
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	SKU                 string    `json:"sku" gorm:"size:64;not null;uniqueIndex"`
+	Nombre              string    `json:"nombre" gorm:"size:200;not null"`
+	Descripcion         string    `json:"descripcion" gorm:"type:text"`
+	Categoria           string    `json:"categoria" gorm:"size:100;index"`
+	Fabricante          string    `json:"fabricante" gorm:"size:100"`
 	Precio              float64   `json:"precio"`
 	PrecioOferta        float64   `json:"precio_oferta"`
 	StockMinimo         int       `json:"stock_minimo"`
 	StockActual         int       `json:"stock_actual"`
-	Estado              string    `json:"estado"`
-	FechaCreacion       time.Time `json:"fecha_creacion"`
-	UltimaActualizacion time.Time `json:"ultima_actualizacion"`
+	Estado              string    `json:"estado" gorm:"size:20"`
+	FechaCreacion       time.Time `json:"fecha_creacion" gorm:"autoCreateTime"`
+	UltimaActualizacion time.Time `json:"ultima_actualizacion" gorm:"autoUpdateTime"`
+}
+
+// CamposProducto enumera los campos de Producto que puede actualizar una
+// sincronización, con el nombre que tienen en los registros recibidos.
+// stock_actual no se copia: se ajusta con un movimiento de stock.
+var CamposProducto = []string{
+	"nombre", "descripcion", "categoria", "fabricante", "precio",
+	"precio_oferta", "stock_minimo", "stock_actual", "estado",
+}
+
+// EsCampoProducto verifica si el nombre corresponde a un campo actualizable de Producto
+func EsCampoProducto(campo string) bool {
+	for _, nombre := range CamposProducto {
+		if nombre == campo {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName define el nombre de la tabla de productos
+func (Producto) TableName() string {
+	return "productos"
 }
 
 // EsValido verifica si el producto tiene los datos mínimos requeridos
@@ -39,10 +63,12 @@ func (p *Producto) EnriquecerDesdeAPI(datosAPI map[string]interface{}) {
 	p.UltimaActualizacion = time.Now()
 }
 
-// ActualizarStock actualiza el stock del producto
-func (p *Producto) ActualizarStock(nuevoStock int) {
-	p.StockActual = nuevoStock
+// AplicarMovimiento suma el movimiento al stock del producto y registra en él
+// el stock resultante
+func (p *Producto) AplicarMovimiento(movimiento *MovimientoStock) {
+	p.StockActual += movimiento.Cantidad
 	p.UltimaActualizacion = time.Now()
+	movimiento.StockResultante = p.StockActual
 }
 
 // TieneStockSuficiente verifica si el producto tiene stock suficiente
@@ -57,3 +83,38 @@ func (p *Producto) CalcularPrecioFinal() float64 {
 	}
 	return p.Precio
 }
+
+// Fusionar copia desde origen los campos indicados cuyo valor difiere y retorna
+// los nombres de los campos modificados
+func (p *Producto) Fusionar(origen *Producto, campos []string) []string {
+	var modificados []string
+	for _, campo := range campos {
+		cambio := false
+		switch campo {
+		case "nombre":
+			cambio, p.Nombre = p.Nombre != origen.Nombre, origen.Nombre
+		case "descripcion":
+			cambio, p.Descripcion = p.Descripcion != origen.Descripcion, origen.Descripcion
+		case "categoria":
+			cambio, p.Categoria = p.Categoria != origen.Categoria, origen.Categoria
+		case "fabricante":
+			cambio, p.Fabricante = p.Fabricante != origen.Fabricante, origen.Fabricante
+		case "precio":
+			cambio, p.Precio = p.Precio != origen.Precio, origen.Precio
+		case "precio_oferta":
+			cambio, p.PrecioOferta = p.PrecioOferta != origen.PrecioOferta, origen.PrecioOferta
+		case "stock_minimo":
+			cambio, p.StockMinimo = p.StockMinimo != origen.StockMinimo, origen.StockMinimo
+		case "estado":
+			cambio, p.Estado = p.Estado != origen.Estado, origen.Estado
+		}
+		if cambio {
+			modificados = append(modificados, campo)
+		}
+	}
+
+	if len(modificados) > 0 {
+		p.UltimaActualizacion = time.Now()
+	}
+	return modificados
+}
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Acciones que la purga aplica a los datos vencidos
+const (
+	AccionPurgaEliminar   = "eliminar"
+	AccionPurgaAnonimizar = "anonimizar"
+)
+
+// RegistroPurga deja constancia de una ejecución de la purga sobre una clase de
+// datos: qué se hizo, con qué corte y a cuántos registros alcanzó
+type RegistroPurga struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Clase        string    `json:"clase" gorm:"size:30;not null;index"`
+	Accion       string    `json:"accion" gorm:"size:20;not null"`
+	AntesDe      time.Time `json:"antes_de"` // se purgaron los datos anteriores a esta fecha
+	Registros    int       `json:"registros"`
+	Lotes        int       `json:"lotes"` // tandas en que se purgaron los registros
+	IniciadaEn   time.Time `json:"iniciada_en" gorm:"not null;index"`
+	FinalizadaEn time.Time `json:"finalizada_en"`
+	Error        string    `json:"error,omitempty" gorm:"size:500"`
+}
+
+// TableName define el nombre de la tabla de auditoría de purgas
+func (RegistroPurga) TableName() string {
+	return "purgas"
+}
@@ -1,34 +1,65 @@
 package entities
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	"sistema-gestion-informacion/internal/domain/filtros"
 )
 
 // Sucursal representa una sucursal en el sistema
 type Sucursal struct {
-	Nombre        string    `json:"nombre"`
-	Direccion     string    `json:"direccion"`
-	Telefono      string    `json:"telefono"`
-	Email         string    `json:"email"`
-	Ciudad        string    `json:"ciudad"`
-	Estado        string    `json:"estado"`
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Nombre        string    `json:"nombre" gorm:"size:150;not null"`
+	Direccion     string    `json:"direccion" gorm:"size:255"`
+	Telefono      string    `json:"telefono" gorm:"size:30"`
+	Email         string    `json:"email" gorm:"size:150"`
+	Ciudad        string    `json:"ciudad" gorm:"size:100"`
+	Estado        string    `json:"estado" gorm:"size:20;index"`
 	FechaApertura time.Time `json:"fecha_apertura"`
 
-	// Configuración de integración
+	// Configuración de integración. APIKey y APISecret son de solo escritura: se
+	// guardan cifradas y no se serializan
 	APIEndpoint          string    `json:"api_endpoint"`
-	APIKey               string    `json:"api_key"`
-	APISecret            string    `json:"api_secret"`
-	TipoSistema          string    `json:"tipo_sistema"`  // 'api', 'csv', 'excel', 'database'
-	Configuracion        string    `json:"configuracion"` // JSON con configuración específica
+	APIKey               string    `json:"api_key,omitempty" swaggerignore:"true"`
+	APISecret            string    `json:"api_secret,omitempty" swaggerignore:"true"`
+	TipoSistema          string    `json:"tipo_sistema"`                   // 'api', 'csv', 'excel', 'ancho_fijo', 'xml', 'database'
+	Configuracion        string    `json:"configuracion" gorm:"type:text"` // JSON con configuración específica
 	UltimaSincronizacion time.Time `json:"ultima_sincronizacion"`
+
+	// Indican si hay credenciales configuradas; se completan al serializar
+	APIKeyConfigurada    bool `json:"api_key_configurada" gorm:"-"`
+	APISecretConfigurado bool `json:"api_secret_configurado" gorm:"-"`
+}
+
+// MarshalJSON serializa la sucursal sin sus credenciales, que son de solo
+// escritura: en su lugar informa si están configuradas
+func (s Sucursal) MarshalJSON() ([]byte, error) {
+	type sucursalSinMetodos Sucursal
+	copia := sucursalSinMetodos(s)
+	copia.APIKeyConfigurada = s.APIKey != ""
+	copia.APISecretConfigurado = s.APISecret != ""
+	copia.APIKey, copia.APISecret = "", ""
+	return json.Marshal(copia)
 }
 
-// ObtenerParametros retorna los parámetros de conexión para la sucursal
+// String describe la sucursal sin sus credenciales, para los logs
+func (s Sucursal) String() string {
+	return fmt.Sprintf("Sucursal{ID: %d, Nombre: %q, TipoSistema: %q}", s.ID, s.Nombre, s.TipoSistema)
+}
+
+// TableName define el nombre de la tabla de sucursales
+func (Sucursal) TableName() string {
+	return "sucursales"
+}
+
+// ObtenerParametros retorna los parámetros de conexión para la sucursal. No
+// incluye las credenciales, que se leen de APIKey y APISecret.
 func (s *Sucursal) ObtenerParametros() map[string]interface{} {
 	return map[string]interface{}{
 		"endpoint": s.APIEndpoint,
-		"api_key":  s.APIKey,
-		"secret":   s.APISecret,
 		"tipo":     s.TipoSistema,
 		"config":   s.Configuracion,
 	}
@@ -49,6 +80,57 @@ func (s *Sucursal) EsValida() bool {
 	return s.Nombre != "" && s.TipoSistema != ""
 }
 
+// ObtenerConfiguracion interpreta el JSON de configuración específica de la sucursal
+func (s *Sucursal) ObtenerConfiguracion() (*ConfiguracionSistema, error) {
+	config := &ConfiguracionSistema{
+		TipoSistema: s.TipoSistema,
+		Parametros:  make(map[string]string),
+		MapeoCampos: make(map[string]string),
+	}
+
+	if s.Configuracion == "" {
+		return config, nil
+	}
+
+	if err := json.Unmarshal([]byte(s.Configuracion), config); err != nil {
+		return nil, fmt.Errorf("configuración inválida para la sucursal %s: %v", s.Nombre, err)
+	}
+
+	return config, nil
+}
+
+// ValidarConfiguracion verifica que la configuración específica sea un JSON
+// válido y que sus filtros compilen
+func (s *Sucursal) ValidarConfiguracion() error {
+	config, err := s.ObtenerConfiguracion()
+	if err != nil {
+		return err
+	}
+
+	if _, err := config.CompilarFiltros(); err != nil {
+		return err
+	}
+
+	for _, campo := range config.AutoridadProducto {
+		if !EsCampoProducto(campo) {
+			return fmt.Errorf("autoridad_producto: campo de producto desconocido %q", campo)
+		}
+	}
+
+	if config.AnchoFijo != nil {
+		if err := config.AnchoFijo.Validar(); err != nil {
+			return err
+		}
+	}
+	if config.XML != nil {
+		if err := config.XML.Validar(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ConfiguracionSistema representa la configuración específica de cada sistema
 type ConfiguracionSistema struct {
 	TipoSistema             string            `json:"tipo_sistema"`
@@ -56,4 +138,105 @@ type ConfiguracionSistema struct {
 	MapeoCampos             map[string]string `json:"mapeo_campos"`
 	Filtros                 []string          `json:"filtros"`
 	IntervaloSincronizacion int               `json:"intervalo_sincronizacion"` // en minutos
+
+	// Campos de Producto que la sucursal puede modificar en productos existentes;
+	// vacío indica todos (por ejemplo, la casa central el precio y las sucursales el stock)
+	AutoridadProducto []string `json:"autoridad_producto,omitempty"`
+
+	// Formatos heredados de punto de venta
+	AnchoFijo *LayoutAnchoFijo `json:"ancho_fijo,omitempty"`
+	XML       *MapeoXML        `json:"xml,omitempty"`
+}
+
+// CompilarFiltros compila las expresiones de Filtros. El error detalla cada filtro inválido.
+func (c *ConfiguracionSistema) CompilarFiltros() ([]*filtros.Expresion, error) {
+	expresiones, errores := filtros.CompilarTodos(c.Filtros)
+	if len(errores) > 0 {
+		return nil, errors.Join(errores...)
+	}
+	return expresiones, nil
+}
+
+// Tipos de columna admitidos en los archivos de ancho fijo
+const (
+	ColumnaTexto  = "texto"
+	ColumnaNumero = "numero"
+	ColumnaFecha  = "fecha"
+)
+
+// LayoutAnchoFijo describe las columnas de un archivo de texto de ancho fijo
+type LayoutAnchoFijo struct {
+	Columnas     []ColumnaAnchoFijo `json:"columnas"`
+	LineasOmitir int                `json:"lineas_omitir"` // encabezados a descartar
+}
+
+// ColumnaAnchoFijo ubica un campo dentro de cada línea. Inicio es la posición
+// del primer carácter, contando desde 1.
+type ColumnaAnchoFijo struct {
+	Campo     string `json:"campo"`
+	Inicio    int    `json:"inicio"`
+	Longitud  int    `json:"longitud"`
+	Tipo      string `json:"tipo"`      // 'texto', 'numero', 'fecha'; vacío deduce el tipo
+	Decimales int    `json:"decimales"` // decimales implícitos de los números sin separador
+	Formato   string `json:"formato"`   // layout de Go para las fechas, por defecto 20060102
+}
+
+// Validar verifica que las columnas estén completas y no se superpongan
+func (l *LayoutAnchoFijo) Validar() error {
+	if len(l.Columnas) == 0 {
+		return fmt.Errorf("el layout de ancho fijo no define columnas")
+	}
+	if l.LineasOmitir < 0 {
+		return fmt.Errorf("el layout de ancho fijo tiene lineas_omitir negativo")
+	}
+
+	ocupadas := make(map[int]string)
+	for _, columna := range l.Columnas {
+		if columna.Campo == "" {
+			return fmt.Errorf("el layout de ancho fijo tiene una columna sin campo")
+		}
+		if columna.Inicio < 1 || columna.Longitud < 1 {
+			return fmt.Errorf("columna %s: inicio y longitud deben ser mayores a cero", columna.Campo)
+		}
+		if columna.Decimales < 0 {
+			return fmt.Errorf("columna %s: decimales no puede ser negativo", columna.Campo)
+		}
+		switch columna.Tipo {
+		case "", ColumnaTexto, ColumnaNumero, ColumnaFecha:
+		default:
+			return fmt.Errorf("columna %s: tipo no soportado %q", columna.Campo, columna.Tipo)
+		}
+
+		for posicion := columna.Inicio; posicion < columna.Inicio+columna.Longitud; posicion++ {
+			if otra, existe := ocupadas[posicion]; existe {
+				return fmt.Errorf("las columnas %s y %s se superponen en la posición %d", otra, columna.Campo, posicion)
+			}
+			ocupadas[posicion] = columna.Campo
+		}
+	}
+
+	return nil
+}
+
+// MapeoXML indica cómo extraer registros de un documento XML con rutas al
+// estilo XPath. Registro selecciona los elementos que forman cada registro
+// ("facturas/factura", o "//factura" para buscarlos a cualquier profundidad) y
+// Campos asocia cada campo a una ruta relativa al registro ("cliente/nombre",
+// "@numero", "cliente/@id" o "." para el texto del propio elemento).
+type MapeoXML struct {
+	Registro string            `json:"registro"`
+	Campos   map[string]string `json:"campos"`
+}
+
+// Validar verifica que el mapeo indique los registros y rutas no vacías
+func (m *MapeoXML) Validar() error {
+	if m.Registro == "" {
+		return fmt.Errorf("el mapeo XML no indica la ruta de los registros")
+	}
+	for campo, ruta := range m.Campos {
+		if campo == "" || ruta == "" {
+			return fmt.Errorf("el mapeo XML tiene un campo o una ruta vacíos")
+		}
+	}
+	return nil
 }
@@ -1,34 +1,180 @@
 package entities
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// ToleranciaTotal es la diferencia máxima admitida entre el total informado por
+// el origen y el calculado, para absorber los redondeos del sistema de origen
+const ToleranciaTotal = 0.01
+
+// Tipos de las líneas de impuestos de una venta. El IVA y las percepciones se
+// suman al total; las retenciones las practica el cliente y se descuentan de lo
+// que se cobra, sin cambiar el total.
+const (
+	ImpuestoIVA        = "iva"
+	ImpuestoPercepcion = "percepcion"
+	ImpuestoRetencion  = "retencion"
+)
+
+// Estados de una venta
+const (
+	EstadoVentaPendiente       = "pendiente"
+	EstadoVentaCompletada      = "completada"
+	EstadoVentaAnulada         = "anulada"
+	EstadoVentaDevueltaParcial = "devuelta_parcial"
+	EstadoVentaDevuelta        = "devuelta"
+)
+
+// EstadosVenta enumera los estados posibles de una venta
+var EstadosVenta = []string{
+	EstadoVentaPendiente, EstadoVentaCompletada, EstadoVentaAnulada,
+	EstadoVentaDevueltaParcial, EstadoVentaDevuelta,
+}
+
+// EstadosVentaIniciales son los estados con que puede registrarse una venta;
+// las devoluciones sólo se alcanzan con una transición
+var EstadosVentaIniciales = []string{
+	EstadoVentaPendiente, EstadoVentaCompletada, EstadoVentaAnulada,
+}
+
+// transicionesVenta indica a qué estados puede pasar una venta. Anulada y
+// devuelta son finales; una devolución parcial admite nuevas devoluciones
+var transicionesVenta = map[string][]string{
+	EstadoVentaPendiente:       {EstadoVentaCompletada, EstadoVentaAnulada},
+	EstadoVentaCompletada:      {EstadoVentaAnulada, EstadoVentaDevueltaParcial, EstadoVentaDevuelta},
+	EstadoVentaDevueltaParcial: {EstadoVentaDevueltaParcial, EstadoVentaDevuelta},
+}
+
 // Venta representa una venta en el sistema
 type Venta struct {
-	SucursalID    uint           `json:"sucursal_id"`
-	ClienteID     uint           `json:"cliente_id"`
-	FechaVenta    time.Time      `json:"fecha_venta"`
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	SucursalID    uint           `json:"sucursal_id" gorm:"not null;index:idx_ventas_sucursal_fecha"`
+	Sucursal      *Sucursal      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ClienteID     uint           `json:"cliente_id" gorm:"index"`
+	FechaVenta    time.Time      `json:"fecha_venta" gorm:"index:idx_ventas_sucursal_fecha"`
 	Total         float64        `json:"total"`
 	Subtotal      float64        `json:"subtotal"`
-	Impuestos     float64        `json:"impuestos"`
+	Impuestos     float64        `json:"impuestos"`   // IVA y percepciones
+	Retenciones   float64        `json:"retenciones"` // retenciones practicadas por el cliente
 	Descuento     float64        `json:"descuento"`
-	Estado        string         `json:"estado"`
-	MetodoPago    string         `json:"metodo_pago"`
-	DetallesVenta []DetalleVenta `json:"detalles_venta"`
+	Estado        string         `json:"estado" gorm:"size:20;index"`
+	MetodoPago    string         `json:"metodo_pago" gorm:"size:30"`
+	DetallesVenta []DetalleVenta `json:"detalles_venta" gorm:"foreignKey:VentaID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// Desglose de impuestos: el IVA por alícuota y las percepciones y retenciones
+	ImpuestosVenta []ImpuestoVenta `json:"impuestos_venta" gorm:"foreignKey:VentaID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName define el nombre de la tabla de ventas
+func (Venta) TableName() string {
+	return "ventas"
 }
 
-// CalcularTotal calcula el total de la venta
+// CalcularTotal calcula el IVA de cada detalle según su alícuota, neto de su
+// parte del descuento general, arma el desglose del IVA por alícuota
+// conservando las percepciones y retenciones, y calcula los totales de la venta
 func (v *Venta) CalcularTotal() {
 	v.Subtotal = 0
-	for _, detalle := range v.DetallesVenta {
+	var desglose []ImpuestoVenta
+	porConcepto := make(map[string]int)
+	descuentos := v.descuentosProrrateados()
+	for i := range v.DetallesVenta {
+		detalle := &v.DetallesVenta[i]
+		detalle.CalcularIVA(descuentos[i])
 		v.Subtotal += detalle.Total
+
+		concepto := detalle.ConceptoIVA()
+		j, existe := porConcepto[concepto]
+		if !existe {
+			j = len(desglose)
+			porConcepto[concepto] = j
+			desglose = append(desglose, ImpuestoVenta{
+				Tipo:        ImpuestoIVA,
+				Concepto:    concepto,
+				Descripcion: detalle.DescripcionIVA(),
+				Alicuota:    detalle.AlicuotaIVA,
+			})
+		}
+		desglose[j].BaseImponible += detalle.Total - descuentos[i]
+		desglose[j].Importe += detalle.IVA
 	}
+	for _, impuesto := range v.ImpuestosVenta {
+		if impuesto.Tipo != ImpuestoIVA {
+			desglose = append(desglose, impuesto)
+		}
+	}
+	v.ImpuestosVenta = desglose
 
-	v.Impuestos = v.Subtotal * 0.21 // 21% IVA
+	v.Impuestos, v.Retenciones = 0, 0
+	for _, impuesto := range v.ImpuestosVenta {
+		if impuesto.Tipo == ImpuestoRetencion {
+			v.Retenciones += impuesto.Importe
+		} else {
+			v.Impuestos += impuesto.Importe
+		}
+	}
 	v.Total = v.Subtotal + v.Impuestos - v.Descuento
 }
 
+// NetoGravado retorna la suma de los detalles alcanzados por el IVA, neta de su
+// parte del descuento general
+func (v *Venta) NetoGravado() float64 {
+	neto := 0.0
+	descuentos := v.descuentosProrrateados()
+	for i, detalle := range v.DetallesVenta {
+		if !detalle.ExentoIVA {
+			neto += detalle.Total - descuentos[i]
+		}
+	}
+	return neto
+}
+
+// descuentosProrrateados reparte el descuento general de la venta entre sus
+// detalles en proporción al total de cada uno
+func (v *Venta) descuentosProrrateados() []float64 {
+	descuentos := make([]float64, len(v.DetallesVenta))
+	subtotal := 0.0
+	for _, detalle := range v.DetallesVenta {
+		subtotal += detalle.Total
+	}
+	if v.Descuento == 0 || subtotal <= 0 {
+		return descuentos
+	}
+	for i, detalle := range v.DetallesVenta {
+		descuentos[i] = v.Descuento * detalle.Total / subtotal
+	}
+	return descuentos
+}
+
+// AgregarPercepcion agrega una percepción o retención al desglose de impuestos
+// de la venta y recalcula sus totales
+func (v *Venta) AgregarPercepcion(impuesto ImpuestoVenta) {
+	v.ImpuestosVenta = append(v.ImpuestosVenta, impuesto)
+	v.CalcularTotal()
+}
+
+// QuitarPercepciones quita las percepciones y retenciones del desglose de
+// impuestos, para volver a determinarlas
+func (v *Venta) QuitarPercepciones() {
+	var iva []ImpuestoVenta
+	for _, impuesto := range v.ImpuestosVenta {
+		if impuesto.Tipo == ImpuestoIVA {
+			iva = append(iva, impuesto)
+		}
+	}
+	v.ImpuestosVenta = iva
+	v.CalcularTotal()
+}
+
+// CobroNeto retorna lo que se cobra de la venta, descontadas las retenciones
+func (v *Venta) CobroNeto() float64 {
+	return v.Total - v.Retenciones
+}
+
 // AgregarDetalle agrega un detalle a la venta
 func (v *Venta) AgregarDetalle(detalle DetalleVenta) {
 	v.DetallesVenta = append(v.DetallesVenta, detalle)
@@ -40,20 +186,165 @@ func (v *Venta) EsValida() bool {
 	return v.SucursalID > 0 && !v.FechaVenta.IsZero() && len(v.DetallesVenta) > 0
 }
 
-// ActualizarEstado actualiza el estado de la venta
-func (v *Venta) ActualizarEstado(nuevoEstado string) {
+// VincularDetalles asigna el ID de la venta a cada uno de sus detalles y de
+// sus líneas de impuestos
+func (v *Venta) VincularDetalles() {
+	for i := range v.DetallesVenta {
+		v.DetallesVenta[i].VentaID = v.ID
+	}
+	for i := range v.ImpuestosVenta {
+		v.ImpuestosVenta[i].VentaID = v.ID
+	}
+}
+
+// CoincideTotal verifica si el total calculado coincide con el esperado dentro de ToleranciaTotal
+func (v *Venta) CoincideTotal(esperado float64) bool {
+	return math.Abs(v.Total-esperado) <= ToleranciaTotal
+}
+
+// EsEstadoVenta verifica si el estado es uno de los de una venta
+func EsEstadoVenta(estado string) bool {
+	return contieneEstado(EstadosVenta, estado)
+}
+
+// EsEstadoVentaInicial verifica si una venta puede registrarse con el estado indicado
+func EsEstadoVentaInicial(estado string) bool {
+	return contieneEstado(EstadosVentaIniciales, estado)
+}
+
+func contieneEstado(estados []string, estado string) bool {
+	for _, valido := range estados {
+		if estado == valido {
+			return true
+		}
+	}
+	return false
+}
+
+// TransicionesPosibles retorna los estados a los que puede pasar la venta
+func (v *Venta) TransicionesPosibles() []string {
+	return transicionesVenta[v.Estado]
+}
+
+// PuedePasarA verifica si la venta puede pasar al estado indicado
+func (v *Venta) PuedePasarA(estado string) bool {
+	return contieneEstado(transicionesVenta[v.Estado], estado)
+}
+
+// ActualizarEstado pasa la venta al estado indicado si la tabla de
+// transiciones lo admite
+func (v *Venta) ActualizarEstado(nuevoEstado string) error {
+	if !v.PuedePasarA(nuevoEstado) {
+		if len(v.TransicionesPosibles()) == 0 {
+			return fmt.Errorf("la venta está %s y no admite cambios de estado", v.Estado)
+		}
+		return fmt.Errorf("no se puede pasar de %s a %q (se admite %s)", v.Estado, nuevoEstado, strings.Join(v.TransicionesPosibles(), ", "))
+	}
 	v.Estado = nuevoEstado
+	return nil
+}
+
+// Devolver registra la devolución de cantidad unidades del detalle indicado
+func (v *Venta) Devolver(detalleID uint, cantidad int) error {
+	for i := range v.DetallesVenta {
+		detalle := &v.DetallesVenta[i]
+		if detalle.ID != detalleID {
+			continue
+		}
+		if cantidad <= 0 {
+			return fmt.Errorf("la cantidad a devolver del detalle %d debe ser positiva", detalleID)
+		}
+		if cantidad > detalle.CantidadPendiente() {
+			return fmt.Errorf("el detalle %d tiene %d unidades sin devolver y se pidió devolver %d", detalleID, detalle.CantidadPendiente(), cantidad)
+		}
+		detalle.CantidadDevuelta += cantidad
+		return nil
+	}
+	return fmt.Errorf("la venta %d no tiene el detalle %d", v.ID, detalleID)
+}
+
+// TodoDevuelto verifica si se devolvieron todas las unidades de la venta
+func (v *Venta) TodoDevuelto() bool {
+	for _, detalle := range v.DetallesVenta {
+		if detalle.CantidadPendiente() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TotalNeto retorna el total de la venta descontando la parte devuelta, en
+// proporción al subtotal de las líneas devueltas
+func (v *Venta) TotalNeto() float64 {
+	if v.Subtotal == 0 {
+		return v.Total
+	}
+	neto := 0.0
+	for _, detalle := range v.DetallesVenta {
+		neto += detalle.TotalNeto()
+	}
+	return v.Total * neto / v.Subtotal
+}
+
+// ReferenciaStock es la referencia de los movimientos de stock de la venta
+func (v *Venta) ReferenciaStock() string {
+	return fmt.Sprintf("venta %d", v.ID)
+}
+
+// ImpuestoVenta es una línea del desglose de impuestos de una venta: el IVA de
+// una alícuota o una percepción o retención
+type ImpuestoVenta struct {
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	VentaID       uint    `json:"venta_id" gorm:"not null;index"`
+	Tipo          string  `json:"tipo" gorm:"size:20;not null"`     // 'iva', 'percepcion', 'retencion'
+	Concepto      string  `json:"concepto" gorm:"size:50;not null"` // 'iva_21', 'iva_exento', 'percepcion_iibb_caba', etc.
+	Descripcion   string  `json:"descripcion" gorm:"size:100"`
+	Alicuota      float64 `json:"alicuota"` // porcentaje; 0 en el IVA exento
+	BaseImponible float64 `json:"base_imponible"`
+	Importe       float64 `json:"importe"`
+}
+
+// TableName define el nombre de la tabla del desglose de impuestos de las ventas
+func (ImpuestoVenta) TableName() string {
+	return "impuestos_venta"
+}
+
+// TransicionVenta registra un cambio de estado de una venta
+type TransicionVenta struct {
+	ID      uint      `json:"id" gorm:"primaryKey"`
+	VentaID uint      `json:"venta_id" gorm:"not null;index"`
+	Venta   *Venta    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Desde   string    `json:"desde" gorm:"size:20;not null"`
+	Hasta   string    `json:"hasta" gorm:"size:20;not null;index"`
+	Usuario string    `json:"usuario" gorm:"size:100;not null"`
+	Motivo  string    `json:"motivo,omitempty" gorm:"size:500"`
+	Fecha   time.Time `json:"fecha" gorm:"not null;index"`
+}
+
+// TableName define el nombre de la tabla del historial de estados de las ventas
+func (TransicionVenta) TableName() string {
+	return "transiciones_ventas"
 }
 
 // DetalleVenta representa un detalle de venta
 type DetalleVenta struct {
-	VentaID        uint     `json:"venta_id"`
-	ProductoID     uint     `json:"producto_id"`
-	Producto       Producto `json:"producto"`
-	Cantidad       int      `json:"cantidad"`
-	PrecioUnitario float64  `json:"precio_unitario"`
-	Total          float64  `json:"total"`
-	Descuento      float64  `json:"descuento"`
+	ID               uint     `json:"id" gorm:"primaryKey"`
+	VentaID          uint     `json:"venta_id" gorm:"not null;index"`
+	ProductoID       uint     `json:"producto_id" gorm:"not null;index"`
+	Producto         Producto `json:"producto" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Cantidad         int      `json:"cantidad"`
+	PrecioUnitario   float64  `json:"precio_unitario"`
+	Total            float64  `json:"total"`
+	Descuento        float64  `json:"descuento"`
+	CantidadDevuelta int      `json:"cantidad_devuelta"` // unidades devueltas, a lo sumo Cantidad
+	AlicuotaIVA      float64  `json:"alicuota_iva"`      // porcentaje: 21, 10.5 o 27
+	ExentoIVA        bool     `json:"exento_iva"`
+	IVA              float64  `json:"iva"`
+}
+
+// TableName define el nombre de la tabla de detalles de venta
+func (DetalleVenta) TableName() string {
+	return "detalles_venta"
 }
 
 // CalcularTotal calcula el total del detalle de venta
@@ -61,7 +352,52 @@ func (d *DetalleVenta) CalcularTotal() {
 	d.Total = (d.PrecioUnitario * float64(d.Cantidad)) - d.Descuento
 }
 
+// CalcularIVA calcula el IVA del detalle según su alícuota sobre su total,
+// descontada su parte del descuento general de la venta
+func (d *DetalleVenta) CalcularIVA(descuentoGeneral float64) {
+	if d.ExentoIVA {
+		d.IVA = 0
+		return
+	}
+	d.IVA = (d.Total - descuentoGeneral) * d.AlicuotaIVA / 100
+}
+
+// ConceptoIVA identifica la alícuota del detalle en el desglose de impuestos
+func (d *DetalleVenta) ConceptoIVA() string {
+	if d.ExentoIVA {
+		return "iva_exento"
+	}
+	return "iva_" + strconv.FormatFloat(d.AlicuotaIVA, 'f', -1, 64)
+}
+
+// DescripcionIVA describe la alícuota del detalle en el desglose de impuestos
+func (d *DetalleVenta) DescripcionIVA() string {
+	if d.ExentoIVA {
+		return "IVA exento"
+	}
+	return "IVA " + strconv.FormatFloat(d.AlicuotaIVA, 'f', -1, 64) + "%"
+}
+
+// CantidadPendiente retorna las unidades del detalle que no se devolvieron
+func (d *DetalleVenta) CantidadPendiente() int {
+	return d.Cantidad - d.CantidadDevuelta
+}
+
+// TotalNeto retorna el total del detalle descontando las unidades devueltas
+func (d *DetalleVenta) TotalNeto() float64 {
+	if d.Cantidad == 0 {
+		return 0
+	}
+	return d.Total * float64(d.CantidadPendiente()) / float64(d.Cantidad)
+}
+
 // EsValido verifica si el detalle de venta tiene los datos mínimos requeridos
 func (d *DetalleVenta) EsValido() bool {
-	return d.VentaID > 0 && d.ProductoID > 0 && d.Cantidad > 0 && d.PrecioUnitario >= 0
+	return d.VentaID > 0 && d.EsLineaValida()
+}
+
+// EsLineaValida verifica los datos propios de la línea, sin exigir la venta, que
+// recién tiene ID al persistirse
+func (d *DetalleVenta) EsLineaValida() bool {
+	return d.ProductoID > 0 && d.Cantidad > 0 && d.PrecioUnitario >= 0
 }
@@ -0,0 +1,254 @@
+package filtros
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expresion es un filtro compilado que se evalúa sobre un registro. El lenguaje
+// admite campos del registro, literales (números, textos, true, false, null),
+// listas, comparaciones (==, !=, <, <=, >, >=, in, not in), los operadores
+// lógicos &&, || y ! y paréntesis. Por ejemplo:
+//
+//	estado != "anulada" && total > 0
+//	categoria in ["a", "b"]
+type Expresion struct {
+	fuente string
+	raiz   nodo
+}
+
+// Compilar interpreta el texto de un filtro y retorna la expresión lista para evaluar
+func Compilar(fuente string) (*Expresion, error) {
+	if strings.TrimSpace(fuente) == "" {
+		return nil, fmt.Errorf("el filtro está vacío")
+	}
+
+	tokens, err := tokenizar(fuente)
+	if err != nil {
+		return nil, fmt.Errorf("filtro %q: %v", fuente, err)
+	}
+
+	p := &parser{tokens: tokens}
+	raiz, err := p.parsearO()
+	if err != nil {
+		return nil, fmt.Errorf("filtro %q: %v", fuente, err)
+	}
+
+	if t := p.siguiente(); t.tipo != tokenFin {
+		return nil, fmt.Errorf("filtro %q: %v", fuente, p.errorEn(t, fmt.Sprintf("token inesperado '%s'", t.texto)))
+	}
+
+	return &Expresion{fuente: fuente, raiz: raiz}, nil
+}
+
+// CompilarTodos compila una lista de filtros, reportando el error de cada uno inválido
+func CompilarTodos(fuentes []string) ([]*Expresion, []error) {
+	expresiones := make([]*Expresion, 0, len(fuentes))
+	var errores []error
+
+	for _, fuente := range fuentes {
+		expresion, err := Compilar(fuente)
+		if err != nil {
+			errores = append(errores, err)
+			continue
+		}
+		expresiones = append(expresiones, expresion)
+	}
+
+	return expresiones, errores
+}
+
+// Evaluar indica si el registro cumple la expresión
+func (e *Expresion) Evaluar(registro map[string]interface{}) bool {
+	return esVerdadero(e.raiz.evaluar(registro))
+}
+
+// String retorna el texto original del filtro
+func (e *Expresion) String() string {
+	return e.fuente
+}
+
+// nodo es un elemento del árbol de una expresión
+type nodo interface {
+	evaluar(registro map[string]interface{}) interface{}
+}
+
+type nodoLiteral struct {
+	valor interface{}
+}
+
+func (n *nodoLiteral) evaluar(registro map[string]interface{}) interface{} {
+	return n.valor
+}
+
+type nodoCampo struct {
+	nombre string
+}
+
+// evaluar obtiene el valor del campo; los campos ausentes valen null
+func (n *nodoCampo) evaluar(registro map[string]interface{}) interface{} {
+	valor, existe := registro[n.nombre]
+	if !existe {
+		valor = registro[strings.ToLower(n.nombre)]
+	}
+	return normalizarValor(valor)
+}
+
+type nodoLista struct {
+	elementos []nodo
+}
+
+func (n *nodoLista) evaluar(registro map[string]interface{}) interface{} {
+	valores := make([]interface{}, len(n.elementos))
+	for i, elemento := range n.elementos {
+		valores[i] = elemento.evaluar(registro)
+	}
+	return valores
+}
+
+type nodoNegacion struct {
+	operando nodo
+}
+
+func (n *nodoNegacion) evaluar(registro map[string]interface{}) interface{} {
+	return !esVerdadero(n.operando.evaluar(registro))
+}
+
+type nodoLogico struct {
+	operador  string
+	izquierdo nodo
+	derecho   nodo
+}
+
+func (n *nodoLogico) evaluar(registro map[string]interface{}) interface{} {
+	izquierdo := esVerdadero(n.izquierdo.evaluar(registro))
+	if n.operador == "&&" {
+		return izquierdo && esVerdadero(n.derecho.evaluar(registro))
+	}
+	return izquierdo || esVerdadero(n.derecho.evaluar(registro))
+}
+
+type nodoComparacion struct {
+	operador  string
+	izquierdo nodo
+	derecho   nodo
+}
+
+// evaluar compara los operandos. Los números se comparan con números y los
+// textos con textos; una comparación de orden entre tipos distintos es falsa.
+func (n *nodoComparacion) evaluar(registro map[string]interface{}) interface{} {
+	izquierdo := n.izquierdo.evaluar(registro)
+	derecho := n.derecho.evaluar(registro)
+
+	switch n.operador {
+	case "==":
+		return sonIguales(izquierdo, derecho)
+	case "!=":
+		return !sonIguales(izquierdo, derecho)
+	case "in", "not in":
+		lista, _ := derecho.([]interface{})
+		contenido := false
+		for _, elemento := range lista {
+			if sonIguales(izquierdo, elemento) {
+				contenido = true
+				break
+			}
+		}
+		return contenido == (n.operador == "in")
+	}
+
+	comparacion, comparables := comparar(izquierdo, derecho)
+	if !comparables {
+		return false
+	}
+
+	switch n.operador {
+	case "<":
+		return comparacion < 0
+	case "<=":
+		return comparacion <= 0
+	case ">":
+		return comparacion > 0
+	case ">=":
+		return comparacion >= 0
+	}
+	return false
+}
+
+// normalizarValor convierte los tipos numéricos a float64 para compararlos entre sí
+func normalizarValor(valor interface{}) interface{} {
+	switch v := valor.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return valor
+}
+
+func sonIguales(a, b interface{}) bool {
+	if comparacion, comparables := comparar(a, b); comparables {
+		return comparacion == 0
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ba, ok := a.(bool); ok {
+		bb, ok := b.(bool)
+		return ok && ba == bb
+	}
+	return false
+}
+
+// comparar retorna -1, 0 o 1 si ambos valores son números o ambos son textos
+func comparar(a, b interface{}) (int, bool) {
+	switch va := a.(type) {
+	case float64:
+		vb, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case va < vb:
+			return -1, true
+		case va > vb:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		vb, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(va, vb), true
+	}
+	return 0, false
+}
+
+// esVerdadero interpreta un valor en contexto lógico: null, false, 0 y el
+// texto vacío son falsos
+func esVerdadero(valor interface{}) bool {
+	switch v := valor.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
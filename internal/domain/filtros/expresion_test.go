@@ -0,0 +1,112 @@
+package filtros
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompilarRechazaExpresionesInvalidas(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		fuente  string
+		mensaje string
+	}{
+		{"vacía", "   ", "el filtro está vacío"},
+		{"texto sin cerrar", `estado == "anulada`, "texto sin cerrar"},
+		{"carácter inesperado", "total # 3", "carácter inesperado"},
+		{"número inválido", "total > 1.2.3", "número inválido"},
+		{"paréntesis sin cerrar", "(total > 0", "se esperaba ')'"},
+		{"lista sin cerrar", "categoria in ['a', 'b'", "se esperaba ',' o ']'"},
+		{"in sin lista", "categoria in 'a'", "requiere una lista"},
+		{"not sin in", "categoria not ['a']", "se esperaba 'in'"},
+		{"palabra reservada", "in == 1", "palabra reservada"},
+		{"termina incompleta", "total > 0 &&", "termina de forma inesperada"},
+		{"token sobrante", "total > 0 estado", "token inesperado 'estado'"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, err := Compilar(caso.fuente)
+			if err == nil {
+				t.Fatalf("Compilar(%q) no retornó error", caso.fuente)
+			}
+			if !strings.Contains(err.Error(), caso.mensaje) {
+				t.Errorf("Compilar(%q) = %q, se esperaba que contuviera %q", caso.fuente, err, caso.mensaje)
+			}
+		})
+	}
+}
+
+func TestEvaluar(t *testing.T) {
+	registro := map[string]interface{}{
+		"estado":    "completada",
+		"total":     150.5,
+		"cantidad":  3,
+		"categoria": "bebidas",
+		"activo":    true,
+		"notas":     "",
+		"Sucursal":  uint(2),
+	}
+
+	casos := []struct {
+		fuente   string
+		esperado bool
+	}{
+		// comparaciones de números, incluidos enteros del registro
+		{"total > 100", true},
+		{"total <= 150.5", true},
+		{"total < -1", false},
+		{"cantidad == 3", true},
+		{"cantidad >= 4", false},
+		{"Sucursal == 2", true},
+		// comparaciones de textos
+		{`estado == "completada"`, true},
+		{`estado != 'anulada'`, true},
+		{`categoria < "carnes"`, true},
+		// tipos distintos no se ordenan ni son iguales
+		{`total > "100"`, false},
+		{`cantidad == "3"`, false},
+		// listas
+		{`categoria in ["bebidas", "lacteos"]`, true},
+		{`categoria not in ["bebidas", "lacteos"]`, false},
+		{"cantidad in [1, 2, 3]", true},
+		{"cantidad in []", false},
+		// campos ausentes valen null
+		{"descuento == null", true},
+		{"descuento > 0", false},
+		{"!descuento", true},
+		// valores en contexto lógico
+		{"activo", true},
+		{"activo == true", true},
+		{"notas", false},
+		{"!notas", true},
+		// operadores lógicos y precedencia: && antes que ||
+		{`estado == "anulada" || total > 100 && cantidad == 3`, true},
+		{`(estado == "anulada" || total > 100) && cantidad == 4`, false},
+		{`!(estado == "anulada") && activo`, true},
+		{`estado == "anulada" || !activo`, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.fuente, func(t *testing.T) {
+			expresion, err := Compilar(caso.fuente)
+			if err != nil {
+				t.Fatalf("Compilar(%q): %v", caso.fuente, err)
+			}
+			if obtenido := expresion.Evaluar(registro); obtenido != caso.esperado {
+				t.Errorf("Evaluar(%q) = %v, se esperaba %v", caso.fuente, obtenido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestCompilarTodosReportaCadaFiltroInvalido(t *testing.T) {
+	expresiones, errores := CompilarTodos([]string{"total > 0", "total >", `estado == "x"`, "(("})
+
+	if len(expresiones) != 2 {
+		t.Errorf("se compilaron %d expresiones, se esperaban 2", len(expresiones))
+	}
+	if len(errores) != 2 {
+		t.Errorf("se reportaron %d errores, se esperaban 2", len(errores))
+	}
+}
@@ -0,0 +1,133 @@
+package filtros
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tipoToken clasifica los tokens del lenguaje de filtros
+type tipoToken int
+
+const (
+	tokenFin tipoToken = iota
+	tokenIdentificador
+	tokenNumero
+	tokenTexto
+	tokenOperador
+	tokenParentesisAbre
+	tokenParentesisCierra
+	tokenCorcheteAbre
+	tokenCorcheteCierra
+	tokenComa
+)
+
+// token es una unidad léxica de una expresión
+type token struct {
+	tipo     tipoToken
+	texto    string
+	numero   float64
+	posicion int
+}
+
+// operadores reconocidos, de mayor a menor longitud para preferir "<=" sobre "<"
+var operadores = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!"}
+
+// tokenizar divide la expresión en tokens
+func tokenizar(expresion string) ([]token, error) {
+	var tokens []token
+	runas := []rune(expresion)
+
+	for i := 0; i < len(runas); {
+		r := runas[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{tipo: tokenParentesisAbre, texto: "(", posicion: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tipo: tokenParentesisCierra, texto: ")", posicion: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{tipo: tokenCorcheteAbre, texto: "[", posicion: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{tipo: tokenCorcheteCierra, texto: "]", posicion: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tipo: tokenComa, texto: ",", posicion: i})
+			i++
+
+		case r == '"' || r == '\'':
+			texto, fin, err := leerTexto(runas, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tipo: tokenTexto, texto: texto, posicion: i})
+			i = fin
+
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runas) && unicode.IsDigit(runas[i+1])):
+			inicio := i
+			i++
+			for i < len(runas) && (unicode.IsDigit(runas[i]) || runas[i] == '.') {
+				i++
+			}
+			literal := string(runas[inicio:i])
+			numero, err := strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return nil, fmt.Errorf("número inválido %q en la posición %d", literal, inicio+1)
+			}
+			tokens = append(tokens, token{tipo: tokenNumero, texto: literal, numero: numero, posicion: inicio})
+
+		case unicode.IsLetter(r) || r == '_':
+			inicio := i
+			for i < len(runas) && (unicode.IsLetter(runas[i]) || unicode.IsDigit(runas[i]) || runas[i] == '_' || runas[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tipo: tokenIdentificador, texto: string(runas[inicio:i]), posicion: inicio})
+
+		default:
+			operador := ""
+			for _, op := range operadores {
+				if strings.HasPrefix(string(runas[i:]), op) {
+					operador = op
+					break
+				}
+			}
+			if operador == "" {
+				return nil, fmt.Errorf("carácter inesperado %q en la posición %d", r, i+1)
+			}
+			tokens = append(tokens, token{tipo: tokenOperador, texto: operador, posicion: i})
+			i += len([]rune(operador))
+		}
+	}
+
+	return append(tokens, token{tipo: tokenFin, posicion: len(runas)}), nil
+}
+
+// leerTexto lee un literal de texto entre comillas simples o dobles, admitiendo
+// el escape de la comilla y de la barra invertida
+func leerTexto(runas []rune, inicio int) (string, int, error) {
+	comilla := runas[inicio]
+	var texto strings.Builder
+
+	for i := inicio + 1; i < len(runas); i++ {
+		switch runas[i] {
+		case '\\':
+			if i+1 < len(runas) {
+				i++
+				texto.WriteRune(runas[i])
+			}
+		case comilla:
+			return texto.String(), i + 1, nil
+		default:
+			texto.WriteRune(runas[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("texto sin cerrar iniciado en la posición %d", inicio+1)
+}
@@ -0,0 +1,205 @@
+package filtros
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parser construye el árbol de una expresión por descenso recursivo:
+//
+//	o           := y ( "||" y )*
+//	y           := negacion ( "&&" negacion )*
+//	negacion    := "!" negacion | comparacion
+//	comparacion := primario ( ("==" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "not in") primario )?
+//	primario    := numero | texto | true | false | null | campo | lista | "(" o ")"
+//	lista       := "[" ( o ( "," o )* )? "]"
+type parser struct {
+	tokens []token
+	actual int
+}
+
+func (p *parser) siguiente() token {
+	return p.tokens[p.actual]
+}
+
+func (p *parser) avanzar() token {
+	t := p.tokens[p.actual]
+	if t.tipo != tokenFin {
+		p.actual++
+	}
+	return t
+}
+
+func (p *parser) esOperador(texto string) bool {
+	t := p.siguiente()
+	return t.tipo == tokenOperador && t.texto == texto
+}
+
+func (p *parser) esPalabra(palabra string) bool {
+	t := p.siguiente()
+	return t.tipo == tokenIdentificador && strings.EqualFold(t.texto, palabra)
+}
+
+func (p *parser) errorEn(t token, mensaje string) error {
+	return fmt.Errorf("%s en la posición %d", mensaje, t.posicion+1)
+}
+
+func (p *parser) parsearO() (nodo, error) {
+	izquierdo, err := p.parsearY()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.esOperador("||") {
+		p.avanzar()
+		derecho, err := p.parsearY()
+		if err != nil {
+			return nil, err
+		}
+		izquierdo = &nodoLogico{operador: "||", izquierdo: izquierdo, derecho: derecho}
+	}
+
+	return izquierdo, nil
+}
+
+func (p *parser) parsearY() (nodo, error) {
+	izquierdo, err := p.parsearNegacion()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.esOperador("&&") {
+		p.avanzar()
+		derecho, err := p.parsearNegacion()
+		if err != nil {
+			return nil, err
+		}
+		izquierdo = &nodoLogico{operador: "&&", izquierdo: izquierdo, derecho: derecho}
+	}
+
+	return izquierdo, nil
+}
+
+func (p *parser) parsearNegacion() (nodo, error) {
+	if p.esOperador("!") {
+		p.avanzar()
+		operando, err := p.parsearNegacion()
+		if err != nil {
+			return nil, err
+		}
+		return &nodoNegacion{operando: operando}, nil
+	}
+	return p.parsearComparacion()
+}
+
+func (p *parser) parsearComparacion() (nodo, error) {
+	izquierdo, err := p.parsearPrimario()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.siguiente()
+	operador := ""
+	switch {
+	case t.tipo == tokenOperador && esComparador(t.texto):
+		operador = t.texto
+		p.avanzar()
+	case p.esPalabra("in"):
+		operador = "in"
+		p.avanzar()
+	case p.esPalabra("not"):
+		p.avanzar()
+		if !p.esPalabra("in") {
+			return nil, p.errorEn(p.siguiente(), "se esperaba 'in' después de 'not'")
+		}
+		p.avanzar()
+		operador = "not in"
+	default:
+		return izquierdo, nil
+	}
+
+	derecho, err := p.parsearPrimario()
+	if err != nil {
+		return nil, err
+	}
+
+	if operador == "in" || operador == "not in" {
+		if _, esLista := derecho.(*nodoLista); !esLista {
+			return nil, p.errorEn(t, fmt.Sprintf("el operador '%s' requiere una lista a la derecha", operador))
+		}
+	}
+
+	return &nodoComparacion{operador: operador, izquierdo: izquierdo, derecho: derecho}, nil
+}
+
+func (p *parser) parsearPrimario() (nodo, error) {
+	t := p.avanzar()
+
+	switch t.tipo {
+	case tokenNumero:
+		return &nodoLiteral{valor: t.numero}, nil
+	case tokenTexto:
+		return &nodoLiteral{valor: t.texto}, nil
+
+	case tokenIdentificador:
+		switch strings.ToLower(t.texto) {
+		case "true":
+			return &nodoLiteral{valor: true}, nil
+		case "false":
+			return &nodoLiteral{valor: false}, nil
+		case "null":
+			return &nodoLiteral{valor: nil}, nil
+		case "in", "not":
+			return nil, p.errorEn(t, fmt.Sprintf("palabra reservada '%s' inesperada", t.texto))
+		}
+		return &nodoCampo{nombre: t.texto}, nil
+
+	case tokenParentesisAbre:
+		interno, err := p.parsearO()
+		if err != nil {
+			return nil, err
+		}
+		if p.siguiente().tipo != tokenParentesisCierra {
+			return nil, p.errorEn(p.siguiente(), "se esperaba ')'")
+		}
+		p.avanzar()
+		return interno, nil
+
+	case tokenCorcheteAbre:
+		lista := &nodoLista{}
+		if p.siguiente().tipo == tokenCorcheteCierra {
+			p.avanzar()
+			return lista, nil
+		}
+		for {
+			elemento, err := p.parsearO()
+			if err != nil {
+				return nil, err
+			}
+			lista.elementos = append(lista.elementos, elemento)
+
+			if p.siguiente().tipo == tokenComa {
+				p.avanzar()
+				continue
+			}
+			if p.siguiente().tipo != tokenCorcheteCierra {
+				return nil, p.errorEn(p.siguiente(), "se esperaba ',' o ']'")
+			}
+			p.avanzar()
+			return lista, nil
+		}
+
+	case tokenFin:
+		return nil, p.errorEn(t, "la expresión termina de forma inesperada")
+	}
+
+	return nil, p.errorEn(t, fmt.Sprintf("token inesperado '%s'", t.texto))
+}
+
+func esComparador(operador string) bool {
+	switch operador {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// Órdenes del listado de clientes potenciales
+const (
+	OrdenClientesCaptura = "fecha_captura" // del captado más recientemente al más antiguo
+	OrdenClientesPuntaje = "puntaje"       // del de mayor puntaje al de menor
+)
+
+// FiltroClientes selecciona clientes potenciales; los campos vacíos no filtran
+type FiltroClientes struct {
+	SucursalID uint
+	Fuente     string
+	Estado     string
+
+	// Orden de los resultados; vacío ordena por OrdenClientesCaptura
+	Orden string
+
+	// Página de resultados, desde 0
+	Desplazamiento int
+	Limite         int
+}
+
+// ClientePotencialRepository define el acceso a los clientes potenciales
+type ClientePotencialRepository interface {
+	ObtenerPorID(ctx context.Context, id uint) (*entities.ClientePotencial, error)
+	// Listar retorna la página de clientes que cumplen el filtro y el total de
+	// clientes que lo cumplen
+	Listar(ctx context.Context, filtro FiltroClientes) ([]entities.ClientePotencial, int64, error)
+	// Guardar crea el cliente si no tiene ID o reemplaza el existente
+	Guardar(ctx context.Context, cliente *entities.ClientePotencial) error
+	Eliminar(ctx context.Context, id uint) error
+	// ActualizarDatos guarda los datos editables del cliente sin modificar su
+	// estado, puntaje ni conversión, si no fue anonimizado; si no, retorna
+	// ErrModificacionConcurrente
+	ActualizarDatos(ctx context.Context, cliente *entities.ClientePotencial) error
+	// CambiarEstado guarda el estado del cliente, quién y cuándo lo cambió y el
+	// cliente en que se convirtió, si el estado guardado sigue siendo desde; si
+	// no, retorna ErrModificacionConcurrente
+	CambiarEstado(ctx context.Context, cliente *entities.ClientePotencial, desde string) error
+	RegistrarTransicion(ctx context.Context, transicion *entities.TransicionCliente) error
+	// ListarTransiciones retorna el historial de estados del cliente, del más antiguo al más nuevo
+	ListarTransiciones(ctx context.Context, clienteID uint) ([]entities.TransicionCliente, error)
+	// GuardarPuntaje guarda el puntaje del cliente y los factores que lo explican
+	GuardarPuntaje(ctx context.Context, id uint, puntaje float64, factores []entities.FactorPuntaje, fecha time.Time) error
+	// ListarActivos retorna hasta limite clientes que no están convertidos ni
+	// descartados con ID mayor a despuesDeID, ordenados por ID
+	ListarActivos(ctx context.Context, despuesDeID uint, limite int) ([]entities.ClientePotencial, error)
+	RegistrarInteraccion(ctx context.Context, interaccion *entities.InteraccionCliente) error
+	// ListarInteracciones retorna las interacciones del cliente, de la más antigua a la más nueva
+	ListarInteracciones(ctx context.Context, clienteID uint) ([]entities.InteraccionCliente, error)
+	// AnonimizarAnteriores anonimiza hasta limite clientes captados antes de la
+	// fecha que no estén anonimizados y retorna cuántos anonimizó
+	AnonimizarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ClienteRepository define el acceso a los clientes
+type ClienteRepository interface {
+	ObtenerPorID(ctx context.Context, id uint) (*entities.Cliente, error)
+	// Guardar crea el cliente si no tiene ID o reemplaza el existente
+	Guardar(ctx context.Context, cliente *entities.Cliente) error
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// FiltroLotesArchivados selecciona lotes archivados; los campos vacíos no filtran
+type FiltroLotesArchivados struct {
+	LoteIDs    []string
+	SucursalID uint
+	Tipo       string
+	Desde      time.Time // recibidos desde, inclusive
+	Hasta      time.Time // recibidos hasta, exclusive
+}
+
+// LoteArchivadoRepository define el acceso al archivo de lotes crudos
+type LoteArchivadoRepository interface {
+	// Archivar guarda el contenido del lote, si no estaba guardado, y su
+	// descripción, completando Hash y los tamaños
+	Archivar(ctx context.Context, lote *entities.LoteArchivado, contenido []byte) error
+	RegistrarResultado(ctx context.Context, loteID string, resumen entities.ResumenProcesamiento, motivoError string, procesadoEn time.Time) error
+	ObtenerPorLoteID(ctx context.Context, loteID string) (*entities.LoteArchivado, error)
+	// Listar retorna los lotes que cumplen el filtro, del más antiguo al más nuevo
+	Listar(ctx context.Context, filtro FiltroLotesArchivados) ([]entities.LoteArchivado, error)
+	// LeerContenido retorna el contenido sin comprimir, verificando que coincida con su hash
+	LeerContenido(ctx context.Context, hash string) ([]byte, error)
+	// PurgarAnteriores elimina hasta limite lotes recibidos antes de la fecha, y
+	// el contenido que ningún otro lote comparte, y retorna cuántos eliminó
+	PurgarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error)
+}
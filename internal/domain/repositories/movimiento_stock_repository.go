@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// MovimientoStockRepository define el acceso al registro de movimientos de stock
+type MovimientoStockRepository interface {
+	// Registrar guarda los movimientos y aplica cada uno al stock de su producto
+	// en una única transacción, completando su StockResultante
+	Registrar(ctx context.Context, movimientos ...*entities.MovimientoStock) error
+	// ListarPorProducto retorna los movimientos del producto por fecha; con
+	// sucursalID distinto de cero, solo los de esa sucursal
+	ListarPorProducto(ctx context.Context, productoID, sucursalID uint) ([]entities.MovimientoStock, error)
+	// ListarPorReferencia retorna los movimientos con la referencia indicada, como
+	// los de una venta, ordenados por fecha
+	ListarPorReferencia(ctx context.Context, referencia string) ([]entities.MovimientoStock, error)
+	// SaldosPorSucursal retorna el stock de cada producto indicado en la sucursal
+	SaldosPorSucursal(ctx context.Context, sucursalID uint, productoIDs []uint) (map[uint]int, error)
+	// SaldosPorProducto retorna el stock del producto en cada sucursal
+	SaldosPorProducto(ctx context.Context, productoID uint) (map[uint]int, error)
+}
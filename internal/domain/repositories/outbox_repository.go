@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// OutboxRepository define el acceso al outbox de eventos de dominio
+type OutboxRepository interface {
+	// Agregar guarda los eventos como pendientes. Dentro de Transacciones.Ejecutar
+	// se guardan en la misma transacción que los cambios que los originan.
+	Agregar(ctx context.Context, eventos ...*entities.EventoOutbox) error
+	// Reclamar reserva para la instancia, hasta la fecha indicada, hasta limite
+	// eventos pendientes que nadie tiene reservados, del más antiguo al más
+	// nuevo, y los retorna. Dos procesos nunca reclaman el mismo evento a la vez.
+	Reclamar(ctx context.Context, instancia string, limite int, hasta time.Time) ([]entities.EventoOutbox, error)
+	MarcarEntregado(ctx context.Context, id uint, fecha time.Time) error
+	// RegistrarFallo cuenta un intento de entrega fallido y libera la reserva;
+	// el evento sigue pendiente
+	RegistrarFallo(ctx context.Context, id uint, motivo string) error
+	// Descartar cuenta el último intento de entrega fallido y aparta el evento,
+	// que deja de estar pendiente
+	Descartar(ctx context.Context, id uint, motivo string, fecha time.Time) error
+	// PurgarEntregados elimina hasta limite eventos entregados antes de la fecha
+	// y retorna cuántos eliminó. Los pendientes nunca se eliminan.
+	PurgarEntregados(ctx context.Context, antesDe time.Time, limite int) (int, error)
+}
+
+// Transacciones ejecuta operaciones de varios repositorios como una unidad
+type Transacciones interface {
+	// Ejecutar llama a fn con un contexto cuya transacción comparten los
+	// repositorios que lo reciben. Si fn retorna un error no se guarda ningún
+	// cambio. Dentro de otra transacción, fn se une a ella.
+	Ejecutar(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// PrecioHistoricoRepository define la consulta del historial de precios. Los
+// cambios los registra ProductoRepository al guardar cada producto.
+type PrecioHistoricoRepository interface {
+	// ObtenerVigente retorna el precio del producto vigente en la fecha indicada
+	ObtenerVigente(ctx context.Context, productoID uint, fecha time.Time) (*entities.PrecioHistorico, error)
+	ListarPorProducto(ctx context.Context, productoID uint) ([]entities.PrecioHistorico, error)
+}
+
+// OrigenCambio identifica quién originó un cambio guardado por un repositorio
+type OrigenCambio struct {
+	SucursalID uint
+	LoteID     string
+}
+
+type claveOrigenCambio struct{}
+
+// ConOrigenCambio retorna un contexto que informa el origen de los cambios que
+// se guarden con él
+func ConOrigenCambio(ctx context.Context, origen OrigenCambio) context.Context {
+	return context.WithValue(ctx, claveOrigenCambio{}, origen)
+}
+
+// OrigenCambioDe retorna el origen de los cambios informado en el contexto
+func OrigenCambioDe(ctx context.Context) OrigenCambio {
+	origen, _ := ctx.Value(claveOrigenCambio{}).(OrigenCambio)
+	return origen
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ProductoRepository define el acceso al catálogo de productos. Al guardar, cada
+// cambio de precio u oferta queda registrado en el historial de precios con el
+// origen informado por ConOrigenCambio. StockActual no se escribe: solo cambia
+// al registrar movimientos de stock, y un producto nuevo comienza sin stock.
+type ProductoRepository interface {
+	ObtenerPorID(ctx context.Context, id uint) (*entities.Producto, error)
+	ObtenerPorSKU(ctx context.Context, sku string) (*entities.Producto, error)
+	ListarPorSKUs(ctx context.Context, skus []string) ([]entities.Producto, error)
+	Listar(ctx context.Context) ([]entities.Producto, error)
+	Guardar(ctx context.Context, producto *entities.Producto) error
+	// GuardarLote crea o actualiza los productos en una única transacción: si
+	// falla alguno no se guarda ninguno
+	GuardarLote(ctx context.Context, productos []*entities.Producto) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// PurgaRepository define el acceso a la auditoría de purgas de datos
+type PurgaRepository interface {
+	Registrar(ctx context.Context, registro *entities.RegistroPurga) error
+	// Listar retorna hasta limite registros, del más reciente al más antiguo; con
+	// clase no vacía, solo los de esa clase
+	Listar(ctx context.Context, clase string, limite int) ([]entities.RegistroPurga, error)
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"errors"
+)
+
+// ErrNoEncontrado indica que el registro buscado no existe
+var ErrNoEncontrado = errors.New("registro no encontrado")
+
+// ErrDuplicado indica que el registro viola una restricción de unicidad
+var ErrDuplicado = errors.New("registro duplicado")
+
+// ErrReferenciaInvalida indica que el registro referencia a otro que no existe
+var ErrReferenciaInvalida = errors.New("referencia a un registro inexistente")
+
+// ErrEntidadInvalida indica que la entidad no supera la validación de dominio y no se guardó
+var ErrEntidadInvalida = errors.New("entidad inválida")
+
+// ErrModificacionConcurrente indica que el registro cambió entre que se leyó y se modificó
+var ErrModificacionConcurrente = errors.New("el registro fue modificado por otra operación")
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// SucursalRepository define el acceso a las sucursales registradas
+type SucursalRepository interface {
+	ObtenerPorID(ctx context.Context, id uint) (*entities.Sucursal, error)
+	Listar(ctx context.Context) ([]entities.Sucursal, error)
+	Guardar(ctx context.Context, sucursal *entities.Sucursal) error
+}
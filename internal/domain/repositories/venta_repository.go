@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// VentaRepository define el acceso a las ventas registradas
+type VentaRepository interface {
+	ObtenerPorID(ctx context.Context, id uint) (*entities.Venta, error)
+	ListarPorSucursal(ctx context.Context, sucursalID uint) ([]entities.Venta, error)
+	Guardar(ctx context.Context, venta *entities.Venta) error
+	// GuardarLote crea las ventas nuevas con sus detalles en una única
+	// transacción: si falla alguna no se guarda ninguna
+	GuardarLote(ctx context.Context, ventas []*entities.Venta) error
+	// CambiarEstado guarda el estado de la venta y las unidades devueltas de sus
+	// detalles, si el estado guardado sigue siendo desde; si no, retorna
+	// ErrModificacionConcurrente
+	CambiarEstado(ctx context.Context, venta *entities.Venta, desde string) error
+	RegistrarTransicion(ctx context.Context, transicion *entities.TransicionVenta) error
+	// ListarTransiciones retorna el historial de estados de la venta, del más antiguo al más nuevo
+	ListarTransiciones(ctx context.Context, ventaID uint) ([]entities.TransicionVenta, error)
+}
+
+// DetalleVentaRepository define el acceso a las líneas de las ventas
+type DetalleVentaRepository interface {
+	ListarPorVenta(ctx context.Context, ventaID uint) ([]entities.DetalleVenta, error)
+	Guardar(ctx context.Context, detalle *entities.DetalleVenta) error
+}
@@ -0,0 +1,140 @@
+package cifrado
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefijo identifica los valores cifrados; los valores sin él se guardaron en
+// texto plano antes de cifrar las credenciales
+const prefijo = "enc:v1:"
+
+// ErrClaveDesconocida indica que un valor fue cifrado con una clave que no está configurada
+var ErrClaveDesconocida = errors.New("valor cifrado con una clave desconocida")
+
+// Clave es una clave maestra identificada, para poder rotarla
+type Clave struct {
+	ID    string
+	Valor []byte // 32 bytes (AES-256)
+}
+
+// Cifrador cifra valores con AES-256-GCM usando la clave activa y descifra los
+// cifrados con cualquiera de las claves configuradas
+type Cifrador struct {
+	activa string
+	claves map[string]cipher.AEAD
+}
+
+// NewCifrador crea un cifrador cuya clave activa es la primera indicada; las
+// demás solo se usan para descifrar los valores cifrados antes de rotarla
+func NewCifrador(claves []Clave) (*Cifrador, error) {
+	if len(claves) == 0 {
+		return nil, fmt.Errorf("no se indicó ninguna clave de cifrado")
+	}
+
+	c := &Cifrador{activa: claves[0].ID, claves: make(map[string]cipher.AEAD, len(claves))}
+	for _, clave := range claves {
+		if clave.ID == "" || strings.Contains(clave.ID, ":") {
+			return nil, fmt.Errorf("identificador de clave inválido %q", clave.ID)
+		}
+		if _, repetida := c.claves[clave.ID]; repetida {
+			return nil, fmt.Errorf("clave %s repetida", clave.ID)
+		}
+		if len(clave.Valor) != 32 {
+			return nil, fmt.Errorf("la clave %s debe tener 32 bytes, tiene %d", clave.ID, len(clave.Valor))
+		}
+		bloque, err := aes.NewCipher(clave.Valor)
+		if err != nil {
+			return nil, fmt.Errorf("clave %s: %v", clave.ID, err)
+		}
+		aead, err := cipher.NewGCM(bloque)
+		if err != nil {
+			return nil, fmt.Errorf("clave %s: %v", clave.ID, err)
+		}
+		c.claves[clave.ID] = aead
+	}
+	return c, nil
+}
+
+// ParsearClaves interpreta una lista "id:clave,id:clave" con cada clave en
+// base64, la activa primero
+func ParsearClaves(texto string) ([]Clave, error) {
+	var claves []Clave
+	for _, parte := range strings.Split(texto, ",") {
+		parte = strings.TrimSpace(parte)
+		if parte == "" {
+			continue
+		}
+		id, valor, ok := strings.Cut(parte, ":")
+		if !ok {
+			return nil, fmt.Errorf("clave %q: se esperaba id:clave", parte)
+		}
+		bytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(valor))
+		if err != nil {
+			return nil, fmt.Errorf("clave %s: no es base64 válido", id)
+		}
+		claves = append(claves, Clave{ID: strings.TrimSpace(id), Valor: bytes})
+	}
+	return claves, nil
+}
+
+// Cifrar cifra el valor con la clave activa. El valor vacío no se cifra.
+func (c *Cifrador) Cifrar(valor string) (string, error) {
+	if valor == "" {
+		return "", nil
+	}
+
+	aead := c.claves[c.activa]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generando nonce: %v", err)
+	}
+	sellado := aead.Seal(nonce, nonce, []byte(valor), []byte(c.activa))
+	return prefijo + c.activa + ":" + base64.StdEncoding.EncodeToString(sellado), nil
+}
+
+// Descifrar retorna el valor original. Los valores sin cifrar se retornan tal cual.
+func (c *Cifrador) Descifrar(valor string) (string, error) {
+	if !EsCifrado(valor) {
+		return valor, nil
+	}
+
+	id, codificado, ok := strings.Cut(strings.TrimPrefix(valor, prefijo), ":")
+	if !ok {
+		return "", fmt.Errorf("valor cifrado mal formado")
+	}
+	aead, existe := c.claves[id]
+	if !existe {
+		return "", fmt.Errorf("%w: %s", ErrClaveDesconocida, id)
+	}
+
+	sellado, err := base64.StdEncoding.DecodeString(codificado)
+	if err != nil || len(sellado) < aead.NonceSize() {
+		return "", fmt.Errorf("valor cifrado mal formado")
+	}
+	nonce, cifrado := sellado[:aead.NonceSize()], sellado[aead.NonceSize():]
+	texto, err := aead.Open(nil, nonce, cifrado, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("no se pudo descifrar con la clave %s: %v", id, err)
+	}
+	return string(texto), nil
+}
+
+// RequiereRecifrar indica si el valor está sin cifrar o cifrado con una clave
+// que no es la activa
+func (c *Cifrador) RequiereRecifrar(valor string) bool {
+	if valor == "" {
+		return false
+	}
+	return !strings.HasPrefix(valor, prefijo+c.activa+":")
+}
+
+// EsCifrado indica si el valor fue cifrado por un Cifrador
+func EsCifrado(valor string) bool {
+	return strings.HasPrefix(valor, prefijo)
+}
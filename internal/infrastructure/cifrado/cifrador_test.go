@@ -0,0 +1,198 @@
+package cifrado
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func clave(id string, relleno byte) Clave {
+	return Clave{ID: id, Valor: bytes.Repeat([]byte{relleno}, 32)}
+}
+
+func nuevoCifrador(t *testing.T, claves ...Clave) *Cifrador {
+	t.Helper()
+	cifrador, err := NewCifrador(claves)
+	if err != nil {
+		t.Fatalf("NewCifrador: %v", err)
+	}
+	return cifrador
+}
+
+func TestCifrarYDescifrar(t *testing.T) {
+	cifrador := nuevoCifrador(t, clave("k1", 1))
+
+	casos := []struct {
+		nombre string
+		valor  string
+	}{
+		{"secreto", "s3cr3t"},
+		{"con separadores", "usuario:clave,con:dos puntos"},
+		{"unicode", "contraseña-ñandú-€"},
+		{"largo", strings.Repeat("x", 4096)},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			cifrado, err := cifrador.Cifrar(caso.valor)
+			if err != nil {
+				t.Fatalf("Cifrar: %v", err)
+			}
+			if !EsCifrado(cifrado) || strings.Contains(cifrado, caso.valor) {
+				t.Fatalf("Cifrar(%q) = %q, no parece cifrado", caso.valor, cifrado)
+			}
+			if cifrador.RequiereRecifrar(cifrado) {
+				t.Errorf("un valor recién cifrado no debería requerir recifrarse")
+			}
+
+			descifrado, err := cifrador.Descifrar(cifrado)
+			if err != nil {
+				t.Fatalf("Descifrar: %v", err)
+			}
+			if descifrado != caso.valor {
+				t.Errorf("Descifrar = %q, se esperaba %q", descifrado, caso.valor)
+			}
+		})
+	}
+}
+
+func TestCifrarUsaUnNonceDistintoCadaVez(t *testing.T) {
+	cifrador := nuevoCifrador(t, clave("k1", 1))
+
+	primero, _ := cifrador.Cifrar("s3cr3t")
+	segundo, _ := cifrador.Cifrar("s3cr3t")
+	if primero == segundo {
+		t.Errorf("dos cifrados del mismo valor no deberían coincidir")
+	}
+}
+
+func TestValorVacioYTextoPlano(t *testing.T) {
+	cifrador := nuevoCifrador(t, clave("k1", 1))
+
+	if cifrado, err := cifrador.Cifrar(""); err != nil || cifrado != "" {
+		t.Errorf("Cifrar(\"\") = %q, %v; se esperaba el valor vacío", cifrado, err)
+	}
+	if cifrador.RequiereRecifrar("") {
+		t.Errorf("el valor vacío no debería requerir recifrarse")
+	}
+
+	// Los valores guardados antes de cifrar las credenciales se leen tal cual
+	if descifrado, err := cifrador.Descifrar("plano"); err != nil || descifrado != "plano" {
+		t.Errorf("Descifrar(\"plano\") = %q, %v", descifrado, err)
+	}
+	if !cifrador.RequiereRecifrar("plano") {
+		t.Errorf("un valor en texto plano debería requerir recifrarse")
+	}
+}
+
+func TestRotacionDeClaves(t *testing.T) {
+	anterior := nuevoCifrador(t, clave("k1", 1))
+	cifradoAnterior, err := anterior.Cifrar("s3cr3t")
+	if err != nil {
+		t.Fatalf("Cifrar: %v", err)
+	}
+
+	// Tras rotar, k2 es la activa y k1 solo descifra
+	rotado := nuevoCifrador(t, clave("k2", 2), clave("k1", 1))
+	if !rotado.RequiereRecifrar(cifradoAnterior) {
+		t.Errorf("un valor cifrado con la clave anterior debería requerir recifrarse")
+	}
+	descifrado, err := rotado.Descifrar(cifradoAnterior)
+	if err != nil || descifrado != "s3cr3t" {
+		t.Fatalf("Descifrar con la clave anterior = %q, %v", descifrado, err)
+	}
+
+	recifrado, err := rotado.Cifrar(descifrado)
+	if err != nil {
+		t.Fatalf("Cifrar: %v", err)
+	}
+	if !strings.HasPrefix(recifrado, prefijo+"k2:") || rotado.RequiereRecifrar(recifrado) {
+		t.Errorf("el valor recifrado %q debería usar la clave activa k2", recifrado)
+	}
+
+	// Sin la clave anterior ya no se puede descifrar lo que no se recifró
+	soloNueva := nuevoCifrador(t, clave("k2", 2))
+	if _, err := soloNueva.Descifrar(cifradoAnterior); !errors.Is(err, ErrClaveDesconocida) {
+		t.Errorf("Descifrar sin la clave k1 = %v, se esperaba ErrClaveDesconocida", err)
+	}
+	if descifrado, err := soloNueva.Descifrar(recifrado); err != nil || descifrado != "s3cr3t" {
+		t.Errorf("Descifrar el valor recifrado = %q, %v", descifrado, err)
+	}
+}
+
+func TestDescifrarRechazaValoresAlterados(t *testing.T) {
+	cifrador := nuevoCifrador(t, clave("k1", 1))
+	cifrado, _ := cifrador.Cifrar("s3cr3t")
+	codificado := strings.TrimPrefix(cifrado, prefijo+"k1:")
+	sellado, _ := base64.StdEncoding.DecodeString(codificado)
+	sellado[len(sellado)-1] ^= 0xff
+
+	// Una clave con el mismo identificador pero otro valor no descifra
+	otraClave := nuevoCifrador(t, clave("k1", 9))
+
+	casos := []struct {
+		nombre   string
+		cifrador *Cifrador
+		valor    string
+		mensaje  string
+	}{
+		{"sin identificador", cifrador, prefijo + "sin-separador", "mal formado"},
+		{"base64 inválido", cifrador, prefijo + "k1:%%%", "mal formado"},
+		{"demasiado corto", cifrador, prefijo + "k1:" + base64.StdEncoding.EncodeToString([]byte("x")), "mal formado"},
+		{"alterado", cifrador, prefijo + "k1:" + base64.StdEncoding.EncodeToString(sellado), "no se pudo descifrar"},
+		{"otra clave", otraClave, cifrado, "no se pudo descifrar"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, err := caso.cifrador.Descifrar(caso.valor)
+			if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+				t.Errorf("Descifrar(%q) = %v, se esperaba un error con %q", caso.valor, err, caso.mensaje)
+			}
+		})
+	}
+}
+
+func TestNewCifradorValidaLasClaves(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		claves  []Clave
+		mensaje string
+	}{
+		{"sin claves", nil, "ninguna clave"},
+		{"sin identificador", []Clave{clave("", 1)}, "identificador de clave inválido"},
+		{"identificador con dos puntos", []Clave{clave("k:1", 1)}, "identificador de clave inválido"},
+		{"repetida", []Clave{clave("k1", 1), clave("k1", 2)}, "repetida"},
+		{"corta", []Clave{{ID: "k1", Valor: []byte("corta")}}, "32 bytes"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, err := NewCifrador(caso.claves)
+			if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+				t.Errorf("NewCifrador = %v, se esperaba un error con %q", err, caso.mensaje)
+			}
+		})
+	}
+}
+
+func TestParsearClaves(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	claves, err := ParsearClaves(" k2:" + k2 + " , k1:" + k1 + ",")
+	if err != nil {
+		t.Fatalf("ParsearClaves: %v", err)
+	}
+	if len(claves) != 2 || claves[0].ID != "k2" || claves[1].ID != "k1" || len(claves[0].Valor) != 32 {
+		t.Errorf("ParsearClaves = %+v, se esperaban k2 y k1 en ese orden", claves)
+	}
+
+	for _, texto := range []string{"sin-separador", "k1:no-es-base64!"} {
+		if _, err := ParsearClaves(texto); err == nil {
+			t.Errorf("ParsearClaves(%q) no retornó error", texto)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package connectors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ParametroCodificacion es la clave de ConfiguracionSistema.Parametros que
+// indica la codificación de origen de los archivos de una sucursal
+const ParametroCodificacion = "codificacion"
+
+// CodificacionAutomatica detecta la codificación a partir del contenido
+const CodificacionAutomatica = "auto"
+
+// codificaciones soportadas por nombre normalizado
+var codificaciones = map[string]encoding.Encoding{
+	"utf-8":        unicode.UTF8,
+	"utf8":         unicode.UTF8,
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"latin1":       charmap.ISO8859_1,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"latin9":       charmap.ISO8859_15,
+}
+
+// DecodificarUTF8 convierte el contenido a UTF-8 desde la codificación indicada.
+// Con codificación vacía o "auto" se asume UTF-8 si el contenido es válido y
+// Windows-1252 (superconjunto imprimible de ISO-8859-1) en caso contrario.
+// Retorna el contenido convertido y el nombre de la codificación usada.
+func DecodificarUTF8(contenido []byte, codificacion string) ([]byte, string, error) {
+	nombre := strings.ToLower(strings.TrimSpace(codificacion))
+	if nombre == "" || nombre == CodificacionAutomatica {
+		nombre = DetectarCodificacion(contenido)
+	}
+
+	codificador, existe := codificaciones[nombre]
+	if !existe {
+		return nil, "", fmt.Errorf("codificación no soportada: %s", codificacion)
+	}
+
+	if codificador == unicode.UTF8 {
+		// Las secuencias inválidas se reemplazan por U+FFFD para señalarlas como advertencias
+		contenido = bytes.TrimPrefix(contenido, []byte("\xef\xbb\xbf"))
+		return bytes.ToValidUTF8(contenido, []byte("\uFFFD")), nombre, nil
+	}
+
+	convertido, err := codificador.NewDecoder().Bytes(contenido)
+	if err != nil {
+		return nil, "", fmt.Errorf("error convirtiendo desde %s: %v", nombre, err)
+	}
+	return convertido, nombre, nil
+}
+
+// CodificacionDeSucursal retorna la codificación configurada para la sucursal,
+// o "auto" si no tiene una o su configuración no es válida
+func CodificacionDeSucursal(sucursal *entities.Sucursal) string {
+	if sucursal == nil {
+		return CodificacionAutomatica
+	}
+
+	config, err := sucursal.ObtenerConfiguracion()
+	if err != nil || config.Parametros[ParametroCodificacion] == "" {
+		return CodificacionAutomatica
+	}
+	return config.Parametros[ParametroCodificacion]
+}
+
+// DetectarCodificacion distingue entre UTF-8 y las codificaciones heredadas de
+// los sistemas de punto de venta antiguos
+func DetectarCodificacion(contenido []byte) string {
+	if utf8.Valid(contenido) {
+		return "utf-8"
+	}
+	return "windows-1252"
+}
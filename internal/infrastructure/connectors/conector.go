@@ -0,0 +1,55 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// Conector define la interfaz para obtener datos crudos desde el sistema de una sucursal
+type Conector interface {
+	Obtener(ctx context.Context) ([]map[string]interface{}, error)
+	GetTipo() string
+}
+
+// FabricaConector crea un conector configurado para una sucursal
+type FabricaConector func(sucursal *entities.Sucursal) (Conector, error)
+
+// Registro de fábricas de conectores por tipo de sistema
+var (
+	fabricas      = make(map[string]FabricaConector)
+	fabricasMutex sync.RWMutex
+)
+
+// Registrar asocia una fábrica de conectores a un tipo de sistema
+func Registrar(tipoSistema string, fabrica FabricaConector) {
+	fabricasMutex.Lock()
+	defer fabricasMutex.Unlock()
+
+	fabricas[tipoSistema] = fabrica
+}
+
+// NuevoConector crea el conector correspondiente al tipo de sistema de la sucursal
+func NuevoConector(sucursal *entities.Sucursal) (Conector, error) {
+	fabricasMutex.RLock()
+	fabrica, existe := fabricas[sucursal.TipoSistema]
+	fabricasMutex.RUnlock()
+
+	if !existe {
+		return nil, fmt.Errorf("no hay conector registrado para el tipo de sistema: %s", sucursal.TipoSistema)
+	}
+
+	return fabrica(sucursal)
+}
+
+func init() {
+	Registrar("api", func(sucursal *entities.Sucursal) (Conector, error) {
+		return NewConectorAPI(sucursal)
+	})
+	Registrar("csv", nuevoConectorArchivoSucursal)
+	Registrar("excel", nuevoConectorArchivoSucursal)
+	Registrar("ancho_fijo", nuevoConectorLegado("ancho_fijo"))
+	Registrar("xml", nuevoConectorLegado("xml"))
+}
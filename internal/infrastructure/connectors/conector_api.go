@@ -0,0 +1,131 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ConectorAPI obtiene datos desde la API REST de una sucursal
+type ConectorAPI struct {
+	endpoint     string
+	apiKey       string
+	codificacion string
+	client       *http.Client
+}
+
+// NewConectorAPI crea un conector para la API configurada en la sucursal
+func NewConectorAPI(sucursal *entities.Sucursal) (*ConectorAPI, error) {
+	config, err := sucursal.ObtenerConfiguracion()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConectorAPI{
+		endpoint:     sucursal.APIEndpoint,
+		apiKey:       sucursal.APIKey,
+		codificacion: config.Parametros[ParametroCodificacion],
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// ErrorHTTP representa una respuesta no exitosa de la API de una sucursal
+type ErrorHTTP struct {
+	StatusCode int
+	Endpoint   string
+}
+
+func (e *ErrorHTTP) Error() string {
+	return fmt.Sprintf("la API %s respondió con estado %d", e.Endpoint, e.StatusCode)
+}
+
+// Reintentable indica si el estado HTTP corresponde a una falla transitoria
+func (e *ErrorHTTP) Reintentable() bool {
+	return e.StatusCode >= 500 ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout
+}
+
+// Obtener consulta la API y retorna los registros recibidos. Acepta tanto un
+// arreglo JSON como un objeto con el arreglo en el campo "datos".
+func (c *ConectorAPI) Obtener(ctx context.Context) ([]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creando petición: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-API-Key", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando API de sucursal: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrorHTTP{StatusCode: resp.StatusCode, Endpoint: c.endpoint}
+	}
+
+	contenido, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo respuesta de API de sucursal: %w", err)
+	}
+
+	// La codificación configurada tiene prioridad sobre el charset declarado
+	codificacion := c.codificacion
+	if codificacion == "" {
+		if _, parametros, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+			codificacion = parametros["charset"]
+		}
+	}
+
+	contenido, _, err = DecodificarUTF8(contenido, codificacion)
+	if err != nil {
+		return nil, err
+	}
+
+	var cuerpo interface{}
+	if err := json.Unmarshal(contenido, &cuerpo); err != nil {
+		return nil, fmt.Errorf("JSON inválido desde API de sucursal: %w", err)
+	}
+
+	return extraerRegistros(cuerpo)
+}
+
+// GetTipo retorna el tipo de sistema del conector
+func (c *ConectorAPI) GetTipo() string {
+	return "api"
+}
+
+// extraerRegistros convierte un documento JSON decodificado en una lista de registros
+func extraerRegistros(cuerpo interface{}) ([]map[string]interface{}, error) {
+	if objeto, ok := cuerpo.(map[string]interface{}); ok {
+		datos, existe := objeto["datos"]
+		if !existe {
+			return []map[string]interface{}{objeto}, nil
+		}
+		cuerpo = datos
+	}
+
+	lista, ok := cuerpo.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("formato de datos no soportado: se esperaba un arreglo de registros")
+	}
+
+	registros := make([]map[string]interface{}, 0, len(lista))
+	for i, elemento := range lista {
+		registro, ok := elemento.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("registro %d no es un objeto JSON", i)
+		}
+		registros = append(registros, registro)
+	}
+
+	return registros, nil
+}
@@ -0,0 +1,157 @@
+package connectors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ConectorArchivo obtiene datos desde un archivo exportado por una sucursal
+type ConectorArchivo struct {
+	ruta         string
+	formato      string
+	codificacion string
+	lector       Lector // lector configurado para la sucursal; si es nil se usa el registrado para el formato
+}
+
+// NewConectorArchivo crea un conector para el archivo indicado. Si no se indica
+// el formato se deduce de la extensión del archivo; si no se indica la
+// codificación se detecta al leerlo.
+func NewConectorArchivo(ruta, formato, codificacion string) (*ConectorArchivo, error) {
+	if formato == "" {
+		formato = FormatoDesdeNombre(ruta)
+	}
+	if _, err := ObtenerLector(formato); err != nil {
+		return nil, err
+	}
+
+	return &ConectorArchivo{
+		ruta:         ruta,
+		formato:      formato,
+		codificacion: codificacion,
+	}, nil
+}
+
+// NewConectorArchivoConLector crea un conector que lee el archivo con un lector
+// propio, para los formatos que dependen de la configuración de la sucursal
+func NewConectorArchivoConLector(ruta, formato string, lector Lector, codificacion string) *ConectorArchivo {
+	return &ConectorArchivo{
+		ruta:         ruta,
+		formato:      formato,
+		codificacion: codificacion,
+		lector:       lector,
+	}
+}
+
+// Obtener lee el archivo con el lector de su formato
+func (c *ConectorArchivo) Obtener(ctx context.Context) ([]map[string]interface{}, error) {
+	lector := c.lector
+	if lector == nil {
+		var err error
+		if lector, err = ObtenerLector(c.formato); err != nil {
+			return nil, err
+		}
+	}
+
+	contenido, err := os.ReadFile(c.ruta)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo archivo %s: %w", c.ruta, err)
+	}
+
+	// Los XLSX almacenan el texto en UTF-8; el resto puede venir en codificaciones heredadas
+	if c.formato != "xlsx" {
+		var usada string
+		contenido, usada, err = DecodificarUTF8(contenido, c.codificacion)
+		if err != nil {
+			return nil, &ErrorFormato{Archivo: c.ruta, Err: err}
+		}
+		if usada != "utf-8" {
+			log.Printf("Archivo %s convertido desde %s a UTF-8", c.ruta, usada)
+		}
+	}
+
+	registros, err := lector.Leer(bytes.NewReader(contenido))
+	if err != nil {
+		// Un archivo mal formado no mejora al reintentarlo
+		return nil, &ErrorFormato{Archivo: c.ruta, Err: err}
+	}
+	return registros, nil
+}
+
+// GetTipo retorna el formato del archivo leído
+func (c *ConectorArchivo) GetTipo() string {
+	return c.formato
+}
+
+// ErrorFormato indica que el contenido de un archivo no pudo interpretarse
+type ErrorFormato struct {
+	Archivo string
+	Err     error
+}
+
+func (e *ErrorFormato) Error() string {
+	return fmt.Sprintf("error de formato en %s: %v", e.Archivo, e.Err)
+}
+
+func (e *ErrorFormato) Unwrap() error {
+	return e.Err
+}
+
+// Reintentable indica que los errores de formato son permanentes
+func (e *ErrorFormato) Reintentable() bool {
+	return false
+}
+
+// nuevoConectorArchivoSucursal crea un conector de archivo usando el parámetro
+// "ruta" (y opcionalmente "formato" y "codificacion") de la configuración de la sucursal
+func nuevoConectorArchivoSucursal(sucursal *entities.Sucursal) (Conector, error) {
+	config, err := sucursal.ObtenerConfiguracion()
+	if err != nil {
+		return nil, err
+	}
+
+	ruta := config.Parametros["ruta"]
+	if ruta == "" {
+		return nil, fmt.Errorf("la sucursal %s no tiene configurado el parámetro 'ruta'", sucursal.Nombre)
+	}
+
+	return NewConectorArchivo(ruta, config.Parametros["formato"], config.Parametros[ParametroCodificacion])
+}
+
+// nuevoConectorLegado retorna una fábrica de conectores para los formatos
+// heredados (ancho fijo y XML), que leen el archivo del parámetro "ruta" con el
+// layout o mapeo de la configuración de la sucursal
+func nuevoConectorLegado(formato string) FabricaConector {
+	return func(sucursal *entities.Sucursal) (Conector, error) {
+		config, err := sucursal.ObtenerConfiguracion()
+		if err != nil {
+			return nil, err
+		}
+
+		ruta := config.Parametros["ruta"]
+		if ruta == "" {
+			return nil, fmt.Errorf("la sucursal %s no tiene configurado el parámetro 'ruta'", sucursal.Nombre)
+		}
+
+		formato, lector, err := LectorDeSucursal(sucursal, formato)
+		if err != nil {
+			return nil, err
+		}
+		return NewConectorArchivoConLector(ruta, formato, lector, config.Parametros[ParametroCodificacion]), nil
+	}
+}
+
+// NewConectorArchivoSucursal crea un conector para un archivo recibido de la
+// sucursal (carpeta de entrada), leído con el lector que le corresponde según
+// LectorDeSucursal
+func NewConectorArchivoSucursal(ruta string, sucursal *entities.Sucursal) (*ConectorArchivo, error) {
+	formato, lector, err := LectorDeSucursal(sucursal, FormatoDesdeNombre(ruta))
+	if err != nil {
+		return nil, err
+	}
+	return NewConectorArchivoConLector(ruta, formato, lector, CodificacionDeSucursal(sucursal)), nil
+}
@@ -0,0 +1,204 @@
+package connectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// Lector convierte el contenido de un archivo exportado por una sucursal en registros
+type Lector interface {
+	Leer(r io.Reader) ([]map[string]interface{}, error)
+}
+
+// Registro de lectores por formato de archivo
+var (
+	lectores      = make(map[string]Lector)
+	lectoresMutex sync.RWMutex
+)
+
+// RegistrarLector asocia un lector a un formato de archivo
+func RegistrarLector(formato string, lector Lector) {
+	lectoresMutex.Lock()
+	defer lectoresMutex.Unlock()
+
+	lectores[formato] = lector
+}
+
+// ObtenerLector retorna el lector registrado para un formato
+func ObtenerLector(formato string) (Lector, error) {
+	lectoresMutex.RLock()
+	defer lectoresMutex.RUnlock()
+
+	lector, existe := lectores[formato]
+	if !existe {
+		return nil, fmt.Errorf("formato de archivo no soportado: %s", formato)
+	}
+	return lector, nil
+}
+
+// LectorDeSucursal retorna el lector para un archivo de la sucursal. Las
+// sucursales de ancho fijo usan siempre su layout y los XML se leen con el
+// mapeo configurado; el resto usa el lector registrado para el formato.
+func LectorDeSucursal(sucursal *entities.Sucursal, formato string) (string, Lector, error) {
+	if sucursal != nil {
+		config, err := sucursal.ObtenerConfiguracion()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if sucursal.TipoSistema == "ancho_fijo" {
+			if config.AnchoFijo == nil {
+				return "", nil, fmt.Errorf("la sucursal %s no tiene configurado el layout 'ancho_fijo'", sucursal.Nombre)
+			}
+			lector, err := NewLectorAnchoFijo(*config.AnchoFijo)
+			return "ancho_fijo", lector, err
+		}
+
+		if formato == "xml" && config.XML != nil {
+			lector, err := NewLectorXML(*config.XML)
+			return formato, lector, err
+		}
+	}
+
+	lector, err := ObtenerLector(formato)
+	return formato, lector, err
+}
+
+// FormatoDesdeNombre deduce el formato de un archivo a partir de su extensión
+func FormatoDesdeNombre(nombre string) string {
+	switch strings.ToLower(filepath.Ext(nombre)) {
+	case ".csv", ".txt":
+		return "csv"
+	case ".dat", ".prn":
+		return "ancho_fijo"
+	case ".xlsx":
+		return "xlsx"
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".xml":
+		return "xml"
+	default:
+		return ""
+	}
+}
+
+// DetectarFormato identifica el formato de un archivo a partir de su contenido,
+// usando la extensión solo para confirmar los casos ambiguos
+func DetectarFormato(nombre string, contenido []byte) (string, error) {
+	if bytes.HasPrefix(contenido, []byte("PK\x03\x04")) {
+		return "xlsx", nil
+	}
+
+	texto := bytes.TrimSpace(bytes.TrimPrefix(contenido, []byte("\xef\xbb\xbf")))
+	if len(texto) == 0 {
+		return "", fmt.Errorf("el archivo está vacío")
+	}
+
+	if texto[0] == '[' || texto[0] == '{' {
+		if json.Valid(texto) {
+			return "json", nil
+		}
+		if esNDJSON(texto) {
+			return "ndjson", nil
+		}
+		return "", fmt.Errorf("el contenido parece JSON pero no es válido")
+	}
+
+	if texto[0] == '<' {
+		return "xml", nil
+	}
+
+	tipoContenido := http.DetectContentType(contenido)
+	if strings.HasPrefix(tipoContenido, "text/") {
+		if formato := FormatoDesdeNombre(nombre); formato == "csv" || formato == "" {
+			return "csv", nil
+		}
+	}
+
+	return "", fmt.Errorf("contenido no reconocido (%s): se aceptan CSV, XLSX, JSON, NDJSON y XML", tipoContenido)
+}
+
+// esNDJSON verifica que las primeras líneas del contenido sean objetos JSON válidos
+func esNDJSON(texto []byte) bool {
+	lineas := bytes.Split(texto, []byte("\n"))
+	revisadas := 0
+	for _, linea := range lineas {
+		linea = bytes.TrimSpace(linea)
+		if len(linea) == 0 {
+			continue
+		}
+		if linea[0] != '{' || !json.Valid(linea) {
+			return false
+		}
+		revisadas++
+		if revisadas == 10 {
+			break
+		}
+	}
+	return revisadas > 0
+}
+
+// convertirValor interpreta una celda de texto, convirtiendo los números a float64
+// para que los registros tengan los mismos tipos que los recibidos en JSON.
+// Los valores con ceros a la izquierda (códigos, SKU) se conservan como texto.
+func convertirValor(valor string) interface{} {
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		return valor
+	}
+
+	sinSigno := strings.TrimPrefix(valor, "-")
+	if len(sinSigno) > 1 && sinSigno[0] == '0' && sinSigno[1] != '.' {
+		return valor
+	}
+
+	if numero, err := strconv.ParseFloat(valor, 64); err == nil {
+		return numero
+	}
+	return valor
+}
+
+// armarRegistro combina los encabezados con los valores de una fila
+func armarRegistro(encabezados, fila []string) map[string]interface{} {
+	registro := make(map[string]interface{}, len(encabezados))
+	for i, encabezado := range encabezados {
+		if encabezado == "" {
+			continue
+		}
+		if i < len(fila) {
+			registro[encabezado] = convertirValor(fila[i])
+		} else {
+			registro[encabezado] = ""
+		}
+	}
+	return registro
+}
+
+// normalizarEncabezados limpia los nombres de columna para usarlos como claves
+func normalizarEncabezados(fila []string) []string {
+	encabezados := make([]string, len(fila))
+	for i, celda := range fila {
+		celda = strings.TrimPrefix(celda, "\ufeff")
+		encabezados[i] = strings.ToLower(strings.TrimSpace(celda))
+	}
+	return encabezados
+}
+
+func init() {
+	RegistrarLector("csv", &LectorCSV{})
+	RegistrarLector("xlsx", &LectorXLSX{})
+	RegistrarLector("json", &LectorJSON{})
+	RegistrarLector("ndjson", &LectorNDJSON{})
+	RegistrarLector("xml", &LectorXML{})
+}
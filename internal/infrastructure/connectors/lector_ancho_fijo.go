@@ -0,0 +1,116 @@
+package connectors
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// formatoFechaAnchoFijo es el formato por defecto de las fechas en archivos de ancho fijo
+const formatoFechaAnchoFijo = "20060102"
+
+// LectorAnchoFijo lee archivos de texto de ancho fijo según el layout de la sucursal
+type LectorAnchoFijo struct {
+	Layout entities.LayoutAnchoFijo
+}
+
+// NewLectorAnchoFijo crea un lector para el layout indicado
+func NewLectorAnchoFijo(layout entities.LayoutAnchoFijo) (*LectorAnchoFijo, error) {
+	if err := layout.Validar(); err != nil {
+		return nil, err
+	}
+	return &LectorAnchoFijo{Layout: layout}, nil
+}
+
+// Leer interpreta cada línea no vacía como un registro. Las posiciones se
+// cuentan en caracteres, por lo que el contenido debe estar ya en UTF-8.
+func (l *LectorAnchoFijo) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	registros := []map[string]interface{}{}
+	numeroLinea := 0
+	for scanner.Scan() {
+		numeroLinea++
+		if numeroLinea <= l.Layout.LineasOmitir {
+			continue
+		}
+
+		linea := []rune(strings.TrimRight(scanner.Text(), "\r"))
+		if strings.TrimSpace(string(linea)) == "" {
+			continue
+		}
+
+		registro := make(map[string]interface{}, len(l.Layout.Columnas))
+		for _, columna := range l.Layout.Columnas {
+			valor, err := convertirColumna(columna, recortar(linea, columna.Inicio-1, columna.Longitud))
+			if err != nil {
+				return nil, fmt.Errorf("línea %d, campo %s: %v", numeroLinea, columna.Campo, err)
+			}
+			registro[columna.Campo] = valor
+		}
+		registros = append(registros, registro)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo archivo de ancho fijo: %v", err)
+	}
+
+	return registros, nil
+}
+
+// recortar extrae la porción de la línea; las líneas cortas completan con vacío
+func recortar(linea []rune, inicio, longitud int) string {
+	if inicio >= len(linea) {
+		return ""
+	}
+	fin := inicio + longitud
+	if fin > len(linea) {
+		fin = len(linea)
+	}
+	return strings.TrimSpace(string(linea[inicio:fin]))
+}
+
+// convertirColumna interpreta el texto de una columna según su tipo
+func convertirColumna(columna entities.ColumnaAnchoFijo, texto string) (interface{}, error) {
+	switch columna.Tipo {
+	case entities.ColumnaTexto:
+		return texto, nil
+
+	case entities.ColumnaNumero:
+		if texto == "" {
+			return 0.0, nil
+		}
+		numero, err := strconv.ParseFloat(strings.ReplaceAll(texto, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q", texto)
+		}
+		// Los sistemas heredados suelen omitir el separador decimal
+		if columna.Decimales > 0 && !strings.ContainsAny(texto, ".,") {
+			numero /= math.Pow10(columna.Decimales)
+		}
+		return numero, nil
+
+	case entities.ColumnaFecha:
+		if texto == "" || strings.Trim(texto, "0") == "" {
+			return "", nil
+		}
+		formato := columna.Formato
+		if formato == "" {
+			formato = formatoFechaAnchoFijo
+		}
+		fecha, err := time.Parse(formato, texto)
+		if err != nil {
+			return nil, fmt.Errorf("fecha inválida %q para el formato %s", texto, formato)
+		}
+		return fecha.Format(time.RFC3339), nil
+	}
+
+	return convertirValor(texto), nil
+}
@@ -0,0 +1,74 @@
+package connectors
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LectorCSV lee archivos CSV con fila de encabezados. El separador se detecta
+// automáticamente entre coma y punto y coma si no se configura uno.
+type LectorCSV struct {
+	Separador rune
+}
+
+// Leer interpreta el CSV y retorna un registro por fila
+func (l *LectorCSV) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	buffer := bufio.NewReader(r)
+
+	separador := l.Separador
+	if separador == 0 {
+		primeraLinea, err := buffer.Peek(4096)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, fmt.Errorf("error leyendo CSV: %v", err)
+		}
+		separador = detectarSeparador(string(primeraLinea))
+	}
+
+	lector := csv.NewReader(buffer)
+	lector.Comma = separador
+	lector.FieldsPerRecord = -1
+	lector.TrimLeadingSpace = true
+
+	filas, err := lector.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %v", err)
+	}
+
+	if len(filas) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+
+	encabezados := normalizarEncabezados(filas[0])
+	registros := make([]map[string]interface{}, 0, len(filas)-1)
+	for _, fila := range filas[1:] {
+		if filaVacia(fila) {
+			continue
+		}
+		registros = append(registros, armarRegistro(encabezados, fila))
+	}
+
+	return registros, nil
+}
+
+// detectarSeparador elige el separador más frecuente en la primera línea
+func detectarSeparador(texto string) rune {
+	if fin := strings.IndexByte(texto, '\n'); fin >= 0 {
+		texto = texto[:fin]
+	}
+	if strings.Count(texto, ";") > strings.Count(texto, ",") {
+		return ';'
+	}
+	return ','
+}
+
+func filaVacia(fila []string) bool {
+	for _, celda := range fila {
+		if strings.TrimSpace(celda) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,20 @@
+package connectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LectorJSON lee un documento JSON con un arreglo de registros, o un objeto
+// con el arreglo en el campo "datos"
+type LectorJSON struct{}
+
+// Leer decodifica el documento y retorna sus registros
+func (l *LectorJSON) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	var cuerpo interface{}
+	if err := json.NewDecoder(r).Decode(&cuerpo); err != nil {
+		return nil, fmt.Errorf("JSON inválido: %v", err)
+	}
+	return extraerRegistros(cuerpo)
+}
@@ -0,0 +1,40 @@
+package connectors
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LectorNDJSON lee archivos con un objeto JSON por línea
+type LectorNDJSON struct{}
+
+// Leer decodifica cada línea no vacía como un registro
+func (l *LectorNDJSON) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	registros := make([]map[string]interface{}, 0)
+	linea := 0
+	for scanner.Scan() {
+		linea++
+		contenido := bytes.TrimSpace(scanner.Bytes())
+		if len(contenido) == 0 {
+			continue
+		}
+
+		var registro map[string]interface{}
+		if err := json.Unmarshal(contenido, &registro); err != nil {
+			return nil, fmt.Errorf("línea %d: JSON inválido: %v", linea, err)
+		}
+		registros = append(registros, registro)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error leyendo NDJSON: %v", err)
+	}
+
+	return registros, nil
+}
@@ -0,0 +1,47 @@
+package connectors
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// LectorXLSX lee la primera hoja de un libro Excel con fila de encabezados
+type LectorXLSX struct {
+	Hoja string
+}
+
+// Leer interpreta la hoja y retorna un registro por fila
+func (l *LectorXLSX) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	libro, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("XLSX inválido: %v", err)
+	}
+	defer libro.Close()
+
+	hoja := l.Hoja
+	if hoja == "" {
+		hoja = libro.GetSheetName(0)
+	}
+
+	filas, err := libro.GetRows(hoja)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo hoja %s: %v", hoja, err)
+	}
+
+	if len(filas) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+
+	encabezados := normalizarEncabezados(filas[0])
+	registros := make([]map[string]interface{}, 0, len(filas)-1)
+	for _, fila := range filas[1:] {
+		if filaVacia(fila) {
+			continue
+		}
+		registros = append(registros, armarRegistro(encabezados, fila))
+	}
+
+	return registros, nil
+}
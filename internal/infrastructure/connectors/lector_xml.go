@@ -0,0 +1,178 @@
+package connectors
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// LectorXML lee documentos XML. Con un mapeo extrae los registros y campos de
+// las rutas configuradas; sin mapeo, cada hijo del elemento raíz es un registro
+// cuyos campos son sus atributos y sus elementos hijos con texto.
+type LectorXML struct {
+	Mapeo *entities.MapeoXML
+}
+
+// NewLectorXML crea un lector para el mapeo indicado
+func NewLectorXML(mapeo entities.MapeoXML) (*LectorXML, error) {
+	if err := mapeo.Validar(); err != nil {
+		return nil, err
+	}
+	return &LectorXML{Mapeo: &mapeo}, nil
+}
+
+// Leer interpreta el documento y retorna sus registros
+func (l *LectorXML) Leer(r io.Reader) ([]map[string]interface{}, error) {
+	documento, err := parsearXML(r)
+	if err != nil {
+		return nil, fmt.Errorf("XML inválido: %v", err)
+	}
+
+	if l.Mapeo == nil {
+		return registrosGenericos(documento), nil
+	}
+
+	elementos := seleccionar(documento, l.Mapeo.Registro)
+	registros := make([]map[string]interface{}, 0, len(elementos))
+	for _, elemento := range elementos {
+		registro := make(map[string]interface{}, len(l.Mapeo.Campos))
+		for campo, ruta := range l.Mapeo.Campos {
+			registro[campo] = convertirValor(valorEnRuta(elemento, ruta))
+		}
+		registros = append(registros, registro)
+	}
+
+	return registros, nil
+}
+
+// nodoXML es un elemento del documento con sus atributos, texto e hijos
+type nodoXML struct {
+	nombre    string
+	atributos map[string]string
+	texto     strings.Builder
+	hijos     []*nodoXML
+}
+
+// parsearXML construye el árbol del documento. Retorna un nodo sin nombre cuyo
+// único hijo es el elemento raíz.
+func parsearXML(r io.Reader) (*nodoXML, error) {
+	decoder := xml.NewDecoder(r)
+	// El contenido ya fue convertido a UTF-8, sin importar lo que declare el documento
+	decoder.CharsetReader = func(charset string, entrada io.Reader) (io.Reader, error) {
+		return entrada, nil
+	}
+
+	documento := &nodoXML{}
+	pila := []*nodoXML{documento}
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		actual := pila[len(pila)-1]
+		switch elemento := t.(type) {
+		case xml.StartElement:
+			nodo := &nodoXML{nombre: elemento.Name.Local, atributos: make(map[string]string, len(elemento.Attr))}
+			for _, atributo := range elemento.Attr {
+				nodo.atributos[atributo.Name.Local] = atributo.Value
+			}
+			actual.hijos = append(actual.hijos, nodo)
+			pila = append(pila, nodo)
+		case xml.EndElement:
+			pila = pila[:len(pila)-1]
+		case xml.CharData:
+			actual.texto.Write(elemento)
+		}
+	}
+
+	if len(documento.hijos) == 0 {
+		return nil, fmt.Errorf("el documento no tiene elemento raíz")
+	}
+	return documento, nil
+}
+
+// seleccionar retorna los elementos que coinciden con la ruta desde el nodo.
+// Una ruta que empieza con "//" busca su primer paso a cualquier profundidad.
+func seleccionar(nodo *nodoXML, ruta string) []*nodoXML {
+	actuales := []*nodoXML{nodo}
+	if strings.HasPrefix(ruta, "//") {
+		ruta = strings.TrimPrefix(ruta, "//")
+		pasos := strings.Split(ruta, "/")
+		actuales = descendientes(nodo, pasos[0])
+		ruta = strings.Join(pasos[1:], "/")
+	}
+
+	for _, paso := range strings.Split(strings.Trim(ruta, "/"), "/") {
+		if paso == "" || paso == "." {
+			continue
+		}
+		var siguientes []*nodoXML
+		for _, actual := range actuales {
+			for _, hijo := range actual.hijos {
+				if paso == "*" || hijo.nombre == paso {
+					siguientes = append(siguientes, hijo)
+				}
+			}
+		}
+		actuales = siguientes
+	}
+
+	return actuales
+}
+
+// descendientes retorna los elementos con el nombre indicado a cualquier profundidad
+func descendientes(nodo *nodoXML, nombre string) []*nodoXML {
+	var encontrados []*nodoXML
+	for _, hijo := range nodo.hijos {
+		if nombre == "*" || hijo.nombre == nombre {
+			encontrados = append(encontrados, hijo)
+		}
+		encontrados = append(encontrados, descendientes(hijo, nombre)...)
+	}
+	return encontrados
+}
+
+// valorEnRuta retorna el texto del primer elemento (o atributo, con "@") de la ruta
+func valorEnRuta(nodo *nodoXML, ruta string) string {
+	atributo := ""
+	if i := strings.LastIndex(ruta, "@"); i >= 0 {
+		atributo = ruta[i+1:]
+		ruta = strings.TrimSuffix(ruta[:i], "/")
+	}
+
+	elementos := seleccionar(nodo, ruta)
+	if len(elementos) == 0 {
+		return ""
+	}
+	if atributo != "" {
+		return elementos[0].atributos[atributo]
+	}
+	return strings.TrimSpace(elementos[0].texto.String())
+}
+
+// registrosGenericos arma un registro por cada hijo del elemento raíz
+func registrosGenericos(documento *nodoXML) []map[string]interface{} {
+	raiz := documento.hijos[0]
+	registros := make([]map[string]interface{}, 0, len(raiz.hijos))
+	for _, elemento := range raiz.hijos {
+		registro := make(map[string]interface{})
+		for nombre, valor := range elemento.atributos {
+			registro[strings.ToLower(nombre)] = convertirValor(valor)
+		}
+		for _, hijo := range elemento.hijos {
+			nombre := strings.ToLower(hijo.nombre)
+			if _, existe := registro[nombre]; !existe && len(hijo.hijos) == 0 {
+				registro[nombre] = convertirValor(hijo.texto.String())
+			}
+		}
+		registros = append(registros, registro)
+	}
+	return registros
+}
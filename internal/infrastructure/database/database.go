@@ -0,0 +1,151 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Database mantiene la conexión única a la base de datos
+type Database struct {
+	db    *gorm.DB
+	mutex sync.Mutex
+}
+
+var (
+	instance *Database
+	once     sync.Once
+)
+
+// GetInstance retorna la instancia única de Database (Singleton)
+func GetInstance() *Database {
+	once.Do(func() {
+		instance = &Database{}
+	})
+	return instance
+}
+
+// Drivers de base de datos soportados
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Configuracion indica el driver y la cadena de conexión. Para SQLite el DSN es
+// la ruta del archivo o ":memory:" para una base en memoria.
+type Configuracion struct {
+	Driver string
+	DSN    string
+}
+
+// Connect abre la conexión con la configuración indicada. Si ya existe una conexión la reutiliza.
+func (d *Database) Connect(config Configuracion) (*gorm.DB, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.db != nil {
+		return d.db, nil
+	}
+
+	dialector, err := abrirDialector(config)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+		// Traduce las violaciones de unicidad a gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo la base de datos %s: %w", config.Driver, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if config.Driver == DriverSQLite {
+		// SQLite admite un único escritor; una sola conexión evita errores de
+		// bloqueo y mantiene viva la base en memoria
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	} else {
+		sqlDB.SetMaxOpenConns(25)
+		sqlDB.SetMaxIdleConns(5)
+		sqlDB.SetConnMaxLifetime(30 * time.Minute)
+	}
+
+	d.db = db
+	return d.db, nil
+}
+
+// abrirDialector selecciona el dialecto de GORM para el driver configurado
+func abrirDialector(config Configuracion) (gorm.Dialector, error) {
+	switch config.Driver {
+	case DriverMySQL, "":
+		return mysql.Open(config.DSN), nil
+	case DriverPostgres:
+		return postgres.Open(config.DSN), nil
+	case DriverSQLite:
+		return sqlite.Open(dsnSQLite(config.DSN)), nil
+	}
+	return nil, fmt.Errorf("driver de base de datos no soportado: %s (se acepta mysql, sqlite o postgres)", config.Driver)
+}
+
+// dsnSQLite activa las claves foráneas, que SQLite no aplica por defecto, y
+// una espera ante bloqueos del archivo
+func dsnSQLite(ruta string) string {
+	if ruta == "" {
+		ruta = ":memory:"
+	}
+	if strings.Contains(ruta, "_pragma=") {
+		return ruta
+	}
+
+	separador := "?"
+	if strings.Contains(ruta, "?") {
+		separador = "&"
+	}
+	return ruta + separador + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+// GetDB retorna la conexión abierta, o nil si todavía no se conectó
+func (d *Database) GetDB() *gorm.DB {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.db
+}
+
+// Close cierra la conexión
+func (d *Database) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.db == nil {
+		return nil
+	}
+
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return err
+	}
+	d.db = nil
+	return sqlDB.Close()
+}
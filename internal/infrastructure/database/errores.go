@@ -0,0 +1,56 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Códigos de error de SQLite que indican que la base está ocupada por otra conexión
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// Números de error de MySQL que se resuelven reintentando
+const (
+	mysqlDemasiadasConexiones = 1040
+	mysqlEsperaBloqueo        = 1205
+	mysqlDeadlock             = 1213
+)
+
+// EsErrorTransitorio indica si el error de la base de datos se resuelve
+// reintentando la operación: bloqueos, deadlocks, conflictos de serialización,
+// conexiones caídas o falta de recursos momentánea. Las violaciones de
+// restricciones, los errores de tipos y de sintaxis no lo son.
+func EsErrorTransitorio(err error) bool {
+	var errorSQLite *sqlitedriver.Error
+	if errors.As(err, &errorSQLite) {
+		codigo := errorSQLite.Code() & 0xff // el código primario, sin la extensión
+		return codigo == sqliteBusy || codigo == sqliteLocked
+	}
+
+	var errorPostgres *pgconn.PgError
+	if errors.As(err, &errorPostgres) {
+		estado := errorPostgres.SQLState()
+		// 40: rollback de la transacción (serialización, deadlock); 08: conexión;
+		// 53: recursos insuficientes; 57P01-03: el servidor se está deteniendo;
+		// 55P03: no se obtuvo el bloqueo
+		return strings.HasPrefix(estado, "40") || strings.HasPrefix(estado, "08") ||
+			strings.HasPrefix(estado, "53") || strings.HasPrefix(estado, "57P0") || estado == "55P03"
+	}
+
+	var errorMySQL *mysqldriver.MySQLError
+	if errors.As(err, &errorMySQL) {
+		switch errorMySQL.Number {
+		case mysqlDemasiadasConexiones, mysqlEsperaBloqueo, mysqlDeadlock:
+			return true
+		}
+		return false
+	}
+
+	return errors.Is(err, mysqldriver.ErrInvalidConn)
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestEsErrorTransitorio(t *testing.T) {
+	casos := []struct {
+		nombre   string
+		err      error
+		esperado bool
+	}{
+		{"nil", nil, false},
+		{"desconocido", errors.New("la venta ya tiene ID"), false},
+		{"postgres serialización", &pgconn.PgError{Code: "40001"}, true},
+		{"postgres deadlock envuelto", fmt.Errorf("guardando: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"postgres conexión", &pgconn.PgError{Code: "08006"}, true},
+		{"postgres demasiadas conexiones", &pgconn.PgError{Code: "53300"}, true},
+		{"postgres apagándose", &pgconn.PgError{Code: "57P01"}, true},
+		{"postgres sin bloqueo", &pgconn.PgError{Code: "55P03"}, true},
+		{"postgres clave duplicada", &pgconn.PgError{Code: "23505"}, false},
+		{"postgres sintaxis", &pgconn.PgError{Code: "42601"}, false},
+		{"postgres consulta cancelada", &pgconn.PgError{Code: "57014"}, false},
+		{"mysql deadlock", &mysqldriver.MySQLError{Number: 1213}, true},
+		{"mysql espera de bloqueo", &mysqldriver.MySQLError{Number: 1205}, true},
+		{"mysql demasiadas conexiones", &mysqldriver.MySQLError{Number: 1040}, true},
+		{"mysql clave duplicada", &mysqldriver.MySQLError{Number: 1062}, false},
+		{"mysql conexión inválida", fmt.Errorf("consultando: %w", mysqldriver.ErrInvalidConn), true},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if obtenido := EsErrorTransitorio(caso.err); obtenido != caso.esperado {
+				t.Errorf("EsErrorTransitorio(%v) = %v, se esperaba %v", caso.err, obtenido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestEsErrorTransitorioSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("CREATE TABLE productos (sku TEXT PRIMARY KEY)"); err != nil {
+		t.Fatalf("CREATE TABLE: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO productos VALUES ('A1')"); err != nil {
+		t.Fatalf("INSERT: %v", err)
+	}
+
+	casos := []struct {
+		nombre    string
+		sentencia string
+	}{
+		{"restricción violada", "INSERT INTO productos VALUES ('A1')"},
+		{"tabla inexistente", "SELECT * FROM ventas"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, err := db.Exec(caso.sentencia)
+			if err == nil {
+				t.Fatalf("%s no retornó error", caso.sentencia)
+			}
+			if EsErrorTransitorio(err) {
+				t.Errorf("EsErrorTransitorio(%v) = true, se esperaba false", err)
+			}
+		})
+	}
+}
+
+func TestEsErrorTransitorioSQLiteOcupada(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "ocupada.db")
+	abrir := func() *sql.DB {
+		db, err := sql.Open("sqlite", ruta+"?_pragma=busy_timeout(0)")
+		if err != nil {
+			t.Fatalf("sql.Open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	escritor, otro := abrir(), abrir()
+
+	if _, err := escritor.Exec("CREATE TABLE productos (sku TEXT PRIMARY KEY)"); err != nil {
+		t.Fatalf("CREATE TABLE: %v", err)
+	}
+
+	// Una transacción de escritura abierta deja la base ocupada para otra conexión
+	conexion, err := escritor.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn: %v", err)
+	}
+	defer conexion.Close()
+	if _, err := conexion.ExecContext(context.Background(), "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("BEGIN IMMEDIATE: %v", err)
+	}
+	defer conexion.ExecContext(context.Background(), "ROLLBACK")
+
+	_, err = otro.Exec("INSERT INTO productos VALUES ('A1')")
+	if err == nil {
+		t.Fatalf("la escritura concurrente no retornó error")
+	}
+	if !EsErrorTransitorio(fmt.Errorf("guardando: %w", err)) {
+		t.Errorf("EsErrorTransitorio(%v) = false, se esperaba true", err)
+	}
+}
@@ -0,0 +1,525 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migraciones retorna las migraciones del esquema del sistema. Cada migración
+// declara su propia copia de los modelos que toca, de modo que su efecto no
+// cambie cuando evolucionen las entidades del dominio.
+func Migraciones() []Migracion {
+	return []Migracion{
+		{
+			Version: 1,
+			Nombre:  "esquema_inicial",
+			// AutoMigrate también adopta las bases creadas antes de versionar el esquema
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&sucursalV1{}, &productoV1{}, &ventaV1{}, &detalleVentaV1{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &detalleVentaV1{}, &ventaV1{}, &productoV1{}, &sucursalV1{})
+			},
+		},
+		{
+			Version: 2,
+			Nombre:  "historial_precios",
+			// Los productos existentes parten con su precio actual, vigente desde su creación
+			Subir: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&precioHistoricoV2{}); err != nil {
+					return err
+				}
+				return tx.Exec(`INSERT INTO precios_historicos (producto_id, precio_anterior, precio_oferta_anterior, precio, precio_oferta, vigente_desde)
+					SELECT id, 0, 0, precio, precio_oferta, fecha_creacion FROM productos`).Error
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &precioHistoricoV2{})
+			},
+		},
+		{
+			Version: 3,
+			Nombre:  "movimientos_stock",
+			// El stock existente se registra como un ajuste inicial sin sucursal
+			Subir: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&movimientoStockV3{}); err != nil {
+					return err
+				}
+				return tx.Exec(`INSERT INTO movimientos_stock (producto_id, sucursal_id, tipo, cantidad, stock_resultante, referencia, lote_id, fecha)
+					SELECT id, 0, 'ajuste', stock_actual, stock_actual, 'saldo inicial', '', ultima_actualizacion FROM productos WHERE stock_actual <> 0`).Error
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &movimientoStockV3{})
+			},
+		},
+		{
+			Version: 4,
+			Nombre:  "outbox_eventos",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&eventoOutboxV4{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &eventoOutboxV4{})
+			},
+		},
+		{
+			Version: 5,
+			Nombre:  "lotes_archivados",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&loteArchivadoV5{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &loteArchivadoV5{})
+			},
+		},
+		{
+			Version: 6,
+			Nombre:  "auditoria_purgas",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&registroPurgaV6{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &registroPurgaV6{})
+			},
+		},
+		{
+			Version: 7,
+			Nombre:  "clientes_potenciales",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&clientePotencialV7{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return eliminarTablas(tx, &clientePotencialV7{})
+			},
+		},
+		{
+			Version: 8,
+			Nombre:  "ciclo_clientes_potenciales",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&clientePotencialV8{}, &clienteV8{}, &transicionClienteV8{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				if err := eliminarTablas(tx, &transicionClienteV8{}, &clienteV8{}); err != nil {
+					return err
+				}
+				for _, columna := range []string{"EstadoActualizadoEn", "EstadoActualizadoPor", "ClienteID"} {
+					if err := tx.Migrator().DropColumn(&clientePotencialV8{}, columna); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: 9,
+			Nombre:  "puntaje_clientes_potenciales",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&clientePotencialV9{}, &interaccionClienteV9{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				if err := eliminarTablas(tx, &interaccionClienteV9{}); err != nil {
+					return err
+				}
+				for _, columna := range []string{"Puntaje", "FactoresPuntaje", "PuntajeCalculadoEn"} {
+					if err := tx.Migrator().DropColumn(&clientePotencialV9{}, columna); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Version: 10,
+			Nombre:  "estados_ventas",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&detalleVentaV10{}, &transicionVentaV10{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				if err := eliminarTablas(tx, &transicionVentaV10{}); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&detalleVentaV10{}, "CantidadDevuelta")
+			},
+		},
+		{
+			Version: 11,
+			Nombre:  "impuestos_ventas",
+			Subir: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&ventaV11{}, &detalleVentaV11{}, &impuestoVentaV11{}); err != nil {
+					return err
+				}
+				// Las ventas anteriores se calcularon con IVA del 21% sobre todas las líneas
+				if err := tx.Exec("UPDATE detalles_venta SET alicuota_iva = 21, exento_iva = ?, iva = total * 0.21", false).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("UPDATE ventas SET retenciones = 0").Error; err != nil {
+					return err
+				}
+				return tx.Exec(`INSERT INTO impuestos_venta (venta_id, tipo, concepto, descripcion, alicuota, base_imponible, importe)
+					SELECT id, 'iva', 'iva_21', 'IVA 21%', 21, subtotal, impuestos FROM ventas`).Error
+			},
+			Bajar: func(tx *gorm.DB) error {
+				if err := eliminarTablas(tx, &impuestoVentaV11{}); err != nil {
+					return err
+				}
+				for _, columna := range []string{"AlicuotaIVA", "ExentoIVA", "IVA"} {
+					if err := tx.Migrator().DropColumn(&detalleVentaV11{}, columna); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropColumn(&ventaV11{}, "Retenciones")
+			},
+		},
+		{
+			Version: 12,
+			Nombre:  "referencia_movimientos_stock",
+			Subir: func(tx *gorm.DB) error {
+				return tx.Migrator().CreateIndex(&movimientoStockV12{}, "idx_movimientos_referencia")
+			},
+			Bajar: func(tx *gorm.DB) error {
+				return tx.Migrator().DropIndex(&movimientoStockV12{}, "idx_movimientos_referencia")
+			},
+		},
+		{
+			Version: 13,
+			Nombre:  "reclamo_eventos_outbox",
+			Subir: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&eventoOutboxV13{})
+			},
+			Bajar: func(tx *gorm.DB) error {
+				for _, columna := range []string{"DescartadoEn", "ReclamadoPor", "ReclamadoHasta"} {
+					if err := tx.Migrator().DropColumn(&eventoOutboxV13{}, columna); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Modelos de la versión 1 del esquema
+
+type sucursalV1 struct {
+	ID                   uint   `gorm:"primaryKey"`
+	Nombre               string `gorm:"size:150;not null"`
+	Direccion            string `gorm:"size:255"`
+	Telefono             string `gorm:"size:30"`
+	Email                string `gorm:"size:150"`
+	Ciudad               string `gorm:"size:100"`
+	Estado               string `gorm:"size:20;index"`
+	FechaApertura        time.Time
+	APIEndpoint          string
+	APIKey               string
+	APISecret            string
+	TipoSistema          string
+	Configuracion        string `gorm:"type:text"`
+	UltimaSincronizacion time.Time
+}
+
+func (sucursalV1) TableName() string { return "sucursales" }
+
+type productoV1 struct {
+	ID                  uint   `gorm:"primaryKey"`
+	SKU                 string `gorm:"size:64;not null;uniqueIndex"`
+	Nombre              string `gorm:"size:200;not null"`
+	Descripcion         string `gorm:"type:text"`
+	Categoria           string `gorm:"size:100;index"`
+	Fabricante          string `gorm:"size:100"`
+	Precio              float64
+	PrecioOferta        float64
+	StockMinimo         int
+	StockActual         int
+	Estado              string    `gorm:"size:20"`
+	FechaCreacion       time.Time `gorm:"autoCreateTime"`
+	UltimaActualizacion time.Time `gorm:"autoUpdateTime"`
+}
+
+func (productoV1) TableName() string { return "productos" }
+
+type ventaV1 struct {
+	ID            uint        `gorm:"primaryKey"`
+	SucursalID    uint        `gorm:"not null;index:idx_ventas_sucursal_fecha"`
+	Sucursal      *sucursalV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ClienteID     uint        `gorm:"index"`
+	FechaVenta    time.Time   `gorm:"index:idx_ventas_sucursal_fecha"`
+	Total         float64
+	Subtotal      float64
+	Impuestos     float64
+	Descuento     float64
+	Estado        string           `gorm:"size:20;index"`
+	MetodoPago    string           `gorm:"size:30"`
+	DetallesVenta []detalleVentaV1 `gorm:"foreignKey:VentaID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (ventaV1) TableName() string { return "ventas" }
+
+type detalleVentaV1 struct {
+	ID             uint       `gorm:"primaryKey"`
+	VentaID        uint       `gorm:"not null;index"`
+	ProductoID     uint       `gorm:"not null;index"`
+	Producto       productoV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Cantidad       int
+	PrecioUnitario float64
+	Total          float64
+	Descuento      float64
+}
+
+func (detalleVentaV1) TableName() string { return "detalles_venta" }
+
+// Modelos de la versión 2 del esquema
+
+type precioHistoricoV2 struct {
+	ID                   uint        `gorm:"primaryKey"`
+	ProductoID           uint        `gorm:"not null;index:idx_precios_producto_vigencia"`
+	Producto             *productoV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	PrecioAnterior       float64
+	PrecioOfertaAnterior float64
+	Precio               float64
+	PrecioOferta         float64
+	SucursalID           uint
+	LoteID               string    `gorm:"size:64"`
+	VigenteDesde         time.Time `gorm:"not null;index:idx_precios_producto_vigencia"`
+	VigenteHasta         *time.Time
+}
+
+func (precioHistoricoV2) TableName() string { return "precios_historicos" }
+
+// Modelos de la versión 3 del esquema
+
+type movimientoStockV3 struct {
+	ID              uint        `gorm:"primaryKey"`
+	ProductoID      uint        `gorm:"not null;index:idx_movimientos_producto_sucursal"`
+	Producto        *productoV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	SucursalID      uint        `gorm:"index:idx_movimientos_producto_sucursal"`
+	Tipo            string      `gorm:"size:20;not null"`
+	Cantidad        int
+	StockResultante int
+	Referencia      string    `gorm:"size:100"`
+	LoteID          string    `gorm:"size:64"`
+	Fecha           time.Time `gorm:"not null;index"`
+}
+
+func (movimientoStockV3) TableName() string { return "movimientos_stock" }
+
+// Modelos de la versión 4 del esquema
+
+type eventoOutboxV4 struct {
+	ID          uint       `gorm:"primaryKey"`
+	Tipo        string     `gorm:"size:100;not null"`
+	Origen      string     `gorm:"size:100"`
+	Datos       string     `gorm:"type:text"`
+	CreadoEn    time.Time  `gorm:"not null"`
+	EntregadoEn *time.Time `gorm:"index"`
+	Intentos    int
+	UltimoError string `gorm:"size:500"`
+}
+
+func (eventoOutboxV4) TableName() string { return "eventos_outbox" }
+
+// Modelos de la versión 5 del esquema
+
+type loteArchivadoV5 struct {
+	ID                    uint   `gorm:"primaryKey"`
+	LoteID                string `gorm:"size:64;not null;uniqueIndex"`
+	Hash                  string `gorm:"size:64;not null;index"`
+	SucursalID            uint   `gorm:"index"`
+	Tipo                  string `gorm:"size:20"`
+	Origen                string `gorm:"size:255"`
+	Registros             int
+	TamanoOriginal        int64
+	TamanoComprimido      int64
+	RecibidoEn            time.Time `gorm:"not null;index"`
+	ReprocesoDe           string    `gorm:"size:64"`
+	ProcesadoEn           *time.Time
+	RegistrosValidos      int
+	RegistrosFiltrados    int
+	RegistrosUnicos       int
+	RegistrosPersistidos  int
+	RegistrosInsertados   int
+	RegistrosActualizados int
+	RegistrosSinCambios   int
+	RegistrosFallidos     int
+	Error                 string `gorm:"size:500"`
+}
+
+func (loteArchivadoV5) TableName() string { return "lotes_archivados" }
+
+// Modelos de la versión 6 del esquema
+
+type registroPurgaV6 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Clase        string `gorm:"size:30;not null;index"`
+	Accion       string `gorm:"size:20;not null"`
+	AntesDe      time.Time
+	Registros    int
+	Lotes        int
+	IniciadaEn   time.Time `gorm:"not null;index"`
+	FinalizadaEn time.Time
+	Error        string `gorm:"size:500"`
+}
+
+func (registroPurgaV6) TableName() string { return "purgas" }
+
+// Modelos de la versión 7 del esquema
+
+type clientePotencialV7 struct {
+	ID                  uint        `gorm:"primaryKey"`
+	Nombre              string      `gorm:"size:150;not null"`
+	Email               string      `gorm:"size:150;index"`
+	Telefono            string      `gorm:"size:30"`
+	Fuente              string      `gorm:"size:50;index"`
+	FechaCaptura        time.Time   `gorm:"not null;index"`
+	Interes             string      `gorm:"size:100"`
+	Notas               string      `gorm:"type:text"`
+	Estado              string      `gorm:"size:20;not null;index"`
+	SucursalID          uint        `gorm:"not null;index"`
+	Sucursal            *sucursalV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	AnonimizadoEn       *time.Time
+	FechaCreacion       time.Time `gorm:"autoCreateTime"`
+	UltimaActualizacion time.Time `gorm:"autoUpdateTime"`
+}
+
+func (clientePotencialV7) TableName() string { return "clientes_potenciales" }
+
+// Modelos de la versión 8 del esquema
+
+type clientePotencialV8 struct {
+	clientePotencialV7
+	EstadoActualizadoEn  *time.Time
+	EstadoActualizadoPor string `gorm:"size:100"`
+	ClienteID            *uint  `gorm:"index"`
+}
+
+type clienteV8 struct {
+	ID                  uint                `gorm:"primaryKey"`
+	Nombre              string              `gorm:"size:150;not null"`
+	Email               string              `gorm:"size:150;index"`
+	Telefono            string              `gorm:"size:30"`
+	SucursalID          uint                `gorm:"not null;index"`
+	Sucursal            *sucursalV1         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	ClientePotencialID  *uint               `gorm:"uniqueIndex"`
+	ClientePotencial    *clientePotencialV7 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	FechaCreacion       time.Time           `gorm:"autoCreateTime"`
+	UltimaActualizacion time.Time           `gorm:"autoUpdateTime"`
+}
+
+func (clienteV8) TableName() string { return "clientes" }
+
+type transicionClienteV8 struct {
+	ID                 uint                `gorm:"primaryKey"`
+	ClientePotencialID uint                `gorm:"not null;index"`
+	ClientePotencial   *clientePotencialV7 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Desde              string              `gorm:"size:20;not null"`
+	Hasta              string              `gorm:"size:20;not null;index"`
+	Usuario            string              `gorm:"size:100;not null"`
+	Motivo             string              `gorm:"size:500"`
+	Fecha              time.Time           `gorm:"not null;index"`
+}
+
+func (transicionClienteV8) TableName() string { return "transiciones_clientes" }
+
+// Modelos de la versión 9 del esquema
+
+type clientePotencialV9 struct {
+	clientePotencialV8
+	Puntaje            float64 `gorm:"index"`
+	FactoresPuntaje    string  `gorm:"type:text"`
+	PuntajeCalculadoEn *time.Time
+}
+
+type interaccionClienteV9 struct {
+	ID                 uint                `gorm:"primaryKey"`
+	ClientePotencialID uint                `gorm:"not null;index"`
+	ClientePotencial   *clientePotencialV7 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tipo               string              `gorm:"size:30;not null"`
+	Usuario            string              `gorm:"size:100"`
+	Fecha              time.Time           `gorm:"not null;index"`
+}
+
+func (interaccionClienteV9) TableName() string { return "interacciones_clientes" }
+
+// Modelos de la versión 10 del esquema
+
+type detalleVentaV10 struct {
+	detalleVentaV1
+	CantidadDevuelta int
+}
+
+type transicionVentaV10 struct {
+	ID      uint      `gorm:"primaryKey"`
+	VentaID uint      `gorm:"not null;index"`
+	Venta   *ventaV1  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Desde   string    `gorm:"size:20;not null"`
+	Hasta   string    `gorm:"size:20;not null;index"`
+	Usuario string    `gorm:"size:100;not null"`
+	Motivo  string    `gorm:"size:500"`
+	Fecha   time.Time `gorm:"not null;index"`
+}
+
+func (transicionVentaV10) TableName() string { return "transiciones_ventas" }
+
+// Modelos de la versión 11 del esquema
+
+type ventaV11 struct {
+	ventaV1
+	Retenciones float64
+}
+
+type detalleVentaV11 struct {
+	detalleVentaV10
+	AlicuotaIVA float64
+	ExentoIVA   bool
+	IVA         float64
+}
+
+type impuestoVentaV11 struct {
+	ID            uint     `gorm:"primaryKey"`
+	VentaID       uint     `gorm:"not null;index"`
+	Venta         *ventaV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tipo          string   `gorm:"size:20;not null"`
+	Concepto      string   `gorm:"size:50;not null"`
+	Descripcion   string   `gorm:"size:100"`
+	Alicuota      float64
+	BaseImponible float64
+	Importe       float64
+}
+
+func (impuestoVentaV11) TableName() string { return "impuestos_venta" }
+
+// Modelos de la versión 12 del esquema
+
+type movimientoStockV12 struct {
+	ID              uint        `gorm:"primaryKey"`
+	ProductoID      uint        `gorm:"not null;index:idx_movimientos_producto_sucursal"`
+	Producto        *productoV1 `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	SucursalID      uint        `gorm:"index:idx_movimientos_producto_sucursal"`
+	Tipo            string      `gorm:"size:20;not null"`
+	Cantidad        int
+	StockResultante int
+	Referencia      string    `gorm:"size:100;index:idx_movimientos_referencia"`
+	LoteID          string    `gorm:"size:64"`
+	Fecha           time.Time `gorm:"not null;index"`
+}
+
+func (movimientoStockV12) TableName() string { return "movimientos_stock" }
+
+// Modelos de la versión 13 del esquema
+
+type eventoOutboxV13 struct {
+	eventoOutboxV4
+	DescartadoEn   *time.Time `gorm:"index"`
+	ReclamadoPor   string     `gorm:"size:100"`
+	ReclamadoHasta *time.Time
+}
+
+// eliminarTablas elimina las tablas de a una en el orden indicado, primero las
+// que referencian a otras, para no violar las claves foráneas
+func eliminarTablas(tx *gorm.DB, modelos ...interface{}) error {
+	for _, modelo := range modelos {
+		if err := tx.Migrator().DropTable(modelo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEsquemaDesactualizado indica que hay migraciones sin aplicar
+var ErrEsquemaDesactualizado = errors.New("el esquema de la base de datos está desactualizado")
+
+// Migracion es un cambio versionado del esquema. Subir aplica el cambio y Bajar
+// lo revierte. Las migraciones se compilan en el binario y no deben modificarse
+// una vez publicadas: los cambios posteriores se agregan como nuevas versiones.
+type Migracion struct {
+	Version uint
+	Nombre  string
+	Subir   func(tx *gorm.DB) error
+	Bajar   func(tx *gorm.DB) error
+}
+
+// RegistroMigracion es una fila de la tabla schema_migrations
+type RegistroMigracion struct {
+	Version    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Nombre     string    `gorm:"size:150;not null"`
+	AplicadaEn time.Time `gorm:"not null"`
+}
+
+// TableName define el nombre de la tabla de migraciones aplicadas
+func (RegistroMigracion) TableName() string {
+	return "schema_migrations"
+}
+
+// EstadoMigracion describe si una migración está aplicada
+type EstadoMigracion struct {
+	Version    uint       `json:"version"`
+	Nombre     string     `json:"nombre"`
+	Aplicada   bool       `json:"aplicada"`
+	AplicadaEn *time.Time `json:"aplicada_en,omitempty"`
+}
+
+// Migrador aplica y revierte las migraciones registrando su estado en schema_migrations
+type Migrador struct {
+	db          *gorm.DB
+	migraciones []Migracion
+}
+
+// NewMigrador crea un migrador con las migraciones del sistema
+func NewMigrador(db *gorm.DB) *Migrador {
+	return NewMigradorCon(db, Migraciones())
+}
+
+// NewMigradorCon crea un migrador con las migraciones indicadas, ordenadas por versión
+func NewMigradorCon(db *gorm.DB, migraciones []Migracion) *Migrador {
+	ordenadas := make([]Migracion, len(migraciones))
+	copy(ordenadas, migraciones)
+	sort.Slice(ordenadas, func(i, j int) bool { return ordenadas[i].Version < ordenadas[j].Version })
+
+	return &Migrador{db: db, migraciones: ordenadas}
+}
+
+// Subir aplica en orden las migraciones pendientes y retorna las aplicadas. Cada
+// migración se ejecuta en su propia transacción junto con su registro; en MySQL
+// las sentencias DDL confirman la transacción implícitamente, por lo que una
+// migración fallida puede requerir corrección manual.
+func (m *Migrador) Subir(ctx context.Context) ([]Migracion, error) {
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ejecutadas []Migracion
+	for _, migracion := range m.migraciones {
+		if _, existe := aplicadas[migracion.Version]; existe {
+			continue
+		}
+
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migracion.Subir(tx); err != nil {
+				return err
+			}
+			return tx.Create(&RegistroMigracion{
+				Version:    migracion.Version,
+				Nombre:     migracion.Nombre,
+				AplicadaEn: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return ejecutadas, fmt.Errorf("migración %04d_%s: %w", migracion.Version, migracion.Nombre, err)
+		}
+		ejecutadas = append(ejecutadas, migracion)
+	}
+
+	return ejecutadas, nil
+}
+
+// Bajar revierte las últimas migraciones aplicadas, hasta la cantidad indicada,
+// y retorna las revertidas
+func (m *Migrador) Bajar(ctx context.Context, cantidad int) ([]Migracion, error) {
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var revertidas []Migracion
+	for i := len(m.migraciones) - 1; i >= 0 && len(revertidas) < cantidad; i-- {
+		migracion := m.migraciones[i]
+		if _, existe := aplicadas[migracion.Version]; !existe {
+			continue
+		}
+
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migracion.Bajar(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&RegistroMigracion{}, migracion.Version).Error
+		})
+		if err != nil {
+			return revertidas, fmt.Errorf("revirtiendo migración %04d_%s: %w", migracion.Version, migracion.Nombre, err)
+		}
+		revertidas = append(revertidas, migracion)
+	}
+
+	return revertidas, nil
+}
+
+// Estado retorna todas las migraciones conocidas indicando cuáles están aplicadas
+func (m *Migrador) Estado(ctx context.Context) ([]EstadoMigracion, error) {
+	aplicadas, err := m.aplicadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	estados := make([]EstadoMigracion, 0, len(m.migraciones))
+	for _, migracion := range m.migraciones {
+		estado := EstadoMigracion{Version: migracion.Version, Nombre: migracion.Nombre}
+		if registro, existe := aplicadas[migracion.Version]; existe {
+			estado.Aplicada = true
+			estado.AplicadaEn = &registro.AplicadaEn
+		}
+		estados = append(estados, estado)
+	}
+	return estados, nil
+}
+
+// VerificarAlDia retorna ErrEsquemaDesactualizado si quedan migraciones pendientes
+func (m *Migrador) VerificarAlDia(ctx context.Context) error {
+	estados, err := m.Estado(ctx)
+	if err != nil {
+		return err
+	}
+
+	pendientes := 0
+	for _, estado := range estados {
+		if !estado.Aplicada {
+			pendientes++
+		}
+	}
+	if pendientes > 0 {
+		return fmt.Errorf("%w: %d migraciones pendientes", ErrEsquemaDesactualizado, pendientes)
+	}
+	return nil
+}
+
+// aplicadas retorna las migraciones registradas, creando la tabla de registro si no existe
+func (m *Migrador) aplicadas(ctx context.Context) (map[uint]RegistroMigracion, error) {
+	db := m.db.WithContext(ctx)
+	if err := db.AutoMigrate(&RegistroMigracion{}); err != nil {
+		return nil, fmt.Errorf("error preparando schema_migrations: %w", err)
+	}
+
+	var registros []RegistroMigracion
+	if err := db.Order("version").Find(&registros).Error; err != nil {
+		return nil, err
+	}
+
+	aplicadas := make(map[uint]RegistroMigracion, len(registros))
+	for _, registro := range registros {
+		aplicadas[registro.Version] = registro
+	}
+	return aplicadas, nil
+}
@@ -117,15 +117,24 @@ func CreateEvent(eventType string, data map[string]interface{}, source string) E
 
 // EventTypes define los tipos de eventos del sistema
 const (
-	EventDatosRecolectados        = "datos_recolectados"
-	EventDatosProcesados          = "datos_procesados"
-	EventDatosPersistidos         = "datos_persistidos"
-	EventReporteGenerado          = "reporte_generado"
-	EventErrorProcesamiento       = "error_procesamiento"
-	EventSincronizacionCompletada = "sincronizacion_completada"
-	EventClientePotencialCreado   = "cliente_potencial_creado"
-	EventVentaRegistrada          = "venta_registrada"
-	EventStockActualizado         = "stock_actualizado"
+	EventDatosRecolectados           = "datos_recolectados"
+	EventDatosProcesados             = "datos_procesados"
+	EventDatosPersistidos            = "datos_persistidos"
+	EventBloquePersistido            = "bloque_persistido"
+	EventReporteGenerado             = "reporte_generado"
+	EventErrorProcesamiento          = "error_procesamiento"
+	EventSincronizacionCompletada    = "sincronizacion_completada"
+	EventClientePotencialCreado      = "cliente_potencial_creado"
+	EventClientePotencialActualizado = "cliente_potencial_actualizado"
+	EventClientePotencialInteraccion = "cliente_potencial_interaccion"
+	EventClientePotencialEstado      = "cliente_potencial_estado"
+	EventClientePotencialConvertido  = "cliente_potencial_convertido"
+	EventVentaRegistrada             = "venta_registrada"
+	EventVentaEstado                 = "venta_estado"
+	EventStockActualizado            = "stock_actualizado"
+	EventReintentoProgramado         = "reintento_programado"
+	EventReintentosAgotados          = "reintentos_agotados"
+	EventDatosPurgados               = "datos_purgados"
 )
 
 // EventBusSingleton implementa el patrón Singleton para el bus de eventos
@@ -0,0 +1,210 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ClientePotencialRepositoryGorm implementa ClientePotencialRepository sobre GORM
+type ClientePotencialRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewClientePotencialRepositoryGorm crea un repositorio de clientes potenciales sobre la conexión indicada
+func NewClientePotencialRepositoryGorm(db *gorm.DB) *ClientePotencialRepositoryGorm {
+	return &ClientePotencialRepositoryGorm{db: db}
+}
+
+// ObtenerPorID retorna el cliente potencial con el ID indicado
+func (r *ClientePotencialRepositoryGorm) ObtenerPorID(ctx context.Context, id uint) (*entities.ClientePotencial, error) {
+	var cliente entities.ClientePotencial
+	if err := conexion(ctx, r.db).First(&cliente, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &cliente, nil
+}
+
+// Listar retorna la página de clientes que cumplen el filtro, en el orden
+// pedido, y el total de clientes que lo cumplen
+func (r *ClientePotencialRepositoryGorm) Listar(ctx context.Context, filtro repositories.FiltroClientes) ([]entities.ClientePotencial, int64, error) {
+	consulta := conexion(ctx, r.db).Model(&entities.ClientePotencial{})
+	if filtro.SucursalID != 0 {
+		consulta = consulta.Where("sucursal_id = ?", filtro.SucursalID)
+	}
+	if filtro.Fuente != "" {
+		consulta = consulta.Where("fuente = ?", filtro.Fuente)
+	}
+	if filtro.Estado != "" {
+		consulta = consulta.Where("estado = ?", filtro.Estado)
+	}
+
+	var total int64
+	if err := consulta.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orden := "fecha_captura DESC, id DESC"
+	if filtro.Orden == repositories.OrdenClientesPuntaje {
+		orden = "puntaje DESC, " + orden
+	}
+
+	var clientes []entities.ClientePotencial
+	err := consulta.
+		Order(orden).
+		Offset(filtro.Desplazamiento).
+		Limit(filtro.Limite).
+		Find(&clientes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return clientes, total, nil
+}
+
+// Guardar crea o reemplaza el cliente potencial
+func (r *ClientePotencialRepositoryGorm) Guardar(ctx context.Context, cliente *entities.ClientePotencial) error {
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Save(cliente).Error)
+}
+
+// Eliminar borra el cliente potencial con el ID indicado
+func (r *ClientePotencialRepositoryGorm) Eliminar(ctx context.Context, id uint) error {
+	resultado := conexion(ctx, r.db).Delete(&entities.ClientePotencial{}, id)
+	if resultado.Error != nil {
+		return traducirError(resultado.Error)
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// ActualizarDatos guarda los datos editables del cliente si no fue anonimizado
+func (r *ClientePotencialRepositoryGorm) ActualizarDatos(ctx context.Context, cliente *entities.ClientePotencial) error {
+	cliente.UltimaActualizacion = time.Now()
+	resultado := conexion(ctx, r.db).Model(&entities.ClientePotencial{}).
+		Where("id = ? AND anonimizado_en IS NULL", cliente.ID).
+		Select("Nombre", "Email", "Telefono", "Fuente", "FechaCaptura", "Interes", "Notas", "SucursalID", "UltimaActualizacion").
+		Updates(cliente)
+	if resultado.Error != nil {
+		return traducirError(resultado.Error)
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrModificacionConcurrente
+	}
+	return nil
+}
+
+// AnonimizarAnteriores borra los datos personales de los clientes captados
+// antes de la fecha, de los más antiguos a los más nuevos
+func (r *ClientePotencialRepositoryGorm) AnonimizarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	var ids []uint
+	err := conexion(ctx, r.db).Model(&entities.ClientePotencial{}).
+		Where("fecha_captura < ? AND anonimizado_en IS NULL", antesDe).
+		Order("fecha_captura, id").
+		Limit(limite).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var anonimo entities.ClientePotencial
+	anonimo.Anonimizar(time.Now())
+	resultado := conexion(ctx, r.db).Model(&entities.ClientePotencial{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"nombre":         anonimo.Nombre,
+			"email":          anonimo.Email,
+			"telefono":       anonimo.Telefono,
+			"notas":          anonimo.Notas,
+			"anonimizado_en": anonimo.AnonimizadoEn,
+		})
+	return int(resultado.RowsAffected), resultado.Error
+}
+
+// CambiarEstado guarda el estado del cliente si el guardado sigue siendo desde
+func (r *ClientePotencialRepositoryGorm) CambiarEstado(ctx context.Context, cliente *entities.ClientePotencial, desde string) error {
+	resultado := conexion(ctx, r.db).Model(&entities.ClientePotencial{}).
+		Where("id = ? AND estado = ?", cliente.ID, desde).
+		Updates(map[string]interface{}{
+			"estado":                 cliente.Estado,
+			"estado_actualizado_en":  cliente.EstadoActualizadoEn,
+			"estado_actualizado_por": cliente.EstadoActualizadoPor,
+			"cliente_id":             cliente.ClienteID,
+		})
+	if resultado.Error != nil {
+		return traducirError(resultado.Error)
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrModificacionConcurrente
+	}
+	return nil
+}
+
+// RegistrarTransicion agrega la transición al historial del cliente
+func (r *ClientePotencialRepositoryGorm) RegistrarTransicion(ctx context.Context, transicion *entities.TransicionCliente) error {
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Create(transicion).Error)
+}
+
+// ListarTransiciones retorna el historial de estados del cliente, del más antiguo al más nuevo
+func (r *ClientePotencialRepositoryGorm) ListarTransiciones(ctx context.Context, clienteID uint) ([]entities.TransicionCliente, error) {
+	var transiciones []entities.TransicionCliente
+	err := conexion(ctx, r.db).
+		Where("cliente_potencial_id = ?", clienteID).
+		Order("fecha, id").
+		Find(&transiciones).Error
+	if err != nil {
+		return nil, err
+	}
+	return transiciones, nil
+}
+
+// GuardarPuntaje guarda el puntaje del cliente y los factores que lo explican
+func (r *ClientePotencialRepositoryGorm) GuardarPuntaje(ctx context.Context, id uint, puntaje float64, factores []entities.FactorPuntaje, fecha time.Time) error {
+	resultado := conexion(ctx, r.db).Model(&entities.ClientePotencial{ID: id}).
+		Select("Puntaje", "FactoresPuntaje", "PuntajeCalculadoEn").
+		UpdateColumns(&entities.ClientePotencial{Puntaje: puntaje, FactoresPuntaje: factores, PuntajeCalculadoEn: &fecha})
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// ListarActivos retorna hasta limite clientes no convertidos ni descartados con ID mayor a despuesDeID
+func (r *ClientePotencialRepositoryGorm) ListarActivos(ctx context.Context, despuesDeID uint, limite int) ([]entities.ClientePotencial, error) {
+	var clientes []entities.ClientePotencial
+	err := conexion(ctx, r.db).
+		Where("id > ? AND estado NOT IN ?", despuesDeID, []string{entities.EstadoClienteConvertido, entities.EstadoClienteDescartado}).
+		Order("id").
+		Limit(limite).
+		Find(&clientes).Error
+	if err != nil {
+		return nil, err
+	}
+	return clientes, nil
+}
+
+// RegistrarInteraccion agrega la interacción al historial del cliente
+func (r *ClientePotencialRepositoryGorm) RegistrarInteraccion(ctx context.Context, interaccion *entities.InteraccionCliente) error {
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Create(interaccion).Error)
+}
+
+// ListarInteracciones retorna las interacciones del cliente, de la más antigua a la más nueva
+func (r *ClientePotencialRepositoryGorm) ListarInteracciones(ctx context.Context, clienteID uint) ([]entities.InteraccionCliente, error) {
+	var interacciones []entities.InteraccionCliente
+	err := conexion(ctx, r.db).
+		Where("cliente_potencial_id = ?", clienteID).
+		Order("fecha, id").
+		Find(&interacciones).Error
+	if err != nil {
+		return nil, err
+	}
+	return interacciones, nil
+}
@@ -0,0 +1,276 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ClientePotencialRepositoryMemoria implementa ClientePotencialRepository en memoria
+type ClientePotencialRepositoryMemoria struct {
+	clientes      map[uint]entities.ClientePotencial
+	transiciones  []entities.TransicionCliente
+	interacciones []entities.InteraccionCliente
+	siguienteID   uint
+	mutex         sync.RWMutex
+}
+
+// NewClientePotencialRepositoryMemoria crea un repositorio de clientes potenciales vacío
+func NewClientePotencialRepositoryMemoria() *ClientePotencialRepositoryMemoria {
+	return &ClientePotencialRepositoryMemoria{
+		clientes:    make(map[uint]entities.ClientePotencial),
+		siguienteID: 1,
+	}
+}
+
+// ObtenerPorID retorna el cliente potencial con el ID indicado
+func (r *ClientePotencialRepositoryMemoria) ObtenerPorID(ctx context.Context, id uint) (*entities.ClientePotencial, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cliente, existe := r.clientes[id]
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	return &cliente, nil
+}
+
+// Listar retorna la página de clientes que cumplen el filtro, en el orden
+// pedido, y el total de clientes que lo cumplen
+func (r *ClientePotencialRepositoryMemoria) Listar(ctx context.Context, filtro repositories.FiltroClientes) ([]entities.ClientePotencial, int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var clientes []entities.ClientePotencial
+	for _, cliente := range r.clientes {
+		switch {
+		case filtro.SucursalID != 0 && cliente.SucursalID != filtro.SucursalID:
+		case filtro.Fuente != "" && cliente.Fuente != filtro.Fuente:
+		case filtro.Estado != "" && cliente.Estado != filtro.Estado:
+		default:
+			clientes = append(clientes, cliente)
+		}
+	}
+	sort.Slice(clientes, func(i, j int) bool {
+		if filtro.Orden == repositories.OrdenClientesPuntaje && clientes[i].Puntaje != clientes[j].Puntaje {
+			return clientes[i].Puntaje > clientes[j].Puntaje
+		}
+		if !clientes[i].FechaCaptura.Equal(clientes[j].FechaCaptura) {
+			return clientes[i].FechaCaptura.After(clientes[j].FechaCaptura)
+		}
+		return clientes[i].ID > clientes[j].ID
+	})
+
+	total := int64(len(clientes))
+	inicio := min(filtro.Desplazamiento, len(clientes))
+	fin := len(clientes)
+	if filtro.Limite > 0 {
+		fin = min(inicio+filtro.Limite, len(clientes))
+	}
+	return clientes[inicio:fin], total, nil
+}
+
+// Guardar crea o reemplaza el cliente potencial, asignando un ID si no lo tiene
+func (r *ClientePotencialRepositoryMemoria) Guardar(ctx context.Context, cliente *entities.ClientePotencial) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ahora := time.Now()
+	if cliente.ID == 0 {
+		cliente.ID = r.siguienteID
+		cliente.FechaCreacion = ahora
+	}
+	if cliente.ID >= r.siguienteID {
+		r.siguienteID = cliente.ID + 1
+	}
+	cliente.UltimaActualizacion = ahora
+	r.clientes[cliente.ID] = *cliente
+	return nil
+}
+
+// ActualizarDatos guarda los datos editables del cliente si no fue anonimizado
+func (r *ClientePotencialRepositoryMemoria) ActualizarDatos(ctx context.Context, cliente *entities.ClientePotencial) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	guardado, existe := r.clientes[cliente.ID]
+	if !existe || guardado.AnonimizadoEn != nil {
+		return repositories.ErrModificacionConcurrente
+	}
+	guardado.Nombre = cliente.Nombre
+	guardado.Email = cliente.Email
+	guardado.Telefono = cliente.Telefono
+	guardado.Fuente = cliente.Fuente
+	guardado.FechaCaptura = cliente.FechaCaptura
+	guardado.Interes = cliente.Interes
+	guardado.Notas = cliente.Notas
+	guardado.SucursalID = cliente.SucursalID
+	guardado.UltimaActualizacion = time.Now()
+	cliente.UltimaActualizacion = guardado.UltimaActualizacion
+	r.clientes[cliente.ID] = guardado
+	return nil
+}
+
+// Eliminar borra el cliente potencial con el ID indicado
+func (r *ClientePotencialRepositoryMemoria) Eliminar(ctx context.Context, id uint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, existe := r.clientes[id]; !existe {
+		return repositories.ErrNoEncontrado
+	}
+	delete(r.clientes, id)
+
+	conservadas := r.transiciones[:0]
+	for _, transicion := range r.transiciones {
+		if transicion.ClientePotencialID != id {
+			conservadas = append(conservadas, transicion)
+		}
+	}
+	r.transiciones = conservadas
+
+	interacciones := r.interacciones[:0]
+	for _, interaccion := range r.interacciones {
+		if interaccion.ClientePotencialID != id {
+			interacciones = append(interacciones, interaccion)
+		}
+	}
+	r.interacciones = interacciones
+	return nil
+}
+
+// CambiarEstado guarda el estado del cliente si el guardado sigue siendo desde
+func (r *ClientePotencialRepositoryMemoria) CambiarEstado(ctx context.Context, cliente *entities.ClientePotencial, desde string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	guardado, existe := r.clientes[cliente.ID]
+	if !existe || guardado.Estado != desde {
+		return repositories.ErrModificacionConcurrente
+	}
+	guardado.Estado = cliente.Estado
+	guardado.EstadoActualizadoEn = cliente.EstadoActualizadoEn
+	guardado.EstadoActualizadoPor = cliente.EstadoActualizadoPor
+	guardado.ClienteID = cliente.ClienteID
+	guardado.UltimaActualizacion = time.Now()
+	r.clientes[cliente.ID] = guardado
+	return nil
+}
+
+// RegistrarTransicion agrega la transición al historial del cliente
+func (r *ClientePotencialRepositoryMemoria) RegistrarTransicion(ctx context.Context, transicion *entities.TransicionCliente) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, existe := r.clientes[transicion.ClientePotencialID]; !existe {
+		return repositories.ErrReferenciaInvalida
+	}
+	transicion.ID = uint(len(r.transiciones) + 1)
+	r.transiciones = append(r.transiciones, *transicion)
+	return nil
+}
+
+// ListarTransiciones retorna el historial de estados del cliente, del más antiguo al más nuevo
+func (r *ClientePotencialRepositoryMemoria) ListarTransiciones(ctx context.Context, clienteID uint) ([]entities.TransicionCliente, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var transiciones []entities.TransicionCliente
+	for _, transicion := range r.transiciones {
+		if transicion.ClientePotencialID == clienteID {
+			transiciones = append(transiciones, transicion)
+		}
+	}
+	return transiciones, nil
+}
+
+// AnonimizarAnteriores borra los datos personales de los clientes captados
+// antes de la fecha, de los más antiguos a los más nuevos
+func (r *ClientePotencialRepositoryMemoria) AnonimizarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var vencidos []entities.ClientePotencial
+	for _, cliente := range r.clientes {
+		if cliente.AnonimizadoEn == nil && cliente.FechaCaptura.Before(antesDe) {
+			vencidos = append(vencidos, cliente)
+		}
+	}
+	sort.Slice(vencidos, func(i, j int) bool { return vencidos[i].FechaCaptura.Before(vencidos[j].FechaCaptura) })
+	if len(vencidos) > limite {
+		vencidos = vencidos[:limite]
+	}
+
+	ahora := time.Now()
+	for _, cliente := range vencidos {
+		cliente.Anonimizar(ahora)
+		r.clientes[cliente.ID] = cliente
+	}
+	return len(vencidos), nil
+}
+
+// GuardarPuntaje guarda el puntaje del cliente y los factores que lo explican
+func (r *ClientePotencialRepositoryMemoria) GuardarPuntaje(ctx context.Context, id uint, puntaje float64, factores []entities.FactorPuntaje, fecha time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cliente, existe := r.clientes[id]
+	if !existe {
+		return repositories.ErrNoEncontrado
+	}
+	cliente.Puntaje = puntaje
+	cliente.FactoresPuntaje = factores
+	cliente.PuntajeCalculadoEn = &fecha
+	r.clientes[id] = cliente
+	return nil
+}
+
+// ListarActivos retorna hasta limite clientes no convertidos ni descartados con ID mayor a despuesDeID
+func (r *ClientePotencialRepositoryMemoria) ListarActivos(ctx context.Context, despuesDeID uint, limite int) ([]entities.ClientePotencial, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var clientes []entities.ClientePotencial
+	for _, cliente := range r.clientes {
+		if cliente.ID > despuesDeID && len(cliente.TransicionesPosibles()) > 0 {
+			clientes = append(clientes, cliente)
+		}
+	}
+	sort.Slice(clientes, func(i, j int) bool { return clientes[i].ID < clientes[j].ID })
+	if len(clientes) > limite {
+		clientes = clientes[:limite]
+	}
+	return clientes, nil
+}
+
+// RegistrarInteraccion agrega la interacción al historial del cliente
+func (r *ClientePotencialRepositoryMemoria) RegistrarInteraccion(ctx context.Context, interaccion *entities.InteraccionCliente) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, existe := r.clientes[interaccion.ClientePotencialID]; !existe {
+		return repositories.ErrReferenciaInvalida
+	}
+	interaccion.ID = uint(len(r.interacciones) + 1)
+	r.interacciones = append(r.interacciones, *interaccion)
+	return nil
+}
+
+// ListarInteracciones retorna las interacciones del cliente, de la más antigua a la más nueva
+func (r *ClientePotencialRepositoryMemoria) ListarInteracciones(ctx context.Context, clienteID uint) ([]entities.InteraccionCliente, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var interacciones []entities.InteraccionCliente
+	for _, interaccion := range r.interacciones {
+		if interaccion.ClientePotencialID == clienteID {
+			interacciones = append(interacciones, interaccion)
+		}
+	}
+	sort.SliceStable(interacciones, func(i, j int) bool { return interacciones[i].Fecha.Before(interacciones[j].Fecha) })
+	return interacciones, nil
+}
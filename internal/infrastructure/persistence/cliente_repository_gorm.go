@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// ClienteRepositoryGorm implementa ClienteRepository sobre GORM
+type ClienteRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewClienteRepositoryGorm crea un repositorio de clientes sobre la conexión indicada
+func NewClienteRepositoryGorm(db *gorm.DB) *ClienteRepositoryGorm {
+	return &ClienteRepositoryGorm{db: db}
+}
+
+// ObtenerPorID retorna el cliente con el ID indicado
+func (r *ClienteRepositoryGorm) ObtenerPorID(ctx context.Context, id uint) (*entities.Cliente, error) {
+	var cliente entities.Cliente
+	if err := conexion(ctx, r.db).First(&cliente, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &cliente, nil
+}
+
+// Guardar crea o reemplaza el cliente
+func (r *ClienteRepositoryGorm) Guardar(ctx context.Context, cliente *entities.Cliente) error {
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Save(cliente).Error)
+}
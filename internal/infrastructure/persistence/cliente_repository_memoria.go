@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ClienteRepositoryMemoria implementa ClienteRepository en memoria
+type ClienteRepositoryMemoria struct {
+	clientes    map[uint]entities.Cliente
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewClienteRepositoryMemoria crea un repositorio de clientes vacío
+func NewClienteRepositoryMemoria() *ClienteRepositoryMemoria {
+	return &ClienteRepositoryMemoria{
+		clientes:    make(map[uint]entities.Cliente),
+		siguienteID: 1,
+	}
+}
+
+// ObtenerPorID retorna el cliente con el ID indicado
+func (r *ClienteRepositoryMemoria) ObtenerPorID(ctx context.Context, id uint) (*entities.Cliente, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	cliente, existe := r.clientes[id]
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	return &cliente, nil
+}
+
+// Guardar crea o reemplaza el cliente, asignando un ID si no lo tiene. Un
+// cliente potencial se convierte en un único cliente.
+func (r *ClienteRepositoryMemoria) Guardar(ctx context.Context, cliente *entities.Cliente) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if cliente.ClientePotencialID != nil {
+		for id, existente := range r.clientes {
+			if id != cliente.ID && existente.ClientePotencialID != nil && *existente.ClientePotencialID == *cliente.ClientePotencialID {
+				return repositories.ErrDuplicado
+			}
+		}
+	}
+
+	ahora := time.Now()
+	if cliente.ID == 0 {
+		cliente.ID = r.siguienteID
+		cliente.FechaCreacion = ahora
+	}
+	if cliente.ID >= r.siguienteID {
+		r.siguienteID = cliente.ID + 1
+	}
+	cliente.UltimaActualizacion = ahora
+	r.clientes[cliente.ID] = *cliente
+	return nil
+}
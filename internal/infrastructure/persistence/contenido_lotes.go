@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// comprimir comprime el contenido de un lote con gzip
+func comprimir(contenido []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	escritor := gzip.NewWriter(&buffer)
+	if _, err := escritor.Write(contenido); err != nil {
+		return nil, err
+	}
+	if err := escritor.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// descomprimir descomprime el contenido de un lote y verifica que corresponda al hash
+func descomprimir(comprimido []byte, hash string) ([]byte, error) {
+	lector, err := gzip.NewReader(bytes.NewReader(comprimido))
+	if err != nil {
+		return nil, fmt.Errorf("contenido %s dañado: %v", hash, err)
+	}
+	defer lector.Close()
+
+	contenido, err := io.ReadAll(lector)
+	if err != nil {
+		return nil, fmt.Errorf("contenido %s dañado: %v", hash, err)
+	}
+	if entities.HashContenido(contenido) != hash {
+		return nil, fmt.Errorf("contenido %s dañado: no coincide con su hash", hash)
+	}
+	return contenido, nil
+}
+
+// truncarError limita el largo de un mensaje de error guardado
+func truncarError(motivo string) string {
+	if len(motivo) > longitudMaximaError {
+		return motivo[:longitudMaximaError]
+	}
+	return motivo
+}
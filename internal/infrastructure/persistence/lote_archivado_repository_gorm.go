@@ -0,0 +1,212 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// LoteArchivadoRepositoryGorm implementa LoteArchivadoRepository guardando la
+// descripción de los lotes con GORM y su contenido comprimido en archivos
+// <directorio>/<2 primeros caracteres del hash>/<hash>.json.gz
+type LoteArchivadoRepositoryGorm struct {
+	db         *gorm.DB
+	directorio string
+}
+
+// NewLoteArchivadoRepositoryGorm crea un archivo de lotes sobre la conexión y el directorio indicados
+func NewLoteArchivadoRepositoryGorm(db *gorm.DB, directorio string) *LoteArchivadoRepositoryGorm {
+	return &LoteArchivadoRepositoryGorm{db: db, directorio: directorio}
+}
+
+// Archivar escribe el contenido del lote si no existe un archivo con su hash y
+// registra el lote
+func (r *LoteArchivadoRepositoryGorm) Archivar(ctx context.Context, lote *entities.LoteArchivado, contenido []byte) error {
+	lote.Hash = entities.HashContenido(contenido)
+	lote.TamanoOriginal = int64(len(contenido))
+
+	comprimido, err := comprimir(contenido)
+	if err != nil {
+		return err
+	}
+	lote.TamanoComprimido = int64(len(comprimido))
+
+	if err := r.escribirContenido(lote.Hash, comprimido); err != nil {
+		return err
+	}
+	return traducirError(conexion(ctx, r.db).Create(lote).Error)
+}
+
+// escribirContenido guarda el contenido comprimido, salvo que ya exista. Se
+// escribe en un archivo temporal que luego se renombra, para que un corte no
+// deje un archivo incompleto con el nombre del hash. Si ya existe se actualiza
+// su fecha de modificación, para que una purga en curso no lo elimine.
+func (r *LoteArchivadoRepositoryGorm) escribirContenido(hash string, comprimido []byte) error {
+	ruta := r.ruta(hash)
+	if _, err := os.Stat(ruta); err == nil {
+		ahora := time.Now()
+		return os.Chtimes(ruta, ahora, ahora)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ruta), 0o755); err != nil {
+		return fmt.Errorf("error creando el directorio del archivo de lotes: %v", err)
+	}
+	temporal, err := os.CreateTemp(filepath.Dir(ruta), hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporal.Name())
+
+	if _, err := temporal.Write(comprimido); err != nil {
+		temporal.Close()
+		return err
+	}
+	if err := temporal.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporal.Name(), ruta)
+}
+
+// RegistrarResultado guarda el resultado del procesamiento del lote
+func (r *LoteArchivadoRepositoryGorm) RegistrarResultado(ctx context.Context, loteID string, resumen entities.ResumenProcesamiento, motivoError string, procesadoEn time.Time) error {
+	resultado := conexion(ctx, r.db).Model(&entities.LoteArchivado{}).
+		Where("lote_id = ?", loteID).
+		Updates(map[string]interface{}{
+			"procesado_en":           procesadoEn,
+			"registros_validos":      resumen.Validos,
+			"registros_filtrados":    resumen.Filtrados,
+			"registros_unicos":       resumen.Unicos,
+			"registros_persistidos":  resumen.Persistidos,
+			"registros_insertados":   resumen.Insertados,
+			"registros_actualizados": resumen.Actualizados,
+			"registros_sin_cambios":  resumen.SinCambios,
+			"registros_fallidos":     resumen.Fallidos,
+			"error":                  truncarError(motivoError),
+		})
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// ObtenerPorLoteID retorna el lote archivado con el ID de lote indicado
+func (r *LoteArchivadoRepositoryGorm) ObtenerPorLoteID(ctx context.Context, loteID string) (*entities.LoteArchivado, error) {
+	var lote entities.LoteArchivado
+	if err := conexion(ctx, r.db).Where("lote_id = ?", loteID).First(&lote).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &lote, nil
+}
+
+// Listar retorna los lotes archivados que cumplen el filtro por fecha de recepción
+func (r *LoteArchivadoRepositoryGorm) Listar(ctx context.Context, filtro repositories.FiltroLotesArchivados) ([]entities.LoteArchivado, error) {
+	consulta := conexion(ctx, r.db)
+	if len(filtro.LoteIDs) > 0 {
+		consulta = consulta.Where("lote_id IN ?", filtro.LoteIDs)
+	}
+	if filtro.SucursalID != 0 {
+		consulta = consulta.Where("sucursal_id = ?", filtro.SucursalID)
+	}
+	if filtro.Tipo != "" {
+		consulta = consulta.Where("tipo = ?", filtro.Tipo)
+	}
+	if !filtro.Desde.IsZero() {
+		consulta = consulta.Where("recibido_en >= ?", filtro.Desde)
+	}
+	if !filtro.Hasta.IsZero() {
+		consulta = consulta.Where("recibido_en < ?", filtro.Hasta)
+	}
+
+	var lotes []entities.LoteArchivado
+	if err := consulta.Order("recibido_en, id").Find(&lotes).Error; err != nil {
+		return nil, err
+	}
+	return lotes, nil
+}
+
+// LeerContenido lee y descomprime el contenido con el hash indicado
+func (r *LoteArchivadoRepositoryGorm) LeerContenido(ctx context.Context, hash string) ([]byte, error) {
+	comprimido, err := os.ReadFile(r.ruta(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, repositories.ErrNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return descomprimir(comprimido, hash)
+}
+
+// PurgarAnteriores elimina los lotes recibidos antes de la fecha y el contenido
+// que quedó sin lotes. No se elimina un contenido modificado después de la
+// fecha, que pertenece a un lote archivado mientras se purgaba.
+func (r *LoteArchivadoRepositoryGorm) PurgarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	var lotes []entities.LoteArchivado
+	err := conexion(ctx, r.db).
+		Where("recibido_en < ?", antesDe).
+		Order("id").
+		Limit(limite).
+		Find(&lotes).Error
+	if err != nil || len(lotes) == 0 {
+		return 0, err
+	}
+
+	ids := make([]uint, 0, len(lotes))
+	hashes := make(map[string]bool)
+	for _, lote := range lotes {
+		ids = append(ids, lote.ID)
+		hashes[lote.Hash] = true
+	}
+	if err := conexion(ctx, r.db).Where("id IN ?", ids).Delete(&entities.LoteArchivado{}).Error; err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for hash := range hashes {
+		var restantes int64
+		if err := conexion(ctx, r.db).Model(&entities.LoteArchivado{}).Where("hash = ?", hash).Count(&restantes).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if restantes == 0 {
+			if err := r.eliminarContenido(hash, antesDe); err != nil {
+				errs = append(errs, fmt.Errorf("error eliminando el contenido %s: %v", hash, err))
+			}
+		}
+	}
+	return len(lotes), errors.Join(errs...)
+}
+
+// eliminarContenido elimina el archivo del contenido si no fue modificado después de la fecha
+func (r *LoteArchivadoRepositoryGorm) eliminarContenido(hash string, antesDe time.Time) error {
+	ruta := r.ruta(hash)
+	info, err := os.Stat(ruta)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().After(antesDe) {
+		return nil
+	}
+	return os.Remove(ruta)
+}
+
+// ruta retorna el archivo del contenido con el hash indicado
+func (r *LoteArchivadoRepositoryGorm) ruta(hash string) string {
+	return filepath.Join(r.directorio, hash[:2], hash+".json.gz")
+}
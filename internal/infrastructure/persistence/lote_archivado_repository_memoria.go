@@ -0,0 +1,150 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// LoteArchivadoRepositoryMemoria implementa LoteArchivadoRepository en memoria
+type LoteArchivadoRepositoryMemoria struct {
+	lotes       []entities.LoteArchivado
+	contenidos  map[string][]byte // contenido comprimido por hash
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewLoteArchivadoRepositoryMemoria crea un archivo de lotes vacío
+func NewLoteArchivadoRepositoryMemoria() *LoteArchivadoRepositoryMemoria {
+	return &LoteArchivadoRepositoryMemoria{contenidos: make(map[string][]byte), siguienteID: 1}
+}
+
+// Archivar guarda el contenido comprimido, si no estaba guardado, y el lote
+func (r *LoteArchivadoRepositoryMemoria) Archivar(ctx context.Context, lote *entities.LoteArchivado, contenido []byte) error {
+	lote.Hash = entities.HashContenido(contenido)
+	lote.TamanoOriginal = int64(len(contenido))
+
+	comprimido, err := comprimir(contenido)
+	if err != nil {
+		return err
+	}
+	lote.TamanoComprimido = int64(len(comprimido))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existente := range r.lotes {
+		if existente.LoteID == lote.LoteID {
+			return repositories.ErrDuplicado
+		}
+	}
+	if _, existe := r.contenidos[lote.Hash]; !existe {
+		r.contenidos[lote.Hash] = comprimido
+	}
+	lote.ID = r.siguienteID
+	r.siguienteID++
+	r.lotes = append(r.lotes, *lote)
+	return nil
+}
+
+// RegistrarResultado guarda el resultado del procesamiento del lote
+func (r *LoteArchivadoRepositoryMemoria) RegistrarResultado(ctx context.Context, loteID string, resumen entities.ResumenProcesamiento, motivoError string, procesadoEn time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.lotes {
+		if r.lotes[i].LoteID == loteID {
+			r.lotes[i].ProcesadoEn = &procesadoEn
+			r.lotes[i].Resumen = resumen
+			r.lotes[i].Error = truncarError(motivoError)
+			return nil
+		}
+	}
+	return repositories.ErrNoEncontrado
+}
+
+// ObtenerPorLoteID retorna el lote archivado con el ID de lote indicado
+func (r *LoteArchivadoRepositoryMemoria) ObtenerPorLoteID(ctx context.Context, loteID string) (*entities.LoteArchivado, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, lote := range r.lotes {
+		if lote.LoteID == loteID {
+			return &lote, nil
+		}
+	}
+	return nil, repositories.ErrNoEncontrado
+}
+
+// Listar retorna los lotes archivados que cumplen el filtro por fecha de recepción
+func (r *LoteArchivadoRepositoryMemoria) Listar(ctx context.Context, filtro repositories.FiltroLotesArchivados) ([]entities.LoteArchivado, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	incluidos := make(map[string]bool, len(filtro.LoteIDs))
+	for _, loteID := range filtro.LoteIDs {
+		incluidos[loteID] = true
+	}
+
+	var lotes []entities.LoteArchivado
+	for _, lote := range r.lotes {
+		switch {
+		case len(incluidos) > 0 && !incluidos[lote.LoteID]:
+		case filtro.SucursalID != 0 && lote.SucursalID != filtro.SucursalID:
+		case filtro.Tipo != "" && lote.Tipo != filtro.Tipo:
+		case !filtro.Desde.IsZero() && lote.RecibidoEn.Before(filtro.Desde):
+		case !filtro.Hasta.IsZero() && !lote.RecibidoEn.Before(filtro.Hasta):
+		default:
+			lotes = append(lotes, lote)
+		}
+	}
+	sort.SliceStable(lotes, func(i, j int) bool {
+		return lotes[i].RecibidoEn.Before(lotes[j].RecibidoEn)
+	})
+	return lotes, nil
+}
+
+// PurgarAnteriores elimina los lotes recibidos antes de la fecha y el contenido
+// que quedó sin lotes
+func (r *LoteArchivadoRepositoryMemoria) PurgarAnteriores(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	eliminados := 0
+	restantes := r.lotes[:0]
+	for _, lote := range r.lotes {
+		if eliminados < limite && lote.RecibidoEn.Before(antesDe) {
+			eliminados++
+			continue
+		}
+		restantes = append(restantes, lote)
+	}
+	r.lotes = restantes
+
+	enUso := make(map[string]bool, len(r.lotes))
+	for _, lote := range r.lotes {
+		enUso[lote.Hash] = true
+	}
+	for hash := range r.contenidos {
+		if !enUso[hash] {
+			delete(r.contenidos, hash)
+		}
+	}
+	return eliminados, nil
+}
+
+// LeerContenido descomprime el contenido con el hash indicado
+func (r *LoteArchivadoRepositoryMemoria) LeerContenido(ctx context.Context, hash string) ([]byte, error) {
+	r.mutex.RLock()
+	comprimido, existe := r.contenidos[hash]
+	r.mutex.RUnlock()
+
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	return descomprimir(comprimido, hash)
+}
@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// MovimientoStockRepositoryGorm implementa MovimientoStockRepository sobre GORM
+type MovimientoStockRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewMovimientoStockRepositoryGorm crea un repositorio de movimientos de stock sobre la conexión indicada
+func NewMovimientoStockRepositoryGorm(db *gorm.DB) *MovimientoStockRepositoryGorm {
+	return &MovimientoStockRepositoryGorm{db: db}
+}
+
+// Registrar aplica cada movimiento al stock de su producto con una actualización
+// atómica, que bloquea la fila hasta confirmar, y guarda los movimientos con una
+// sentencia de varias filas. Si falla, los movimientos vuelven a quedar sin ID.
+func (r *MovimientoStockRepositoryGorm) Registrar(ctx context.Context, movimientos ...*entities.MovimientoStock) error {
+	if len(movimientos) == 0 {
+		return nil
+	}
+	origen := repositories.OrigenCambioDe(ctx)
+
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, movimiento := range movimientos {
+			resultado := tx.Model(&entities.Producto{}).
+				Where("id = ?", movimiento.ProductoID).
+				Update("stock_actual", gorm.Expr("stock_actual + ?", movimiento.Cantidad))
+			if resultado.Error != nil {
+				return resultado.Error
+			}
+			if resultado.RowsAffected == 0 {
+				return fmt.Errorf("%w: producto %d", repositories.ErrReferenciaInvalida, movimiento.ProductoID)
+			}
+
+			var stock []int
+			err := tx.Model(&entities.Producto{}).
+				Where("id = ?", movimiento.ProductoID).
+				Pluck("stock_actual", &stock).Error
+			if err != nil {
+				return err
+			}
+			movimiento.StockResultante = stock[0]
+			if movimiento.LoteID == "" {
+				movimiento.LoteID = origen.LoteID
+			}
+		}
+		return tx.Omit(clause.Associations).Create(movimientos).Error
+	})
+	if err != nil {
+		for _, movimiento := range movimientos {
+			movimiento.ID = 0
+		}
+	}
+	return traducirError(err)
+}
+
+// ListarPorProducto retorna los movimientos del producto ordenados por fecha
+func (r *MovimientoStockRepositoryGorm) ListarPorProducto(ctx context.Context, productoID, sucursalID uint) ([]entities.MovimientoStock, error) {
+	consulta := conexion(ctx, r.db).Where("producto_id = ?", productoID)
+	if sucursalID != 0 {
+		consulta = consulta.Where("sucursal_id = ?", sucursalID)
+	}
+
+	var movimientos []entities.MovimientoStock
+	if err := consulta.Order("fecha, id").Find(&movimientos).Error; err != nil {
+		return nil, err
+	}
+	return movimientos, nil
+}
+
+// ListarPorReferencia retorna los movimientos con la referencia indicada ordenados por fecha
+func (r *MovimientoStockRepositoryGorm) ListarPorReferencia(ctx context.Context, referencia string) ([]entities.MovimientoStock, error) {
+	var movimientos []entities.MovimientoStock
+	err := conexion(ctx, r.db).Where("referencia = ?", referencia).Order("fecha, id").Find(&movimientos).Error
+	if err != nil {
+		return nil, err
+	}
+	return movimientos, nil
+}
+
+// saldo es el stock acumulado de un grupo de movimientos
+type saldo struct {
+	Clave uint
+	Total int
+}
+
+// SaldosPorSucursal retorna el stock de cada producto indicado en la sucursal
+func (r *MovimientoStockRepositoryGorm) SaldosPorSucursal(ctx context.Context, sucursalID uint, productoIDs []uint) (map[uint]int, error) {
+	saldos := make(map[uint]int, len(productoIDs))
+	if len(productoIDs) == 0 {
+		return saldos, nil
+	}
+
+	var filas []saldo
+	err := conexion(ctx, r.db).Model(&entities.MovimientoStock{}).
+		Select("producto_id AS clave, SUM(cantidad) AS total").
+		Where("sucursal_id = ? AND producto_id IN ?", sucursalID, productoIDs).
+		Group("producto_id").
+		Scan(&filas).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, fila := range filas {
+		saldos[fila.Clave] = fila.Total
+	}
+	return saldos, nil
+}
+
+// SaldosPorProducto retorna el stock del producto en cada sucursal
+func (r *MovimientoStockRepositoryGorm) SaldosPorProducto(ctx context.Context, productoID uint) (map[uint]int, error) {
+	var filas []saldo
+	err := conexion(ctx, r.db).Model(&entities.MovimientoStock{}).
+		Select("sucursal_id AS clave, SUM(cantidad) AS total").
+		Where("producto_id = ?", productoID).
+		Group("sucursal_id").
+		Scan(&filas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	saldos := make(map[uint]int, len(filas))
+	for _, fila := range filas {
+		saldos[fila.Clave] = fila.Total
+	}
+	return saldos, nil
+}
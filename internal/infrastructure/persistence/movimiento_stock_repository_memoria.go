@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// MovimientoStockRepositoryMemoria implementa MovimientoStockRepository en
+// memoria. Los movimientos se aplican al stock del repositorio de productos,
+// como en la base de datos.
+type MovimientoStockRepositoryMemoria struct {
+	movimientos []entities.MovimientoStock
+	productos   *ProductoRepositoryMemoria
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewMovimientoStockRepositoryMemoria crea un registro de movimientos vacío que
+// aplica los movimientos a los productos del repositorio indicado
+func NewMovimientoStockRepositoryMemoria(productos *ProductoRepositoryMemoria) *MovimientoStockRepositoryMemoria {
+	return &MovimientoStockRepositoryMemoria{
+		productos:   productos,
+		siguienteID: 1,
+	}
+}
+
+// Registrar aplica los movimientos al stock de sus productos y los guarda
+func (r *MovimientoStockRepositoryMemoria) Registrar(ctx context.Context, movimientos ...*entities.MovimientoStock) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.productos.aplicarMovimientos(movimientos); err != nil {
+		return err
+	}
+
+	origen := repositories.OrigenCambioDe(ctx)
+	for _, movimiento := range movimientos {
+		if movimiento.LoteID == "" {
+			movimiento.LoteID = origen.LoteID
+		}
+		movimiento.ID = r.siguienteID
+		r.siguienteID++
+		r.movimientos = append(r.movimientos, *movimiento)
+	}
+	return nil
+}
+
+// ListarPorProducto retorna los movimientos del producto ordenados por fecha
+func (r *MovimientoStockRepositoryMemoria) ListarPorProducto(ctx context.Context, productoID, sucursalID uint) ([]entities.MovimientoStock, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var movimientos []entities.MovimientoStock
+	for _, movimiento := range r.movimientos {
+		if movimiento.ProductoID == productoID && (sucursalID == 0 || movimiento.SucursalID == sucursalID) {
+			movimientos = append(movimientos, movimiento)
+		}
+	}
+	sort.SliceStable(movimientos, func(i, j int) bool { return movimientos[i].Fecha.Before(movimientos[j].Fecha) })
+	return movimientos, nil
+}
+
+// ListarPorReferencia retorna los movimientos con la referencia indicada ordenados por fecha
+func (r *MovimientoStockRepositoryMemoria) ListarPorReferencia(ctx context.Context, referencia string) ([]entities.MovimientoStock, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var movimientos []entities.MovimientoStock
+	for _, movimiento := range r.movimientos {
+		if movimiento.Referencia == referencia {
+			movimientos = append(movimientos, movimiento)
+		}
+	}
+	sort.SliceStable(movimientos, func(i, j int) bool { return movimientos[i].Fecha.Before(movimientos[j].Fecha) })
+	return movimientos, nil
+}
+
+// SaldosPorSucursal retorna el stock de cada producto indicado en la sucursal
+func (r *MovimientoStockRepositoryMemoria) SaldosPorSucursal(ctx context.Context, sucursalID uint, productoIDs []uint) (map[uint]int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	buscados := make(map[uint]bool, len(productoIDs))
+	for _, id := range productoIDs {
+		buscados[id] = true
+	}
+
+	saldos := make(map[uint]int, len(productoIDs))
+	for _, movimiento := range r.movimientos {
+		if movimiento.SucursalID == sucursalID && buscados[movimiento.ProductoID] {
+			saldos[movimiento.ProductoID] += movimiento.Cantidad
+		}
+	}
+	return saldos, nil
+}
+
+// SaldosPorProducto retorna el stock del producto en cada sucursal
+func (r *MovimientoStockRepositoryMemoria) SaldosPorProducto(ctx context.Context, productoID uint) (map[uint]int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	saldos := make(map[uint]int)
+	for _, movimiento := range r.movimientos {
+		if movimiento.ProductoID == productoID {
+			saldos[movimiento.SucursalID] += movimiento.Cantidad
+		}
+	}
+	return saldos, nil
+}
@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// longitudMaximaError es el largo máximo del último error guardado de un evento
+const longitudMaximaError = 500
+
+// OutboxRepositoryGorm implementa OutboxRepository sobre GORM
+type OutboxRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepositoryGorm crea un repositorio del outbox sobre la conexión indicada
+func NewOutboxRepositoryGorm(db *gorm.DB) *OutboxRepositoryGorm {
+	return &OutboxRepositoryGorm{db: db}
+}
+
+// Agregar guarda los eventos pendientes, en la transacción del contexto si la hay
+func (r *OutboxRepositoryGorm) Agregar(ctx context.Context, eventos ...*entities.EventoOutbox) error {
+	if len(eventos) == 0 {
+		return nil
+	}
+	return traducirError(conexion(ctx, r.db).Create(eventos).Error)
+}
+
+// Reclamar elige los eventos pendientes más antiguos sin reserva vigente y los
+// reserva con una actualización condicionada a que sigan libres, de modo que
+// si otro proceso reserva alguno al mismo tiempo sólo uno de los dos lo obtiene
+func (r *OutboxRepositoryGorm) Reclamar(ctx context.Context, instancia string, limite int, hasta time.Time) ([]entities.EventoOutbox, error) {
+	ahora := time.Now()
+	libres := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("entregado_en IS NULL AND descartado_en IS NULL").
+			Where("reclamado_hasta IS NULL OR reclamado_hasta < ?", ahora)
+	}
+
+	var ids []uint
+	err := libres(conexion(ctx, r.db).Model(&entities.EventoOutbox{})).
+		Order("id").
+		Limit(limite).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	err = libres(conexion(ctx, r.db).Model(&entities.EventoOutbox{})).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"reclamado_por": instancia, "reclamado_hasta": hasta}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var eventos []entities.EventoOutbox
+	err = conexion(ctx, r.db).
+		Where("id IN ? AND reclamado_por = ? AND entregado_en IS NULL AND descartado_en IS NULL", ids, instancia).
+		Order("id").
+		Find(&eventos).Error
+	if err != nil {
+		return nil, err
+	}
+	return eventos, nil
+}
+
+// MarcarEntregado registra la entrega del evento
+func (r *OutboxRepositoryGorm) MarcarEntregado(ctx context.Context, id uint, fecha time.Time) error {
+	resultado := conexion(ctx, r.db).Model(&entities.EventoOutbox{}).
+		Where("id = ?", id).
+		Update("entregado_en", fecha)
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// RegistrarFallo incrementa los intentos del evento y guarda el motivo del fallo
+func (r *OutboxRepositoryGorm) RegistrarFallo(ctx context.Context, id uint, motivo string) error {
+	resultado := conexion(ctx, r.db).Model(&entities.EventoOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"intentos":        gorm.Expr("intentos + 1"),
+			"ultimo_error":    truncarError(motivo),
+			"reclamado_por":   "",
+			"reclamado_hasta": nil,
+		})
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// Descartar incrementa los intentos del evento, guarda el motivo del fallo y lo marca descartado
+func (r *OutboxRepositoryGorm) Descartar(ctx context.Context, id uint, motivo string, fecha time.Time) error {
+	resultado := conexion(ctx, r.db).Model(&entities.EventoOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"intentos":        gorm.Expr("intentos + 1"),
+			"ultimo_error":    truncarError(motivo),
+			"descartado_en":   fecha,
+			"reclamado_por":   "",
+			"reclamado_hasta": nil,
+		})
+	if resultado.Error != nil {
+		return resultado.Error
+	}
+	if resultado.RowsAffected == 0 {
+		return repositories.ErrNoEncontrado
+	}
+	return nil
+}
+
+// PurgarEntregados elimina los eventos entregados antes de la fecha, de los más antiguos a los más nuevos
+func (r *OutboxRepositoryGorm) PurgarEntregados(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	var ids []uint
+	err := conexion(ctx, r.db).Model(&entities.EventoOutbox{}).
+		Where("entregado_en IS NOT NULL AND entregado_en < ?", antesDe).
+		Order("id").
+		Limit(limite).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	resultado := conexion(ctx, r.db).Where("id IN ?", ids).Delete(&entities.EventoOutbox{})
+	return int(resultado.RowsAffected), resultado.Error
+}
@@ -0,0 +1,123 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// OutboxRepositoryMemoria implementa OutboxRepository en memoria
+type OutboxRepositoryMemoria struct {
+	eventos     []entities.EventoOutbox
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewOutboxRepositoryMemoria crea un outbox vacío
+func NewOutboxRepositoryMemoria() *OutboxRepositoryMemoria {
+	return &OutboxRepositoryMemoria{siguienteID: 1}
+}
+
+// Agregar guarda los eventos pendientes asignándoles ID
+func (r *OutboxRepositoryMemoria) Agregar(ctx context.Context, eventos ...*entities.EventoOutbox) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, evento := range eventos {
+		evento.ID = r.siguienteID
+		r.siguienteID++
+		r.eventos = append(r.eventos, *evento)
+	}
+	return nil
+}
+
+// Reclamar reserva para la instancia los eventos pendientes más antiguos sin reserva vigente
+func (r *OutboxRepositoryMemoria) Reclamar(ctx context.Context, instancia string, limite int, hasta time.Time) ([]entities.EventoOutbox, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ahora := time.Now()
+	var reclamados []entities.EventoOutbox
+	for i := range r.eventos {
+		if len(reclamados) == limite {
+			break
+		}
+		evento := &r.eventos[i]
+		if evento.EstaPendiente() && !evento.EstaReclamado(ahora) {
+			evento.ReclamadoPor = instancia
+			evento.ReclamadoHasta = &hasta
+			reclamados = append(reclamados, *evento)
+		}
+	}
+	return reclamados, nil
+}
+
+// MarcarEntregado registra la entrega del evento
+func (r *OutboxRepositoryMemoria) MarcarEntregado(ctx context.Context, id uint, fecha time.Time) error {
+	return r.modificar(id, func(evento *entities.EventoOutbox) {
+		evento.EntregadoEn = &fecha
+	})
+}
+
+// RegistrarFallo incrementa los intentos del evento y guarda el motivo del fallo
+func (r *OutboxRepositoryMemoria) RegistrarFallo(ctx context.Context, id uint, motivo string) error {
+	return r.modificar(id, func(evento *entities.EventoOutbox) {
+		evento.Intentos++
+		evento.UltimoError = truncarError(motivo)
+		evento.ReclamadoPor, evento.ReclamadoHasta = "", nil
+	})
+}
+
+// Descartar incrementa los intentos del evento, guarda el motivo del fallo y lo marca descartado
+func (r *OutboxRepositoryMemoria) Descartar(ctx context.Context, id uint, motivo string, fecha time.Time) error {
+	return r.modificar(id, func(evento *entities.EventoOutbox) {
+		evento.Intentos++
+		evento.UltimoError = truncarError(motivo)
+		evento.DescartadoEn = &fecha
+		evento.ReclamadoPor, evento.ReclamadoHasta = "", nil
+	})
+}
+
+// PurgarEntregados elimina los eventos entregados antes de la fecha
+func (r *OutboxRepositoryMemoria) PurgarEntregados(ctx context.Context, antesDe time.Time, limite int) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	eliminados := 0
+	restantes := r.eventos[:0]
+	for _, evento := range r.eventos {
+		if eliminados < limite && evento.FueEntregado() && evento.EntregadoEn.Before(antesDe) {
+			eliminados++
+			continue
+		}
+		restantes = append(restantes, evento)
+	}
+	r.eventos = restantes
+	return eliminados, nil
+}
+
+// modificar aplica el cambio al evento con el ID indicado
+func (r *OutboxRepositoryMemoria) modificar(id uint, cambio func(*entities.EventoOutbox)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.eventos {
+		if r.eventos[i].ID == id {
+			cambio(&r.eventos[i])
+			return nil
+		}
+	}
+	return repositories.ErrNoEncontrado
+}
+
+// TransaccionesMemoria implementa Transacciones para los repositorios en
+// memoria, que no pueden revertir cambios: fn se ejecuta sin transacción
+type TransaccionesMemoria struct{}
+
+// Ejecutar llama a fn con el contexto recibido
+func (TransaccionesMemoria) Ejecutar(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,245 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ProductoRepositoryGorm implementa ProductoRepository sobre GORM
+type ProductoRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewProductoRepositoryGorm crea un repositorio de productos sobre la conexión indicada
+func NewProductoRepositoryGorm(db *gorm.DB) *ProductoRepositoryGorm {
+	return &ProductoRepositoryGorm{db: db}
+}
+
+// ObtenerPorID retorna el producto con el ID indicado
+func (r *ProductoRepositoryGorm) ObtenerPorID(ctx context.Context, id uint) (*entities.Producto, error) {
+	var producto entities.Producto
+	if err := conexion(ctx, r.db).First(&producto, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &producto, nil
+}
+
+// ObtenerPorSKU retorna el producto con el SKU indicado
+func (r *ProductoRepositoryGorm) ObtenerPorSKU(ctx context.Context, sku string) (*entities.Producto, error) {
+	var producto entities.Producto
+	if err := conexion(ctx, r.db).Where("sku = ?", sku).First(&producto).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &producto, nil
+}
+
+// ListarPorSKUs retorna los productos existentes entre los SKUs indicados
+func (r *ProductoRepositoryGorm) ListarPorSKUs(ctx context.Context, skus []string) ([]entities.Producto, error) {
+	var productos []entities.Producto
+	if len(skus) == 0 {
+		return productos, nil
+	}
+	if err := conexion(ctx, r.db).Where("sku IN ?", skus).Find(&productos).Error; err != nil {
+		return nil, err
+	}
+	return productos, nil
+}
+
+// Listar retorna todos los productos ordenados por SKU
+func (r *ProductoRepositoryGorm) Listar(ctx context.Context) ([]entities.Producto, error) {
+	var productos []entities.Producto
+	if err := conexion(ctx, r.db).Order("sku").Find(&productos).Error; err != nil {
+		return nil, err
+	}
+	return productos, nil
+}
+
+// Guardar crea o actualiza un producto, asignando un ID si no lo tiene, y
+// registra en la misma transacción el cambio de precio si lo hubo. El stock no
+// se escribe: el producto queda con el stock almacenado.
+func (r *ProductoRepositoryGorm) Guardar(ctx context.Context, producto *entities.Producto) error {
+	productoID := producto.ID
+	productos := []*entities.Producto{producto}
+
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		anteriores, err := productosGuardados(tx, productos)
+		if err != nil {
+			return err
+		}
+
+		guardar := tx
+		if anterior, existe := anteriores[producto.ID]; existe {
+			producto.StockActual = anterior.StockActual
+			guardar = tx.Omit("stock_actual")
+		} else {
+			producto.StockActual = 0
+		}
+		if err := guardar.Save(producto).Error; err != nil {
+			return err
+		}
+		return registrarCambiosPrecio(ctx, tx, anteriores, productos)
+	})
+	if err != nil {
+		producto.ID = productoID
+	}
+	return traducirError(err)
+}
+
+// columnasActualizables son las columnas que GuardarLote actualiza en los productos existentes
+var columnasActualizables = []string{
+	"sku", "nombre", "descripcion", "categoria", "fabricante", "precio",
+	"precio_oferta", "stock_minimo", "estado", "ultima_actualizacion",
+}
+
+// GuardarLote inserta los productos nuevos y actualiza los existentes con una
+// sentencia de varias filas para cada grupo, dentro de una transacción. Como en
+// Guardar, el stock no se escribe. Si falla, los productos nuevos vuelven a
+// quedar sin ID.
+func (r *ProductoRepositoryGorm) GuardarLote(ctx context.Context, productos []*entities.Producto) error {
+	var nuevos, existentes []*entities.Producto
+	for _, producto := range productos {
+		if producto.ID == 0 {
+			nuevos = append(nuevos, producto)
+		} else {
+			existentes = append(existentes, producto)
+		}
+	}
+
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		anteriores, err := productosGuardados(tx, existentes)
+		if err != nil {
+			return err
+		}
+		for _, producto := range productos {
+			producto.StockActual = 0
+			if anterior, existe := anteriores[producto.ID]; existe {
+				producto.StockActual = anterior.StockActual
+			}
+		}
+		if len(nuevos) > 0 {
+			if err := tx.Create(nuevos).Error; err != nil {
+				return err
+			}
+		}
+		if len(existentes) > 0 {
+			upsert := clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns(columnasActualizables),
+			}
+			if err := tx.Clauses(upsert).Create(existentes).Error; err != nil {
+				return err
+			}
+		}
+		return registrarCambiosPrecio(ctx, tx, anteriores, productos)
+	})
+	if err != nil {
+		for _, producto := range nuevos {
+			producto.ID = 0
+		}
+	}
+	return traducirError(err)
+}
+
+// productosGuardados retorna el precio, la oferta y el stock almacenados de los
+// productos que ya tienen ID
+func productosGuardados(tx *gorm.DB, productos []*entities.Producto) (map[uint]*entities.Producto, error) {
+	var ids []uint
+	for _, producto := range productos {
+		if producto.ID != 0 {
+			ids = append(ids, producto.ID)
+		}
+	}
+
+	anteriores := make(map[uint]*entities.Producto, len(ids))
+	if len(ids) == 0 {
+		return anteriores, nil
+	}
+
+	var filas []entities.Producto
+	if err := tx.Select("id", "precio", "precio_oferta", "stock_actual").Where("id IN ?", ids).Find(&filas).Error; err != nil {
+		return nil, err
+	}
+	for i := range filas {
+		anteriores[filas[i].ID] = &filas[i]
+	}
+	return anteriores, nil
+}
+
+// registrarCambiosPrecio cierra el precio vigente de los productos cuyo precio u
+// oferta cambió e inserta el nuevo, con el origen informado en el contexto
+func registrarCambiosPrecio(ctx context.Context, tx *gorm.DB, anteriores map[uint]*entities.Producto, productos []*entities.Producto) error {
+	origen := repositories.OrigenCambioDe(ctx)
+	ahora := time.Now()
+
+	var cambios []*entities.PrecioHistorico
+	var cerrar []uint
+	for _, producto := range productos {
+		anterior := anteriores[producto.ID]
+		if !entities.CambiaPrecio(anterior, producto) {
+			continue
+		}
+		cambio := entities.NewPrecioHistorico(anterior, producto, ahora)
+		cambio.SucursalID = origen.SucursalID
+		cambio.LoteID = origen.LoteID
+		cambios = append(cambios, cambio)
+		if anterior != nil {
+			cerrar = append(cerrar, producto.ID)
+		}
+	}
+
+	if len(cerrar) > 0 {
+		err := tx.Model(&entities.PrecioHistorico{}).
+			Where("producto_id IN ? AND vigente_hasta IS NULL", cerrar).
+			Update("vigente_hasta", ahora).Error
+		if err != nil {
+			return err
+		}
+	}
+	if len(cambios) == 0 {
+		return nil
+	}
+	return tx.Omit(clause.Associations).Create(cambios).Error
+}
+
+// PrecioHistoricoRepositoryGorm implementa PrecioHistoricoRepository sobre GORM
+type PrecioHistoricoRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewPrecioHistoricoRepositoryGorm crea un repositorio del historial de precios sobre la conexión indicada
+func NewPrecioHistoricoRepositoryGorm(db *gorm.DB) *PrecioHistoricoRepositoryGorm {
+	return &PrecioHistoricoRepositoryGorm{db: db}
+}
+
+// ObtenerVigente retorna el precio del producto vigente en la fecha indicada
+func (r *PrecioHistoricoRepositoryGorm) ObtenerVigente(ctx context.Context, productoID uint, fecha time.Time) (*entities.PrecioHistorico, error) {
+	var precio entities.PrecioHistorico
+	err := conexion(ctx, r.db).
+		Where("producto_id = ? AND vigente_desde <= ?", productoID, fecha).
+		Where("vigente_hasta IS NULL OR vigente_hasta > ?", fecha).
+		Order("vigente_desde DESC, id DESC").
+		First(&precio).Error
+	if err != nil {
+		return nil, traducirError(err)
+	}
+	return &precio, nil
+}
+
+// ListarPorProducto retorna el historial de precios del producto, del más antiguo al vigente
+func (r *PrecioHistoricoRepositoryGorm) ListarPorProducto(ctx context.Context, productoID uint) ([]entities.PrecioHistorico, error) {
+	var precios []entities.PrecioHistorico
+	err := conexion(ctx, r.db).
+		Where("producto_id = ?", productoID).
+		Order("vigente_desde, id").
+		Find(&precios).Error
+	if err != nil {
+		return nil, err
+	}
+	return precios, nil
+}
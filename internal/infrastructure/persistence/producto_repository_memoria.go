@@ -0,0 +1,233 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ProductoRepositoryMemoria implementa ProductoRepository en memoria. Los
+// cambios de precio se registran en el repositorio de historial, como en la base
+// de datos.
+type ProductoRepositoryMemoria struct {
+	productos   map[uint]entities.Producto
+	porSKU      map[string]uint
+	precios     *PrecioHistoricoRepositoryMemoria
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewProductoRepositoryMemoria crea un repositorio de productos vacío que
+// registra los cambios de precio en el historial indicado
+func NewProductoRepositoryMemoria(precios *PrecioHistoricoRepositoryMemoria) *ProductoRepositoryMemoria {
+	return &ProductoRepositoryMemoria{
+		productos:   make(map[uint]entities.Producto),
+		porSKU:      make(map[string]uint),
+		precios:     precios,
+		siguienteID: 1,
+	}
+}
+
+// ObtenerPorID retorna el producto con el ID indicado
+func (r *ProductoRepositoryMemoria) ObtenerPorID(ctx context.Context, id uint) (*entities.Producto, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	producto, existe := r.productos[id]
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	return &producto, nil
+}
+
+// ObtenerPorSKU retorna el producto con el SKU indicado
+func (r *ProductoRepositoryMemoria) ObtenerPorSKU(ctx context.Context, sku string) (*entities.Producto, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	id, existe := r.porSKU[sku]
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	producto := r.productos[id]
+	return &producto, nil
+}
+
+// ListarPorSKUs retorna los productos existentes entre los SKUs indicados
+func (r *ProductoRepositoryMemoria) ListarPorSKUs(ctx context.Context, skus []string) ([]entities.Producto, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var productos []entities.Producto
+	for _, sku := range skus {
+		if id, existe := r.porSKU[sku]; existe {
+			productos = append(productos, r.productos[id])
+		}
+	}
+	return productos, nil
+}
+
+// Listar retorna todos los productos ordenados por SKU
+func (r *ProductoRepositoryMemoria) Listar(ctx context.Context) ([]entities.Producto, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	productos := make([]entities.Producto, 0, len(r.productos))
+	for _, producto := range r.productos {
+		productos = append(productos, producto)
+	}
+	sort.Slice(productos, func(i, j int) bool { return productos[i].SKU < productos[j].SKU })
+	return productos, nil
+}
+
+// Guardar crea o actualiza un producto, asignando un ID si no lo tiene. Como el
+// índice único de la base de datos, rechaza un SKU que pertenezca a otro producto.
+// El stock no se escribe: solo cambia con los movimientos de stock.
+func (r *ProductoRepositoryMemoria) Guardar(ctx context.Context, producto *entities.Producto) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if id, existe := r.porSKU[producto.SKU]; existe && id != producto.ID {
+		return repositories.ErrDuplicado
+	}
+
+	r.guardar(ctx, producto, time.Now())
+	return nil
+}
+
+// GuardarLote crea o actualiza los productos verificando antes todos los SKUs,
+// de modo que un conflicto no deja el lote guardado a medias
+func (r *ProductoRepositoryMemoria) GuardarLote(ctx context.Context, productos []*entities.Producto) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	enLote := make(map[string]bool, len(productos))
+	for _, producto := range productos {
+		id, existe := r.porSKU[producto.SKU]
+		if (existe && id != producto.ID) || enLote[producto.SKU] {
+			return repositories.ErrDuplicado
+		}
+		enLote[producto.SKU] = true
+	}
+
+	ahora := time.Now()
+	for _, producto := range productos {
+		r.guardar(ctx, producto, ahora)
+	}
+	return nil
+}
+
+// guardar almacena el producto y registra el cambio de precio; el llamador debe
+// tener tomado el mutex
+func (r *ProductoRepositoryMemoria) guardar(ctx context.Context, producto *entities.Producto, ahora time.Time) {
+	var anterior *entities.Producto
+	producto.StockActual = 0
+	if guardado, existe := r.productos[producto.ID]; existe && producto.ID != 0 {
+		anterior = &guardado
+		producto.StockActual = guardado.StockActual
+	}
+	cambioPrecio := r.precios != nil && entities.CambiaPrecio(anterior, producto)
+
+	if producto.ID == 0 {
+		producto.ID = r.siguienteID
+	}
+	if producto.ID >= r.siguienteID {
+		r.siguienteID = producto.ID + 1
+	}
+	if anterior, existe := r.productos[producto.ID]; existe {
+		delete(r.porSKU, anterior.SKU)
+	} else if producto.FechaCreacion.IsZero() {
+		producto.FechaCreacion = ahora
+	}
+	producto.UltimaActualizacion = ahora
+
+	r.productos[producto.ID] = *producto
+	r.porSKU[producto.SKU] = producto.ID
+
+	if cambioPrecio {
+		r.precios.registrar(repositories.OrigenCambioDe(ctx), anterior, producto, ahora)
+	}
+}
+
+// aplicarMovimientos aplica los movimientos al stock de sus productos, verificando
+// antes que existan todos
+func (r *ProductoRepositoryMemoria) aplicarMovimientos(movimientos []*entities.MovimientoStock) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, movimiento := range movimientos {
+		if _, existe := r.productos[movimiento.ProductoID]; !existe {
+			return repositories.ErrReferenciaInvalida
+		}
+	}
+	for _, movimiento := range movimientos {
+		producto := r.productos[movimiento.ProductoID]
+		producto.AplicarMovimiento(movimiento)
+		r.productos[producto.ID] = producto
+	}
+	return nil
+}
+
+// PrecioHistoricoRepositoryMemoria implementa PrecioHistoricoRepository en memoria
+type PrecioHistoricoRepositoryMemoria struct {
+	precios     []entities.PrecioHistorico
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewPrecioHistoricoRepositoryMemoria crea un historial de precios vacío
+func NewPrecioHistoricoRepositoryMemoria() *PrecioHistoricoRepositoryMemoria {
+	return &PrecioHistoricoRepositoryMemoria{siguienteID: 1}
+}
+
+// ObtenerVigente retorna el precio del producto vigente en la fecha indicada
+func (r *PrecioHistoricoRepositoryMemoria) ObtenerVigente(ctx context.Context, productoID uint, fecha time.Time) (*entities.PrecioHistorico, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := len(r.precios) - 1; i >= 0; i-- {
+		precio := r.precios[i]
+		if precio.ProductoID == productoID && precio.VigenteEn(fecha) {
+			return &precio, nil
+		}
+	}
+	return nil, repositories.ErrNoEncontrado
+}
+
+// ListarPorProducto retorna el historial de precios del producto, del más antiguo al vigente
+func (r *PrecioHistoricoRepositoryMemoria) ListarPorProducto(ctx context.Context, productoID uint) ([]entities.PrecioHistorico, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var precios []entities.PrecioHistorico
+	for _, precio := range r.precios {
+		if precio.ProductoID == productoID {
+			precios = append(precios, precio)
+		}
+	}
+	return precios, nil
+}
+
+// registrar cierra el precio vigente del producto y agrega el nuevo
+func (r *PrecioHistoricoRepositoryMemoria) registrar(origen repositories.OrigenCambio, anterior, actual *entities.Producto, ahora time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.precios {
+		if r.precios[i].ProductoID == actual.ID && r.precios[i].VigenteHasta == nil {
+			hasta := ahora
+			r.precios[i].VigenteHasta = &hasta
+		}
+	}
+
+	cambio := entities.NewPrecioHistorico(anterior, actual, ahora)
+	cambio.ID = r.siguienteID
+	cambio.SucursalID = origen.SucursalID
+	cambio.LoteID = origen.LoteID
+	r.siguienteID++
+	r.precios = append(r.precios, *cambio)
+}
@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// PurgaRepositoryGorm implementa PurgaRepository sobre GORM
+type PurgaRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewPurgaRepositoryGorm crea un repositorio de auditoría de purgas sobre la conexión indicada
+func NewPurgaRepositoryGorm(db *gorm.DB) *PurgaRepositoryGorm {
+	return &PurgaRepositoryGorm{db: db}
+}
+
+// Registrar guarda el registro de una purga
+func (r *PurgaRepositoryGorm) Registrar(ctx context.Context, registro *entities.RegistroPurga) error {
+	registro.Error = truncarError(registro.Error)
+	return traducirError(conexion(ctx, r.db).Create(registro).Error)
+}
+
+// Listar retorna los registros de purga del más reciente al más antiguo
+func (r *PurgaRepositoryGorm) Listar(ctx context.Context, clase string, limite int) ([]entities.RegistroPurga, error) {
+	consulta := conexion(ctx, r.db)
+	if clase != "" {
+		consulta = consulta.Where("clase = ?", clase)
+	}
+
+	var registros []entities.RegistroPurga
+	if err := consulta.Order("iniciada_en DESC, id DESC").Limit(limite).Find(&registros).Error; err != nil {
+		return nil, err
+	}
+	return registros, nil
+}
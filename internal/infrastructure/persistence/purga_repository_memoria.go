@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// PurgaRepositoryMemoria implementa PurgaRepository en memoria
+type PurgaRepositoryMemoria struct {
+	registros   []entities.RegistroPurga
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewPurgaRepositoryMemoria crea una auditoría de purgas vacía
+func NewPurgaRepositoryMemoria() *PurgaRepositoryMemoria {
+	return &PurgaRepositoryMemoria{siguienteID: 1}
+}
+
+// Registrar guarda el registro de una purga asignándole ID
+func (r *PurgaRepositoryMemoria) Registrar(ctx context.Context, registro *entities.RegistroPurga) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	registro.ID = r.siguienteID
+	registro.Error = truncarError(registro.Error)
+	r.siguienteID++
+	r.registros = append(r.registros, *registro)
+	return nil
+}
+
+// Listar retorna los registros de purga del más reciente al más antiguo
+func (r *PurgaRepositoryMemoria) Listar(ctx context.Context, clase string, limite int) ([]entities.RegistroPurga, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var registros []entities.RegistroPurga
+	for i := len(r.registros) - 1; i >= 0 && len(registros) < limite; i-- {
+		if clase == "" || r.registros[i].Clase == clase {
+			registros = append(registros, r.registros[i])
+		}
+	}
+	return registros, nil
+}
@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/cifrado"
+)
+
+// SucursalRepositoryGorm implementa SucursalRepository sobre GORM. Las
+// credenciales de integración se guardan cifradas y se descifran al leerlas.
+type SucursalRepositoryGorm struct {
+	db       *gorm.DB
+	cifrador *cifrado.Cifrador
+}
+
+// NewSucursalRepositoryGorm crea un repositorio de sucursales sobre la conexión
+// indicada. Sin cifrador las credenciales se guardan en texto plano.
+func NewSucursalRepositoryGorm(db *gorm.DB, cifrador *cifrado.Cifrador) *SucursalRepositoryGorm {
+	return &SucursalRepositoryGorm{db: db, cifrador: cifrador}
+}
+
+// ObtenerPorID retorna la sucursal con el ID indicado
+func (r *SucursalRepositoryGorm) ObtenerPorID(ctx context.Context, id uint) (*entities.Sucursal, error) {
+	var sucursal entities.Sucursal
+	if err := conexion(ctx, r.db).First(&sucursal, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	if err := r.descifrar(&sucursal); err != nil {
+		return nil, err
+	}
+	return &sucursal, nil
+}
+
+// Listar retorna todas las sucursales ordenadas por ID
+func (r *SucursalRepositoryGorm) Listar(ctx context.Context) ([]entities.Sucursal, error) {
+	var sucursales []entities.Sucursal
+	if err := conexion(ctx, r.db).Order("id").Find(&sucursales).Error; err != nil {
+		return nil, err
+	}
+	for i := range sucursales {
+		if err := r.descifrar(&sucursales[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sucursales, nil
+}
+
+// Guardar crea o actualiza una sucursal, asignando un ID si no lo tiene. La
+// sucursal recibida conserva sus credenciales sin cifrar.
+func (r *SucursalRepositoryGorm) Guardar(ctx context.Context, sucursal *entities.Sucursal) error {
+	guardada := *sucursal
+	if err := r.cifrar(&guardada); err != nil {
+		return err
+	}
+	if err := conexion(ctx, r.db).Save(&guardada).Error; err != nil {
+		return traducirError(err)
+	}
+	sucursal.ID = guardada.ID
+	return nil
+}
+
+// RecifrarCredenciales vuelve a cifrar con la clave activa las credenciales
+// guardadas sin cifrar o con una clave anterior, y retorna cuántas sucursales
+// actualizó. Permite retirar una clave después de rotarla.
+func (r *SucursalRepositoryGorm) RecifrarCredenciales(ctx context.Context) (int, error) {
+	if r.cifrador == nil {
+		return 0, fmt.Errorf("no hay clave de cifrado configurada")
+	}
+
+	actualizadas := 0
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var sucursales []entities.Sucursal
+		if err := tx.Select("id", "api_key", "api_secret").Order("id").Find(&sucursales).Error; err != nil {
+			return err
+		}
+
+		for i := range sucursales {
+			sucursal := &sucursales[i]
+			if !r.cifrador.RequiereRecifrar(sucursal.APIKey) && !r.cifrador.RequiereRecifrar(sucursal.APISecret) {
+				continue
+			}
+			if err := r.descifrar(sucursal); err != nil {
+				return err
+			}
+			if err := r.cifrar(sucursal); err != nil {
+				return err
+			}
+			err := tx.Model(&entities.Sucursal{}).Where("id = ?", sucursal.ID).
+				Updates(map[string]interface{}{"api_key": sucursal.APIKey, "api_secret": sucursal.APISecret}).Error
+			if err != nil {
+				return err
+			}
+			actualizadas++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return actualizadas, nil
+}
+
+// cifrar reemplaza las credenciales de la sucursal por su valor cifrado
+func (r *SucursalRepositoryGorm) cifrar(sucursal *entities.Sucursal) error {
+	if r.cifrador == nil {
+		return nil
+	}
+	var err error
+	if sucursal.APIKey, err = r.cifrador.Cifrar(sucursal.APIKey); err != nil {
+		return fmt.Errorf("api_key de la sucursal %d: %w", sucursal.ID, err)
+	}
+	if sucursal.APISecret, err = r.cifrador.Cifrar(sucursal.APISecret); err != nil {
+		return fmt.Errorf("api_secret de la sucursal %d: %w", sucursal.ID, err)
+	}
+	return nil
+}
+
+// descifrar reemplaza las credenciales guardadas de la sucursal por su valor
+// original. Las guardadas en texto plano se retornan tal cual.
+func (r *SucursalRepositoryGorm) descifrar(sucursal *entities.Sucursal) error {
+	if r.cifrador == nil {
+		if cifrado.EsCifrado(sucursal.APIKey) || cifrado.EsCifrado(sucursal.APISecret) {
+			return fmt.Errorf("las credenciales de la sucursal %d están cifradas y no hay clave de cifrado configurada", sucursal.ID)
+		}
+		return nil
+	}
+	var err error
+	if sucursal.APIKey, err = r.cifrador.Descifrar(sucursal.APIKey); err != nil {
+		return fmt.Errorf("api_key de la sucursal %d: %w", sucursal.ID, err)
+	}
+	if sucursal.APISecret, err = r.cifrador.Descifrar(sucursal.APISecret); err != nil {
+		return fmt.Errorf("api_secret de la sucursal %d: %w", sucursal.ID, err)
+	}
+	return nil
+}
+
+// traducirError convierte los errores de GORM en errores del dominio
+func traducirError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return repositories.ErrNoEncontrado
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return repositories.ErrDuplicado
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return repositories.ErrReferenciaInvalida
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// SucursalRepositoryMemoria implementa SucursalRepository en memoria
+type SucursalRepositoryMemoria struct {
+	sucursales  map[uint]entities.Sucursal
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewSucursalRepositoryMemoria crea un repositorio de sucursales vacío
+func NewSucursalRepositoryMemoria() *SucursalRepositoryMemoria {
+	return &SucursalRepositoryMemoria{
+		sucursales:  make(map[uint]entities.Sucursal),
+		siguienteID: 1,
+	}
+}
+
+// ObtenerPorID retorna la sucursal con el ID indicado
+func (r *SucursalRepositoryMemoria) ObtenerPorID(ctx context.Context, id uint) (*entities.Sucursal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sucursal, existe := r.sucursales[id]
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+	return &sucursal, nil
+}
+
+// Listar retorna todas las sucursales ordenadas por ID
+func (r *SucursalRepositoryMemoria) Listar(ctx context.Context) ([]entities.Sucursal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sucursales := make([]entities.Sucursal, 0, len(r.sucursales))
+	for _, sucursal := range r.sucursales {
+		sucursales = append(sucursales, sucursal)
+	}
+	sort.Slice(sucursales, func(i, j int) bool { return sucursales[i].ID < sucursales[j].ID })
+	return sucursales, nil
+}
+
+// Guardar crea o actualiza una sucursal, asignando un ID si no lo tiene
+func (r *SucursalRepositoryMemoria) Guardar(ctx context.Context, sucursal *entities.Sucursal) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if sucursal.ID == 0 {
+		sucursal.ID = r.siguienteID
+	}
+	if sucursal.ID >= r.siguienteID {
+		r.siguienteID = sucursal.ID + 1
+	}
+
+	r.sucursales[sucursal.ID] = *sucursal
+	return nil
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type claveTransaccion struct{}
+
+// TransaccionesGorm implementa Transacciones sobre GORM, informando la
+// transacción en curso a los repositorios mediante el contexto
+type TransaccionesGorm struct {
+	db *gorm.DB
+}
+
+// NewTransaccionesGorm crea las transacciones sobre la conexión indicada
+func NewTransaccionesGorm(db *gorm.DB) *TransaccionesGorm {
+	return &TransaccionesGorm{db: db}
+}
+
+// Ejecutar llama a fn dentro de una transacción que se confirma si fn no retorna
+// error. Si ya hay una transacción en curso, fn se ejecuta en un savepoint que se
+// revierte si falla, sin abortar la transacción que la contiene.
+func (t *TransaccionesGorm) Ejecutar(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := t.db.WithContext(ctx)
+	if enCurso, ok := ctx.Value(claveTransaccion{}).(*gorm.DB); ok {
+		db = enCurso.WithContext(ctx)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, claveTransaccion{}, tx))
+	})
+}
+
+// conexion retorna la transacción en curso del contexto o, si no hay, la
+// conexión del repositorio. Las transacciones que abra un repositorio sobre ella
+// quedan anidadas en la del contexto.
+func conexion(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, enCurso := ctx.Value(claveTransaccion{}).(*gorm.DB); enCurso {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,234 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// VentaRepositoryGorm implementa VentaRepository sobre GORM
+type VentaRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewVentaRepositoryGorm crea un repositorio de ventas sobre la conexión indicada
+func NewVentaRepositoryGorm(db *gorm.DB) *VentaRepositoryGorm {
+	return &VentaRepositoryGorm{db: db}
+}
+
+// ObtenerPorID retorna la venta con el ID indicado junto con sus detalles y su
+// desglose de impuestos
+func (r *VentaRepositoryGorm) ObtenerPorID(ctx context.Context, id uint) (*entities.Venta, error) {
+	var venta entities.Venta
+	if err := conexion(ctx, r.db).Preload("DetallesVenta").Preload("ImpuestosVenta", ordenarPorID).First(&venta, id).Error; err != nil {
+		return nil, traducirError(err)
+	}
+	return &venta, nil
+}
+
+// ListarPorSucursal retorna las ventas de una sucursal ordenadas por fecha
+func (r *VentaRepositoryGorm) ListarPorSucursal(ctx context.Context, sucursalID uint) ([]entities.Venta, error) {
+	var ventas []entities.Venta
+	err := conexion(ctx, r.db).
+		Preload("DetallesVenta").
+		Preload("ImpuestosVenta", ordenarPorID).
+		Where("sucursal_id = ?", sucursalID).
+		Order("fecha_venta, id").
+		Find(&ventas).Error
+	if err != nil {
+		return nil, err
+	}
+	return ventas, nil
+}
+
+// Guardar crea o actualiza la venta y sus detalles en una única transacción y
+// reemplaza su desglose de impuestos. Los productos y la sucursal referenciados
+// deben existir: no se crean a través de la venta. Si falla cualquier detalle
+// no se guarda nada y la venta conserva los IDs que tenía.
+func (r *VentaRepositoryGorm) Guardar(ctx context.Context, venta *entities.Venta) error {
+	ventaID := venta.ID
+	detalleIDs := make([]uint, len(venta.DetallesVenta))
+	for i, detalle := range venta.DetallesVenta {
+		detalleIDs[i] = detalle.ID
+	}
+
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(venta).Error; err != nil {
+			return err
+		}
+
+		venta.VincularDetalles()
+		for i := range venta.DetallesVenta {
+			detalle := &venta.DetallesVenta[i]
+			if !detalle.EsValido() {
+				return fmt.Errorf("%w: detalle %d de la venta sin producto, cantidad o precio", repositories.ErrEntidadInvalida, i+1)
+			}
+			if err := tx.Omit(clause.Associations).Save(detalle).Error; err != nil {
+				return fmt.Errorf("detalle %d de la venta: %w", i+1, traducirError(err))
+			}
+		}
+
+		if err := tx.Where("venta_id = ?", venta.ID).Delete(&entities.ImpuestoVenta{}).Error; err != nil {
+			return err
+		}
+		for i := range venta.ImpuestosVenta {
+			venta.ImpuestosVenta[i].ID = 0
+		}
+		if len(venta.ImpuestosVenta) == 0 {
+			return nil
+		}
+		return tx.Create(&venta.ImpuestosVenta).Error
+	})
+	if err != nil {
+		venta.ID = ventaID
+		for i := range venta.DetallesVenta {
+			venta.DetallesVenta[i].ID = detalleIDs[i]
+			venta.DetallesVenta[i].VentaID = ventaID
+		}
+	}
+	return traducirError(err)
+}
+
+// GuardarLote crea las ventas y luego todos sus detalles y sus líneas de
+// impuestos con sentencias de varias filas, dentro de una transacción. Si falla, las ventas vuelven a quedar sin ID.
+func (r *VentaRepositoryGorm) GuardarLote(ctx context.Context, ventas []*entities.Venta) error {
+	if len(ventas) == 0 {
+		return nil
+	}
+	for i, venta := range ventas {
+		if venta.ID != 0 {
+			return fmt.Errorf("%w: la venta %d del lote ya tiene ID", repositories.ErrEntidadInvalida, i+1)
+		}
+	}
+
+	err := conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(ventas).Error; err != nil {
+			return err
+		}
+
+		var detalles []*entities.DetalleVenta
+		var impuestos []*entities.ImpuestoVenta
+		for i, venta := range ventas {
+			venta.VincularDetalles()
+			for j := range venta.ImpuestosVenta {
+				impuestos = append(impuestos, &venta.ImpuestosVenta[j])
+			}
+			for j := range venta.DetallesVenta {
+				detalle := &venta.DetallesVenta[j]
+				if !detalle.EsValido() {
+					return fmt.Errorf("%w: detalle %d de la venta %d del lote sin producto, cantidad o precio", repositories.ErrEntidadInvalida, j+1, i+1)
+				}
+				detalles = append(detalles, detalle)
+			}
+		}
+		if len(detalles) > 0 {
+			if err := tx.Omit(clause.Associations).Create(detalles).Error; err != nil {
+				return err
+			}
+		}
+		if len(impuestos) == 0 {
+			return nil
+		}
+		return tx.Create(impuestos).Error
+	})
+	if err != nil {
+		for _, venta := range ventas {
+			venta.ID = 0
+			for j := range venta.DetallesVenta {
+				venta.DetallesVenta[j].ID = 0
+				venta.DetallesVenta[j].VentaID = 0
+			}
+			for j := range venta.ImpuestosVenta {
+				venta.ImpuestosVenta[j].ID = 0
+				venta.ImpuestosVenta[j].VentaID = 0
+			}
+		}
+	}
+	return traducirError(err)
+}
+
+// CambiarEstado guarda el estado de la venta y las unidades devueltas de sus
+// detalles en una transacción, si el estado guardado sigue siendo desde
+func (r *VentaRepositoryGorm) CambiarEstado(ctx context.Context, venta *entities.Venta, desde string) error {
+	return traducirError(conexion(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		resultado := tx.Model(&entities.Venta{}).
+			Where("id = ? AND estado = ?", venta.ID, desde).
+			Update("estado", venta.Estado)
+		if resultado.Error != nil {
+			return resultado.Error
+		}
+		if resultado.RowsAffected == 0 {
+			return repositories.ErrModificacionConcurrente
+		}
+
+		for _, detalle := range venta.DetallesVenta {
+			err := tx.Model(&entities.DetalleVenta{}).
+				Where("id = ? AND venta_id = ?", detalle.ID, venta.ID).
+				Update("cantidad_devuelta", detalle.CantidadDevuelta).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// RegistrarTransicion agrega la transición al historial de la venta
+func (r *VentaRepositoryGorm) RegistrarTransicion(ctx context.Context, transicion *entities.TransicionVenta) error {
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Create(transicion).Error)
+}
+
+// ListarTransiciones retorna el historial de estados de la venta, del más antiguo al más nuevo
+func (r *VentaRepositoryGorm) ListarTransiciones(ctx context.Context, ventaID uint) ([]entities.TransicionVenta, error) {
+	var transiciones []entities.TransicionVenta
+	err := conexion(ctx, r.db).
+		Where("venta_id = ?", ventaID).
+		Order("fecha, id").
+		Find(&transiciones).Error
+	if err != nil {
+		return nil, err
+	}
+	return transiciones, nil
+}
+
+// ordenarPorID ordena por ID las filas de una asociación precargada
+func ordenarPorID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// DetalleVentaRepositoryGorm implementa DetalleVentaRepository sobre GORM
+type DetalleVentaRepositoryGorm struct {
+	db *gorm.DB
+}
+
+// NewDetalleVentaRepositoryGorm crea un repositorio de detalles de venta sobre la conexión indicada
+func NewDetalleVentaRepositoryGorm(db *gorm.DB) *DetalleVentaRepositoryGorm {
+	return &DetalleVentaRepositoryGorm{db: db}
+}
+
+// ListarPorVenta retorna los detalles de una venta con su producto
+func (r *DetalleVentaRepositoryGorm) ListarPorVenta(ctx context.Context, ventaID uint) ([]entities.DetalleVenta, error) {
+	var detalles []entities.DetalleVenta
+	err := conexion(ctx, r.db).
+		Preload("Producto").
+		Where("venta_id = ?", ventaID).
+		Order("id").
+		Find(&detalles).Error
+	if err != nil {
+		return nil, err
+	}
+	return detalles, nil
+}
+
+// Guardar crea o actualiza un detalle de una venta existente
+func (r *DetalleVentaRepositoryGorm) Guardar(ctx context.Context, detalle *entities.DetalleVenta) error {
+	if !detalle.EsValido() {
+		return fmt.Errorf("%w: detalle de venta sin venta, producto, cantidad o precio", repositories.ErrEntidadInvalida)
+	}
+	return traducirError(conexion(ctx, r.db).Omit(clause.Associations).Save(detalle).Error)
+}
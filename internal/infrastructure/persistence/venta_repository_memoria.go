@@ -0,0 +1,240 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// VentaRepositoryMemoria implementa VentaRepository en memoria. Los detalles se
+// guardan en el repositorio de detalles, como en la base de datos.
+type VentaRepositoryMemoria struct {
+	ventas       map[uint]entities.Venta
+	detalles     *DetalleVentaRepositoryMemoria
+	transiciones []entities.TransicionVenta
+	siguienteID  uint
+	// IDs de las líneas de impuestos, que se guardan con la venta
+	siguienteImpuestoID uint
+	mutex               sync.RWMutex
+}
+
+// NewVentaRepositoryMemoria crea un repositorio de ventas vacío que guarda los
+// detalles en el repositorio indicado
+func NewVentaRepositoryMemoria(detalles *DetalleVentaRepositoryMemoria) *VentaRepositoryMemoria {
+	return &VentaRepositoryMemoria{
+		ventas:              make(map[uint]entities.Venta),
+		detalles:            detalles,
+		siguienteID:         1,
+		siguienteImpuestoID: 1,
+	}
+}
+
+// ObtenerPorID retorna la venta con el ID indicado junto con sus detalles
+func (r *VentaRepositoryMemoria) ObtenerPorID(ctx context.Context, id uint) (*entities.Venta, error) {
+	r.mutex.RLock()
+	venta, existe := r.ventas[id]
+	r.mutex.RUnlock()
+
+	if !existe {
+		return nil, repositories.ErrNoEncontrado
+	}
+
+	venta.DetallesVenta = r.detalles.listar(id)
+	return &venta, nil
+}
+
+// ListarPorSucursal retorna las ventas de una sucursal ordenadas por fecha
+func (r *VentaRepositoryMemoria) ListarPorSucursal(ctx context.Context, sucursalID uint) ([]entities.Venta, error) {
+	r.mutex.RLock()
+	var ventas []entities.Venta
+	for _, venta := range r.ventas {
+		if venta.SucursalID == sucursalID {
+			ventas = append(ventas, venta)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(ventas, func(i, j int) bool {
+		if !ventas[i].FechaVenta.Equal(ventas[j].FechaVenta) {
+			return ventas[i].FechaVenta.Before(ventas[j].FechaVenta)
+		}
+		return ventas[i].ID < ventas[j].ID
+	})
+	for i := range ventas {
+		ventas[i].DetallesVenta = r.detalles.listar(ventas[i].ID)
+	}
+	return ventas, nil
+}
+
+// Guardar crea o actualiza la venta y sus detalles, asignando los IDs faltantes,
+// y reemplaza su desglose de impuestos. Los detalles se validan antes de
+// guardar, de modo que una venta con un detalle inválido no se guarda en absoluto.
+func (r *VentaRepositoryMemoria) Guardar(ctx context.Context, venta *entities.Venta) error {
+	for i := range venta.DetallesVenta {
+		if !venta.DetallesVenta[i].EsLineaValida() {
+			return fmt.Errorf("%w: detalle %d de la venta sin producto, cantidad o precio", repositories.ErrEntidadInvalida, i+1)
+		}
+	}
+
+	r.mutex.Lock()
+	if venta.ID == 0 {
+		venta.ID = r.siguienteID
+	}
+	if venta.ID >= r.siguienteID {
+		r.siguienteID = venta.ID + 1
+	}
+
+	venta.VincularDetalles()
+	for i := range venta.ImpuestosVenta {
+		venta.ImpuestosVenta[i].ID = r.siguienteImpuestoID
+		r.siguienteImpuestoID++
+	}
+
+	guardada := *venta
+	guardada.DetallesVenta = nil
+	guardada.ImpuestosVenta = append([]entities.ImpuestoVenta(nil), venta.ImpuestosVenta...)
+	r.ventas[venta.ID] = guardada
+	r.mutex.Unlock()
+
+	for i := range venta.DetallesVenta {
+		if err := r.detalles.Guardar(ctx, &venta.DetallesVenta[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GuardarLote crea las ventas con sus detalles validando antes todas las líneas,
+// de modo que una venta inválida no deja el lote guardado a medias
+func (r *VentaRepositoryMemoria) GuardarLote(ctx context.Context, ventas []*entities.Venta) error {
+	for i, venta := range ventas {
+		if venta.ID != 0 {
+			return fmt.Errorf("%w: la venta %d del lote ya tiene ID", repositories.ErrEntidadInvalida, i+1)
+		}
+		for j := range venta.DetallesVenta {
+			if !venta.DetallesVenta[j].EsLineaValida() {
+				return fmt.Errorf("%w: detalle %d de la venta %d del lote sin producto, cantidad o precio", repositories.ErrEntidadInvalida, j+1, i+1)
+			}
+		}
+	}
+
+	for _, venta := range ventas {
+		if err := r.Guardar(ctx, venta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CambiarEstado guarda el estado de la venta y las unidades devueltas de sus
+// detalles, si el estado guardado sigue siendo desde
+func (r *VentaRepositoryMemoria) CambiarEstado(ctx context.Context, venta *entities.Venta, desde string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	guardada, existe := r.ventas[venta.ID]
+	if !existe || guardada.Estado != desde {
+		return repositories.ErrModificacionConcurrente
+	}
+	guardada.Estado = venta.Estado
+	r.ventas[venta.ID] = guardada
+	r.detalles.actualizarDevueltas(venta.ID, venta.DetallesVenta)
+	return nil
+}
+
+// RegistrarTransicion agrega la transición al historial de la venta
+func (r *VentaRepositoryMemoria) RegistrarTransicion(ctx context.Context, transicion *entities.TransicionVenta) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, existe := r.ventas[transicion.VentaID]; !existe {
+		return repositories.ErrReferenciaInvalida
+	}
+	transicion.ID = uint(len(r.transiciones) + 1)
+	r.transiciones = append(r.transiciones, *transicion)
+	return nil
+}
+
+// ListarTransiciones retorna el historial de estados de la venta, del más antiguo al más nuevo
+func (r *VentaRepositoryMemoria) ListarTransiciones(ctx context.Context, ventaID uint) ([]entities.TransicionVenta, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var transiciones []entities.TransicionVenta
+	for _, transicion := range r.transiciones {
+		if transicion.VentaID == ventaID {
+			transiciones = append(transiciones, transicion)
+		}
+	}
+	return transiciones, nil
+}
+
+// DetalleVentaRepositoryMemoria implementa DetalleVentaRepository en memoria
+type DetalleVentaRepositoryMemoria struct {
+	detalles    map[uint]entities.DetalleVenta
+	siguienteID uint
+	mutex       sync.RWMutex
+}
+
+// NewDetalleVentaRepositoryMemoria crea un repositorio de detalles de venta vacío
+func NewDetalleVentaRepositoryMemoria() *DetalleVentaRepositoryMemoria {
+	return &DetalleVentaRepositoryMemoria{
+		detalles:    make(map[uint]entities.DetalleVenta),
+		siguienteID: 1,
+	}
+}
+
+// ListarPorVenta retorna los detalles de una venta ordenados por ID
+func (r *DetalleVentaRepositoryMemoria) ListarPorVenta(ctx context.Context, ventaID uint) ([]entities.DetalleVenta, error) {
+	return r.listar(ventaID), nil
+}
+
+// Guardar crea o actualiza un detalle, asignando un ID si no lo tiene
+func (r *DetalleVentaRepositoryMemoria) Guardar(ctx context.Context, detalle *entities.DetalleVenta) error {
+	if !detalle.EsValido() {
+		return fmt.Errorf("%w: detalle de venta sin venta, producto, cantidad o precio", repositories.ErrEntidadInvalida)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if detalle.ID == 0 {
+		detalle.ID = r.siguienteID
+	}
+	if detalle.ID >= r.siguienteID {
+		r.siguienteID = detalle.ID + 1
+	}
+
+	r.detalles[detalle.ID] = *detalle
+	return nil
+}
+
+func (r *DetalleVentaRepositoryMemoria) actualizarDevueltas(ventaID uint, detalles []entities.DetalleVenta) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, detalle := range detalles {
+		if guardado, existe := r.detalles[detalle.ID]; existe && guardado.VentaID == ventaID {
+			guardado.CantidadDevuelta = detalle.CantidadDevuelta
+			r.detalles[detalle.ID] = guardado
+		}
+	}
+}
+
+func (r *DetalleVentaRepositoryMemoria) listar(ventaID uint) []entities.DetalleVenta {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var detalles []entities.DetalleVenta
+	for _, detalle := range r.detalles {
+		if detalle.VentaID == ventaID {
+			detalles = append(detalles, detalle)
+		}
+	}
+	sort.Slice(detalles, func(i, j int) bool { return detalles[i].ID < detalles[j].ID })
+	return detalles
+}
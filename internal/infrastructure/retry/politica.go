@@ -0,0 +1,209 @@
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Politica implementa una política de reintentos con backoff exponencial y jitter
+type Politica struct {
+	MaxIntentos   int           `json:"max_intentos"`
+	DemoraInicial time.Duration `json:"demora_inicial"`
+	DemoraMaxima  time.Duration `json:"demora_maxima"`
+	Multiplicador float64       `json:"multiplicador"`
+	Jitter        float64       `json:"jitter"` // fracción de la demora (0 a 1) aplicada al azar
+
+	// Transitorio reconoce errores transitorios que EsReintentable no clasifica,
+	// como los bloqueos o conflictos de serialización de una base de datos
+	Transitorio func(err error) bool `json:"-"`
+}
+
+// NewPolitica crea una política con backoff exponencial a partir de la cantidad
+// máxima de intentos y la demora del primer reintento
+func NewPolitica(maxIntentos int, demoraInicial time.Duration) *Politica {
+	if maxIntentos < 1 {
+		maxIntentos = 1
+	}
+	return &Politica{
+		MaxIntentos:   maxIntentos,
+		DemoraInicial: demoraInicial,
+		DemoraMaxima:  demoraInicial * 10,
+		Multiplicador: 2,
+		Jitter:        0.2,
+	}
+}
+
+// PoliticaPorDefecto retorna la política usada cuando no se configura otra
+func PoliticaPorDefecto() *Politica {
+	return NewPolitica(3, 30*time.Second)
+}
+
+// PoliticaPersistenciaPorDefecto retorna la política para las escrituras en la
+// base de datos, cuyas fallas transitorias se resuelven en fracciones de segundo
+func PoliticaPersistenciaPorDefecto() *Politica {
+	return NewPolitica(3, 200*time.Millisecond)
+}
+
+// Intento describe un intento fallido que será reintentado
+type Intento struct {
+	Numero int           `json:"numero"`
+	Error  error         `json:"error"`
+	Demora time.Duration `json:"demora"`
+}
+
+// NotificadorReintento se invoca antes de esperar cada reintento
+type NotificadorReintento func(intento Intento)
+
+// ErrorReintentosAgotados indica que la operación falló en todos los intentos permitidos
+type ErrorReintentosAgotados struct {
+	Intentos int
+	Ultimo   error
+}
+
+func (e *ErrorReintentosAgotados) Error() string {
+	return fmt.Sprintf("reintentos agotados tras %d intentos: %v", e.Intentos, e.Ultimo)
+}
+
+func (e *ErrorReintentosAgotados) Unwrap() error {
+	return e.Ultimo
+}
+
+// ErrorPermanente marca un error que no debe reintentarse
+type ErrorPermanente struct {
+	Err error
+}
+
+func (e *ErrorPermanente) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ErrorPermanente) Unwrap() error {
+	return e.Err
+}
+
+// Permanente envuelve un error para que la política no lo reintente
+func Permanente(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ErrorPermanente{Err: err}
+}
+
+// EsReintentable clasifica un error como transitorio (reintentable) o permanente.
+// Los errores que implementan Reintentable() deciden por sí mismos; los de red,
+// los plazos vencidos y las conexiones perdidas son transitorios, y cualquier
+// otro error se considera permanente.
+func EsReintentable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var permanente *ErrorPermanente
+	if errors.As(err, &permanente) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var clasificable interface{ Reintentable() bool }
+	if errors.As(err, &clasificable) {
+		return clasificable.Reintentable()
+	}
+
+	// syscall.Errno también implementa net.Error: de sus códigos solo son
+	// transitorios los de conexión que se listan abajo
+	var errorRed net.Error
+	if errors.As(err, &errorRed) {
+		if _, esErrno := errorRed.(syscall.Errno); !esErrno {
+			return true
+		}
+	}
+
+	for _, transitorio := range []error{
+		context.DeadlineExceeded, driver.ErrBadConn, sql.ErrConnDone, io.ErrUnexpectedEOF,
+		syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.EPIPE,
+	} {
+		if errors.Is(err, transitorio) {
+			return true
+		}
+	}
+	return false
+}
+
+// esReintentable agrega a EsReintentable los errores que la política reconoce como transitorios
+func (p *Politica) esReintentable(err error) bool {
+	if EsReintentable(err) {
+		return true
+	}
+	var permanente *ErrorPermanente
+	if err == nil || errors.As(err, &permanente) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return p.Transitorio != nil && p.Transitorio(err)
+}
+
+// Ejecutar ejecuta la operación reintentándola según la política. Retorna nil
+// si algún intento tiene éxito, el error original si no es reintentable y
+// ErrorReintentosAgotados si se alcanzó la cantidad máxima de intentos.
+func (p *Politica) Ejecutar(ctx context.Context, operacion func(ctx context.Context) error, notificar NotificadorReintento) error {
+	var ultimoErr error
+
+	for intento := 1; intento <= p.MaxIntentos; intento++ {
+		ultimoErr = operacion(ctx)
+		if ultimoErr == nil {
+			return nil
+		}
+
+		if !p.esReintentable(ultimoErr) {
+			return ultimoErr
+		}
+
+		if intento == p.MaxIntentos {
+			break
+		}
+
+		demora := p.Demora(intento)
+		if notificar != nil {
+			notificar(Intento{Numero: intento, Error: ultimoErr, Demora: demora})
+		}
+
+		timer := time.NewTimer(demora)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return &ErrorReintentosAgotados{Intentos: p.MaxIntentos, Ultimo: ultimoErr}
+}
+
+// Demora calcula la espera previa al reintento siguiente al intento indicado
+func (p *Politica) Demora(intento int) time.Duration {
+	base := float64(p.DemoraInicial) * math.Pow(p.Multiplicador, float64(intento-1))
+	if p.DemoraMaxima > 0 && base > float64(p.DemoraMaxima) {
+		base = float64(p.DemoraMaxima)
+	}
+
+	if p.Jitter > 0 {
+		variacion := base * p.Jitter
+		base += (rand.Float64()*2 - 1) * variacion
+	}
+
+	if base < 0 {
+		base = 0
+	}
+	return time.Duration(base)
+}
@@ -0,0 +1,164 @@
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// errorClasificable decide por sí mismo si se reintenta
+type errorClasificable bool
+
+func (e errorClasificable) Error() string      { return "error clasificable" }
+func (e errorClasificable) Reintentable() bool { return bool(e) }
+
+func TestEsReintentable(t *testing.T) {
+	errorRed := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")}
+
+	casos := []struct {
+		nombre   string
+		err      error
+		esperado bool
+	}{
+		{"nil", nil, false},
+		{"desconocido", errors.New("la venta ya tiene ID"), false},
+		{"desconocido envuelto", fmt.Errorf("guardando: %w", errors.New("restricción violada")), false},
+		{"permanente", Permanente(io.ErrUnexpectedEOF), false},
+		{"permanente envuelto", fmt.Errorf("lote: %w", Permanente(errorRed)), false},
+		{"cancelado", context.Canceled, false},
+		{"cancelado envuelto", fmt.Errorf("consultando: %w", context.Canceled), false},
+		{"clasificable transitorio", errorClasificable(true), true},
+		{"clasificable permanente", errorClasificable(false), false},
+		{"clasificable envuelto", fmt.Errorf("api: %w", errorClasificable(true)), true},
+		{"error de red", errorRed, true},
+		{"error de red envuelto", fmt.Errorf("conectando: %w", errorRed), true},
+		{"plazo vencido", context.DeadlineExceeded, true},
+		{"conexión inválida", driver.ErrBadConn, true},
+		{"conexión cerrada", sql.ErrConnDone, true},
+		{"fin inesperado", io.ErrUnexpectedEOF, true},
+		{"conexión reiniciada", fmt.Errorf("escribiendo: %w", syscall.ECONNRESET), true},
+		{"conexión rechazada", syscall.ECONNREFUSED, true},
+		{"tubería rota", syscall.EPIPE, true},
+		{"otro errno", syscall.ENOENT, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if obtenido := EsReintentable(caso.err); obtenido != caso.esperado {
+				t.Errorf("EsReintentable(%v) = %v, se esperaba %v", caso.err, obtenido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestTransitorioAmpliaLaClasificacion(t *testing.T) {
+	errBloqueo := errors.New("database is locked")
+	politica := NewPolitica(3, time.Millisecond)
+	politica.Transitorio = func(err error) bool { return errors.Is(err, errBloqueo) }
+
+	casos := []struct {
+		nombre   string
+		err      error
+		esperado bool
+	}{
+		{"reconocido", fmt.Errorf("guardando: %w", errBloqueo), true},
+		{"reconocido pero permanente", Permanente(errBloqueo), false},
+		{"clasificado por EsReintentable", io.ErrUnexpectedEOF, true},
+		{"desconocido", errors.New("otro"), false},
+		{"cancelado", context.Canceled, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			if obtenido := politica.esReintentable(caso.err); obtenido != caso.esperado {
+				t.Errorf("esReintentable(%v) = %v, se esperaba %v", caso.err, obtenido, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestEjecutar(t *testing.T) {
+	casos := []struct {
+		nombre   string
+		errores  []error // error de cada intento; los que faltan tienen éxito
+		intentos int
+		agotados bool
+		exitoso  bool
+	}{
+		{"éxito al primer intento", nil, 1, false, true},
+		{"éxito tras fallas transitorias", []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF}, 3, false, true},
+		{"transitorio en todos los intentos", []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF}, 3, true, false},
+		{"desconocido no se reintenta", []error{errors.New("ya tiene ID")}, 1, false, false},
+		{"permanente no se reintenta", []error{Permanente(io.ErrUnexpectedEOF)}, 1, false, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			politica := NewPolitica(3, time.Millisecond)
+			intentos, notificados := 0, 0
+			err := politica.Ejecutar(context.Background(), func(ctx context.Context) error {
+				intentos++
+				if intentos <= len(caso.errores) {
+					return caso.errores[intentos-1]
+				}
+				return nil
+			}, func(Intento) { notificados++ })
+
+			if intentos != caso.intentos {
+				t.Errorf("intentos = %d, se esperaban %d", intentos, caso.intentos)
+			}
+			if notificados != caso.intentos-1 {
+				t.Errorf("reintentos notificados = %d, se esperaban %d", notificados, caso.intentos-1)
+			}
+			if (err == nil) != caso.exitoso {
+				t.Errorf("Ejecutar = %v, se esperaba éxito %v", err, caso.exitoso)
+			}
+			var agotados *ErrorReintentosAgotados
+			if errors.As(err, &agotados) != caso.agotados {
+				t.Errorf("Ejecutar = %v, se esperaba reintentos agotados %v", err, caso.agotados)
+			}
+		})
+	}
+}
+
+func TestEjecutarSeDetieneAlCancelarElContexto(t *testing.T) {
+	ctx, cancelar := context.WithCancel(context.Background())
+	politica := NewPolitica(5, time.Hour)
+
+	intentos := 0
+	err := politica.Ejecutar(ctx, func(ctx context.Context) error {
+		intentos++
+		return io.ErrUnexpectedEOF
+	}, func(Intento) { cancelar() })
+
+	if !errors.Is(err, context.Canceled) || intentos != 1 {
+		t.Errorf("Ejecutar = %v tras %d intentos, se esperaba la cancelación tras 1", err, intentos)
+	}
+}
+
+func TestDemora(t *testing.T) {
+	politica := &Politica{MaxIntentos: 5, DemoraInicial: 100 * time.Millisecond, DemoraMaxima: 300 * time.Millisecond, Multiplicador: 2}
+
+	casos := []struct {
+		intento  int
+		esperada time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 300 * time.Millisecond},
+		{4, 300 * time.Millisecond},
+	}
+
+	for _, caso := range casos {
+		if demora := politica.Demora(caso.intento); demora != caso.esperada {
+			t.Errorf("Demora(%d) = %v, se esperaba %v", caso.intento, demora, caso.esperada)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ArchivoLotesHandler maneja la consulta y el reproceso de los lotes archivados
+type ArchivoLotesHandler struct {
+	procesador *services.ProcesadorDatosService
+}
+
+// NewArchivoLotesHandler crea una nueva instancia del handler
+func NewArchivoLotesHandler(procesador *services.ProcesadorDatosService) *ArchivoLotesHandler {
+	return &ArchivoLotesHandler{procesador: procesador}
+}
+
+// ReprocesoRequest selecciona los lotes archivados a reprocesar; los criterios
+// vacíos no filtran, pero debe indicarse al menos uno
+type ReprocesoRequest struct {
+	LoteIDs    []string `json:"lote_ids" example:"lote_1705314600000000000"`
+	SucursalID uint     `json:"sucursal_id" example:"1"`
+	Tipo       string   `json:"tipo" example:"producto"`
+	Desde      string   `json:"desde" example:"2024-01-01"`
+	Hasta      string   `json:"hasta" example:"2024-01-31"`
+	Aplicar    bool     `json:"aplicar" example:"false"` // sin aplicar, los cambios se simulan y descartan
+}
+
+// ReprocesoResponse resume el reproceso de los lotes seleccionados
+type ReprocesoResponse struct {
+	Aplicado       bool                     `json:"aplicado" example:"false"`
+	Lotes          int                      `json:"lotes" example:"3"`
+	ConDiferencias int                      `json:"con_diferencias" example:"1"`
+	ConErrores     int                      `json:"con_errores" example:"0"`
+	Resultados     []services.ReprocesoLote `json:"resultados"`
+}
+
+// ListarLotesArchivados godoc
+// @Summary Listar lotes archivados
+// @Description Obtiene los lotes crudos archivados, con su hash de contenido, tamaños y el resultado de su procesamiento
+// @Tags procesamiento
+// @Produce json
+// @Param lote_id query []string false "IDs de lote" collectionFormat(multi)
+// @Param sucursal_id query int false "ID de la sucursal"
+// @Param tipo query string false "Tipo de datos (producto, stock, venta)"
+// @Param desde query string false "Recibidos desde (AAAA-MM-DD o RFC3339)"
+// @Param hasta query string false "Recibidos hasta (AAAA-MM-DD inclusive, o RFC3339)"
+// @Success 200 {array} entities.LoteArchivado
+// @Failure 400 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/lotes/archivados [get]
+func (h *ArchivoLotesHandler) ListarLotesArchivados(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	consulta := r.URL.Query()
+	var sucursalID uint64
+	if valor := consulta.Get("sucursal_id"); valor != "" {
+		var err error
+		if sucursalID, err = strconv.ParseUint(valor, 10, 64); err != nil {
+			http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+			return
+		}
+	}
+	filtro, err := filtroLotes(consulta["lote_id"], uint(sucursalID), consulta.Get("tipo"), consulta.Get("desde"), consulta.Get("hasta"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	lotes, err := h.procesador.ListarLotesArchivados(r.Context(), filtro)
+	if err != nil {
+		responderErrorArchivo(w, err)
+		return
+	}
+	if lotes == nil {
+		lotes = []entities.LoteArchivado{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lotes)
+}
+
+// ReprocesarLotes godoc
+// @Summary Reprocesar lotes archivados
+// @Description Vuelve a procesar los lotes archivados seleccionados con la configuración actual y compara cada resultado con el registrado al procesarlos. Por defecto los cambios se simulan y descartan; con aplicar=true cada lote se procesa como un lote nuevo. No se aplican lotes de ventas ni de movimientos de stock, que se registrarían otra vez
+// @Tags procesamiento
+// @Accept json
+// @Produce json
+// @Param request body ReprocesoRequest true "Lotes a reprocesar"
+// @Success 200 {object} ReprocesoResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/lotes/reprocesar [post]
+func (h *ArchivoLotesHandler) ReprocesarLotes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var solicitud ReprocesoRequest
+	if err := json.NewDecoder(r.Body).Decode(&solicitud); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+	filtro, err := filtroLotes(solicitud.LoteIDs, solicitud.SucursalID, solicitud.Tipo, solicitud.Desde, solicitud.Hasta)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reprocesos, err := h.procesador.ReprocesarLotes(r.Context(), filtro, solicitud.Aplicar)
+	if err != nil {
+		responderErrorArchivo(w, err)
+		return
+	}
+
+	respuesta := ReprocesoResponse{Aplicado: solicitud.Aplicar, Lotes: len(reprocesos), Resultados: reprocesos}
+	for _, reproceso := range reprocesos {
+		if len(reproceso.Diferencias) > 0 {
+			respuesta.ConDiferencias++
+		}
+		if reproceso.Error != "" {
+			respuesta.ConErrores++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(respuesta)
+}
+
+// filtroLotes arma el filtro de lotes archivados. Una fecha hasta sin hora
+// incluye el día completo.
+func filtroLotes(loteIDs []string, sucursalID uint, tipo, desde, hasta string) (repositories.FiltroLotesArchivados, error) {
+	filtro := repositories.FiltroLotesArchivados{LoteIDs: loteIDs, SucursalID: sucursalID, Tipo: tipo}
+	if desde != "" {
+		fecha, _, err := parsearFecha(desde)
+		if err != nil {
+			return filtro, fmt.Errorf("desde inválido: use AAAA-MM-DD o RFC3339")
+		}
+		filtro.Desde = fecha
+	}
+	if hasta != "" {
+		fecha, soloFecha, err := parsearFecha(hasta)
+		if err != nil {
+			return filtro, fmt.Errorf("hasta inválido: use AAAA-MM-DD o RFC3339")
+		}
+		if soloFecha {
+			fecha = fecha.Add(24 * time.Hour)
+		}
+		filtro.Hasta = fecha
+	}
+	return filtro, nil
+}
+
+// responderErrorArchivo responde el error de una operación sobre el archivo de lotes
+func responderErrorArchivo(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDatosInvalidos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrSinArchivoLotes):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Error accediendo al archivo de lotes", http.StatusInternalServerError)
+	}
+}
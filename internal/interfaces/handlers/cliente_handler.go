@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ClienteHandler maneja el alta, modificación, consulta y baja de clientes potenciales
+type ClienteHandler struct {
+	clientes *services.ClienteService
+}
+
+// NewClienteHandler crea una nueva instancia del handler
+func NewClienteHandler(clientes *services.ClienteService) *ClienteHandler {
+	return &ClienteHandler{clientes: clientes}
+}
+
+// ClienteRequest son los datos de un cliente potencial que se pueden escribir
+type ClienteRequest struct {
+	Nombre       string    `json:"nombre" example:"Ana Pérez"`
+	Email        string    `json:"email" example:"ana.perez@correo.com"`
+	Telefono     string    `json:"telefono" example:"+54 11 5555-1234"`
+	Fuente       string    `json:"fuente" example:"formulario_web"`
+	FechaCaptura time.Time `json:"fecha_captura" example:"2024-01-15T10:30:00Z"`
+	Interes      string    `json:"interes" example:"notebooks"`
+	Notas        string    `json:"notas" example:"Pidió presupuesto por 10 equipos"`
+	SucursalID   uint      `json:"sucursal_id" example:"1"`
+}
+
+// aCliente convierte la solicitud en el cliente potencial con el ID indicado
+func (c *ClienteRequest) aCliente(id uint) *entities.ClientePotencial {
+	return &entities.ClientePotencial{
+		ID:           id,
+		Nombre:       c.Nombre,
+		Email:        c.Email,
+		Telefono:     c.Telefono,
+		Fuente:       c.Fuente,
+		FechaCaptura: c.FechaCaptura,
+		Interes:      c.Interes,
+		Notas:        c.Notas,
+		SucursalID:   c.SucursalID,
+	}
+}
+
+// TransicionRequest pide el cambio de estado de un cliente potencial
+type TransicionRequest struct {
+	Estado  string `json:"estado" example:"contactado"`
+	Usuario string `json:"usuario" example:"jgomez"`
+	Motivo  string `json:"motivo" example:"Llamado telefónico"`
+}
+
+// TransicionResponse es el cliente potencial después de cambiar de estado, con
+// la transición registrada y, si se convirtió, el cliente creado
+type TransicionResponse struct {
+	ClientePotencial *entities.ClientePotencial  `json:"cliente_potencial"`
+	Transicion       *entities.TransicionCliente `json:"transicion"`
+	Cliente          *entities.Cliente           `json:"cliente,omitempty"`
+}
+
+// InteraccionRequest registra una interacción de un cliente potencial
+type InteraccionRequest struct {
+	Tipo    string    `json:"tipo" example:"visita"`
+	Usuario string    `json:"usuario" example:"jgomez"`
+	Fecha   time.Time `json:"fecha" example:"2024-01-20T16:00:00Z"`
+}
+
+// ClientesResponse es una página del listado de clientes potenciales
+type ClientesResponse struct {
+	Clientes     []entities.ClientePotencial `json:"clientes"`
+	Total        int64                       `json:"total" example:"42"`
+	Pagina       int                         `json:"pagina" example:"1"`
+	TamanoPagina int                         `json:"tamano_pagina" example:"20"`
+	TotalPaginas int                         `json:"total_paginas" example:"3"`
+}
+
+// RutaClientes despacha las rutas /api/clientes, /api/clientes/{id},
+// /api/clientes/{id}/transiciones y /api/clientes/{id}/interacciones
+func (h *ClienteHandler) RutaClientes(w http.ResponseWriter, r *http.Request) {
+	ruta := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/clientes"), "/")
+	if _, subruta, ok := strings.Cut(ruta, "/"); ok {
+		switch {
+		case subruta == "transiciones" && r.Method == http.MethodGet:
+			h.ListarTransiciones(w, r)
+		case subruta == "transiciones" && r.Method == http.MethodPost:
+			h.TransicionarCliente(w, r)
+		case subruta == "interacciones" && r.Method == http.MethodGet:
+			h.ListarInteracciones(w, r)
+		case subruta == "interacciones" && r.Method == http.MethodPost:
+			h.RegistrarInteraccion(w, r)
+		case subruta == "transiciones" || subruta == "interacciones":
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	switch {
+	case ruta == "" && r.Method == http.MethodGet:
+		h.ListarClientes(w, r)
+	case ruta == "" && r.Method == http.MethodPost:
+		h.CrearCliente(w, r)
+	case ruta != "" && r.Method == http.MethodGet:
+		h.GetCliente(w, r)
+	case ruta != "" && r.Method == http.MethodPut:
+		h.ActualizarCliente(w, r)
+	case ruta != "" && r.Method == http.MethodDelete:
+		h.EliminarCliente(w, r)
+	default:
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListarClientes godoc
+// @Summary Listar clientes potenciales
+// @Description Obtiene una página de los clientes potenciales, del captado más recientemente al más antiguo o, con orden=puntaje, del de mayor puntaje al de menor. Cada cliente incluye su puntaje y los factores que lo explican
+// @Tags clientes
+// @Produce json
+// @Param sucursal_id query int false "ID de la sucursal"
+// @Param fuente query string false "Fuente de captación"
+// @Param estado query string false "Estado (nuevo, contactado, calificado, convertido, descartado)"
+// @Param orden query string false "Orden (fecha_captura, puntaje)" default(fecha_captura)
+// @Param pagina query int false "Página, desde 1" default(1)
+// @Param tamano_pagina query int false "Clientes por página, hasta 100" default(20)
+// @Success 200 {object} ClientesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/clientes [get]
+func (h *ClienteHandler) ListarClientes(w http.ResponseWriter, r *http.Request) {
+	consulta := r.URL.Query()
+	filtro := repositories.FiltroClientes{
+		Fuente: strings.ToLower(strings.TrimSpace(consulta.Get("fuente"))),
+		Estado: strings.ToLower(strings.TrimSpace(consulta.Get("estado"))),
+		Orden:  strings.ToLower(strings.TrimSpace(consulta.Get("orden"))),
+	}
+	if valor := consulta.Get("sucursal_id"); valor != "" {
+		sucursalID, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+			return
+		}
+		filtro.SucursalID = uint(sucursalID)
+	}
+	pagina, ok := enteroConsulta(w, r, "pagina")
+	if !ok {
+		return
+	}
+	tamanoPagina, ok := enteroConsulta(w, r, "tamano_pagina")
+	if !ok {
+		return
+	}
+
+	resultado, err := h.clientes.Listar(r.Context(), filtro, pagina, tamanoPagina)
+	if err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ClientesResponse{
+		Clientes:     resultado.Clientes,
+		Total:        resultado.Total,
+		Pagina:       resultado.Pagina,
+		TamanoPagina: resultado.TamanoPagina,
+		TotalPaginas: resultado.TotalPaginas(),
+	})
+}
+
+// GetCliente godoc
+// @Summary Consultar un cliente potencial
+// @Description Obtiene el cliente potencial indicado
+// @Tags clientes
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Success 200 {object} entities.ClientePotencial
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/clientes/{id} [get]
+func (h *ClienteHandler) GetCliente(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	cliente, err := h.clientes.ObtenerPorID(r.Context(), id)
+	if err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cliente)
+}
+
+// CrearCliente godoc
+// @Summary Registrar un cliente potencial
+// @Description Registra un cliente potencial en estado nuevo y publica el evento cliente_potencial_creado. Requiere nombre, sucursal existente y email o teléfono; sin fecha_captura se toma la actual
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param cliente body ClienteRequest true "Datos del cliente potencial"
+// @Success 201 {object} entities.ClientePotencial
+// @Failure 400 {object} ErrorResponse
+// @Router /api/clientes [post]
+func (h *ClienteHandler) CrearCliente(w http.ResponseWriter, r *http.Request) {
+	var request ClienteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	cliente := request.aCliente(0)
+	if err := h.clientes.Crear(r.Context(), cliente); err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cliente)
+}
+
+// ActualizarCliente godoc
+// @Summary Modificar un cliente potencial
+// @Description Reemplaza los datos del cliente potencial. Si fecha_captura se omite se conserva la guardada; el estado cambia solo con POST /api/clientes/{id}/transiciones. Un cliente anonimizado no admite cambios
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Param cliente body ClienteRequest true "Datos del cliente potencial"
+// @Success 200 {object} entities.ClientePotencial
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/clientes/{id} [put]
+func (h *ClienteHandler) ActualizarCliente(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	var request ClienteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	cliente := request.aCliente(id)
+	if err := h.clientes.Actualizar(r.Context(), cliente); err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cliente)
+}
+
+// EliminarCliente godoc
+// @Summary Eliminar un cliente potencial
+// @Description Elimina el cliente potencial indicado
+// @Tags clientes
+// @Param id path int true "ID del cliente potencial"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/clientes/{id} [delete]
+func (h *ClienteHandler) EliminarCliente(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.clientes.Eliminar(r.Context(), id); err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListarTransiciones godoc
+// @Summary Historial de estados de un cliente potencial
+// @Description Obtiene los cambios de estado del cliente potencial, del más antiguo al más nuevo, con el usuario que los hizo
+// @Tags clientes
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Success 200 {array} entities.TransicionCliente
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/clientes/{id}/transiciones [get]
+func (h *ClienteHandler) ListarTransiciones(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	transiciones, err := h.clientes.ListarTransiciones(r.Context(), id)
+	if err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transiciones)
+}
+
+// TransicionarCliente godoc
+// @Summary Cambiar el estado de un cliente potencial
+// @Description Pasa el cliente potencial al estado indicado y publica cliente_potencial_estado. Se admite nuevo → contactado → calificado → convertido, y pasar a descartado desde cualquier estado no final. Al pasar a convertido se crea el cliente al que se refieren sus ventas y se publica además cliente_potencial_convertido
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Param transicion body TransicionRequest true "Estado nuevo y usuario que lo cambia"
+// @Success 200 {object} TransicionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/clientes/{id}/transiciones [post]
+func (h *ClienteHandler) TransicionarCliente(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	var request TransicionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	resultado, err := h.clientes.Transicionar(r.Context(), id, request.Estado, request.Usuario, request.Motivo)
+	if err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TransicionResponse{
+		ClientePotencial: resultado.ClientePotencial,
+		Transicion:       resultado.Transicion,
+		Cliente:          resultado.Cliente,
+	})
+}
+
+// ListarInteracciones godoc
+// @Summary Interacciones de un cliente potencial
+// @Description Obtiene las interacciones del cliente potencial, de la más antigua a la más nueva
+// @Tags clientes
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Success 200 {array} entities.InteraccionCliente
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/clientes/{id}/interacciones [get]
+func (h *ClienteHandler) ListarInteracciones(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	interacciones, err := h.clientes.ListarInteracciones(r.Context(), id)
+	if err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(interacciones)
+}
+
+// RegistrarInteraccion godoc
+// @Summary Registrar una interacción de un cliente potencial
+// @Description Registra una interacción del cliente potencial, como una visita o una llamada, y publica cliente_potencial_interaccion, que recalcula su puntaje. Sin fecha se toma la actual
+// @Tags clientes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID del cliente potencial"
+// @Param interaccion body InteraccionRequest true "Datos de la interacción"
+// @Success 201 {object} entities.InteraccionCliente
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/clientes/{id}/interacciones [post]
+func (h *ClienteHandler) RegistrarInteraccion(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCliente(w, r)
+	if !ok {
+		return
+	}
+
+	var request InteraccionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	interaccion := &entities.InteraccionCliente{
+		ClientePotencialID: id,
+		Tipo:               request.Tipo,
+		Usuario:            request.Usuario,
+		Fecha:              request.Fecha,
+	}
+	if err := h.clientes.RegistrarInteraccion(r.Context(), interaccion); err != nil {
+		responderErrorCliente(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(interaccion)
+}
+
+// idCliente obtiene el ID de las rutas /api/clientes/{id}/..., respondiendo el error si es inválido
+func idCliente(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	valor, _, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/clientes"), "/"), "/")
+	id, err := strconv.ParseUint(valor, 10, 32)
+	if err != nil || id == 0 {
+		http.Error(w, "ID de cliente inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// enteroConsulta obtiene el parámetro entero opcional de la consulta,
+// respondiendo el error si es inválido; si se omite retorna 0
+func enteroConsulta(w http.ResponseWriter, r *http.Request, nombre string) (int, bool) {
+	valor := r.URL.Query().Get(nombre)
+	if valor == "" {
+		return 0, true
+	}
+	entero, err := strconv.Atoi(valor)
+	if err != nil || entero < 1 {
+		http.Error(w, nombre+" inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return entero, true
+}
+
+// responderErrorCliente responde el error de una operación sobre clientes potenciales
+func responderErrorCliente(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDatosInvalidos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTransicionInvalida), errors.Is(err, services.ErrClienteAnonimizado):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repositories.ErrModificacionConcurrente):
+		http.Error(w, "El cliente cambió de estado mientras se procesaba la solicitud; reintente", http.StatusConflict)
+	case errors.Is(err, repositories.ErrNoEncontrado):
+		http.Error(w, "Cliente no encontrado", http.StatusNotFound)
+	default:
+		http.Error(w, "Error accediendo al cliente", http.StatusInternalServerError)
+	}
+}
@@ -1,23 +1,38 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
 	"sistema-gestion-informacion/internal/infrastructure/builders"
+	"sistema-gestion-informacion/internal/infrastructure/connectors"
 	"sistema-gestion-informacion/internal/infrastructure/events"
 )
 
 // ProcesamientoHandler maneja las peticiones de procesamiento de datos
 type ProcesamientoHandler struct {
-	eventBus *events.EventBus
+	eventBus   *events.EventBus
+	procesador *services.ProcesadorDatosService
+	sucursales repositories.SucursalRepository
 }
 
 // NewProcesamientoHandler crea una nueva instancia del handler
-func NewProcesamientoHandler(eventBus *events.EventBus) *ProcesamientoHandler {
+func NewProcesamientoHandler(eventBus *events.EventBus, procesador *services.ProcesadorDatosService, sucursales repositories.SucursalRepository) *ProcesamientoHandler {
 	return &ProcesamientoHandler{
-		eventBus: eventBus,
+		eventBus:   eventBus,
+		procesador: procesador,
+		sucursales: sucursales,
 	}
 }
 
@@ -50,6 +65,27 @@ type ReporteResponse struct {
 	Resumen     string                 `json:"Resumen" example:"Reporte de ventas del período"`
 }
 
+type LoteEncoladoResponse struct {
+	Status       string `json:"status" example:"Lote encolado para procesamiento"`
+	LoteID       string `json:"lote_id" example:"lote_1705314600000000000"`
+	Formato      string `json:"formato" example:"csv"`
+	Codificacion string `json:"codificacion,omitempty" example:"windows-1252"`
+	Registros    int    `json:"registros" example:"120"`
+	URLEstado    string `json:"url_estado" example:"/api/lotes/lote_1705314600000000000"`
+	Time         string `json:"time" example:"2024-01-15T10:30:00Z"`
+}
+
+type ProgresoStreamResponse struct {
+	Chunk                int      `json:"chunk" example:"1"`
+	LoteID               string   `json:"lote_id" example:"lote_1705314600000000000_1"`
+	Registros            int      `json:"registros" example:"1000"`
+	RegistrosAcumulados  int      `json:"registros_acumulados" example:"1000"`
+	RegistrosPersistidos int      `json:"registros_persistidos" example:"998"`
+	Final                bool     `json:"final" example:"false"`
+	Error                string   `json:"error,omitempty" example:""`
+	Chunks               []string `json:"chunks,omitempty"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error" example:"Bad Request"`
 	Message string `json:"message" example:"JSON inválido"`
@@ -110,6 +146,342 @@ func (h *ProcesamientoHandler) ProcesarDatos(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(response)
 }
 
+// tamanioMaximoArchivo limita el tamaño de los archivos subidos (20 MB)
+const tamanioMaximoArchivo = 20 << 20
+
+// ProcesarArchivo godoc
+// @Summary Procesar archivo exportado por una sucursal
+// @Description Recibe un archivo CSV, XLSX, JSON, NDJSON, XML o de ancho fijo, detecta su formato por contenido o por la configuración de la sucursal y lo encola para procesamiento asíncrono
+// @Tags procesamiento
+// @Accept multipart/form-data
+// @Produce json
+// @Param archivo formData file true "Archivo exportado (máximo 20 MB)"
+// @Param sucursal_id formData int true "ID de la sucursal que exporta el archivo"
+// @Param tipo formData string true "Tipo de datos: venta, producto o stock"
+// @Param codificacion formData string false "Codificación de origen (utf-8, windows-1252, iso-8859-1, iso-8859-15 o auto). Por defecto se usa la configurada para la sucursal o se detecta"
+// @Success 202 {object} LoteEncoladoResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/procesar/archivo [post]
+func (h *ProcesamientoHandler) ProcesarArchivo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Margen para los campos del formulario además del archivo
+	r.Body = http.MaxBytesReader(w, r.Body, tamanioMaximoArchivo+1<<20)
+	if err := r.ParseMultipartForm(tamanioMaximoArchivo); err != nil {
+		var errTamanio *http.MaxBytesError
+		if errors.As(err, &errTamanio) {
+			http.Error(w, "El archivo supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Formulario multipart inválido", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	sucursalID, err := strconv.ParseUint(r.FormValue("sucursal_id"), 10, 64)
+	if err != nil || sucursalID == 0 {
+		http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+		return
+	}
+
+	tipo := strings.ToLower(strings.TrimSpace(r.FormValue("tipo")))
+	if !services.EsTipoDatos(tipo) {
+		http.Error(w, "tipo inválido: se acepta venta, producto o stock", http.StatusBadRequest)
+		return
+	}
+
+	sucursal, ok := h.sucursalDelLote(w, r, uint(sucursalID))
+	if !ok {
+		return
+	}
+
+	archivo, cabecera, err := r.FormFile("archivo")
+	if err != nil {
+		http.Error(w, "El campo 'archivo' es requerido", http.StatusBadRequest)
+		return
+	}
+	defer archivo.Close()
+
+	if cabecera.Size > tamanioMaximoArchivo {
+		http.Error(w, "El archivo supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	contenido, err := io.ReadAll(archivo)
+	if err != nil {
+		http.Error(w, "Error leyendo el archivo", http.StatusBadRequest)
+		return
+	}
+
+	formato, err := connectors.DetectarFormato(cabecera.Filename, contenido)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Las sucursales de ancho fijo o XML leen el archivo con su layout o mapeo
+	formato, lector, err := connectors.LectorDeSucursal(sucursal, formato)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Los XLSX almacenan el texto en UTF-8; el resto puede venir en codificaciones heredadas
+	var codificacion string
+	if formato != "xlsx" {
+		codificacion = r.FormValue("codificacion")
+		if codificacion == "" {
+			codificacion = connectors.CodificacionDeSucursal(sucursal)
+		}
+
+		contenido, codificacion, err = connectors.DecodificarUTF8(contenido, codificacion)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	registros, err := lector.Leer(bytes.NewReader(contenido))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("No se pudo interpretar el archivo: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	loteID := h.procesador.EncolarLote(&services.DatosCrudos{
+		Origen:     "archivo:" + cabecera.Filename,
+		Tipo:       tipo,
+		Datos:      registros,
+		Timestamp:  time.Now(),
+		SucursalID: uint(sucursalID),
+	})
+
+	response := LoteEncoladoResponse{
+		Status:       "Lote encolado para procesamiento",
+		LoteID:       loteID,
+		Formato:      formato,
+		Codificacion: codificacion,
+		Registros:    len(registros),
+		URLEstado:    "/api/lotes/" + loteID,
+		Time:         time.Now().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", response.URLEstado)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// sucursalDelLote obtiene la sucursal que envía los datos, respondiendo el
+// error si no existe o no puede consultarse
+func (h *ProcesamientoHandler) sucursalDelLote(w http.ResponseWriter, r *http.Request, sucursalID uint) (*entities.Sucursal, bool) {
+	sucursal, err := h.sucursales.ObtenerPorID(r.Context(), sucursalID)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		http.Error(w, "Sucursal no encontrada", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error consultando la sucursal", http.StatusInternalServerError)
+		return nil, false
+	}
+	return sucursal, true
+}
+
+// tamanioChunkPorDefecto y tamanioChunkMaximo acotan la cantidad de registros
+// que el endpoint de streaming procesa por vez
+const (
+	tamanioChunkPorDefecto = 1000
+	tamanioChunkMaximo     = 10000
+)
+
+// Plazos del endpoint de streaming: el de lectura y escritura se renueva antes
+// de leer cada chunk y de procesarlo, y reemplaza a los del servidor; el de
+// procesamiento acota los reintentos de persistencia de cada chunk
+const (
+	plazoStream             = 2 * time.Minute
+	plazoProcesamientoChunk = time.Minute
+)
+
+// ProcesarStream godoc
+// @Summary Procesar registros en streaming (NDJSON)
+// @Description Recibe un objeto JSON por línea, decodifica los registros de a uno, los procesa en chunks y responde el progreso de cada chunk como NDJSON
+// @Tags procesamiento
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param sucursal_id query int true "ID de la sucursal que envía los datos"
+// @Param tipo query string true "Tipo de datos: venta, producto o stock"
+// @Param tamanio_chunk query int false "Registros por chunk (por defecto 1000, máximo 10000)"
+// @Success 200 {object} ProgresoStreamResponse "Una línea por chunk y una línea final con final=true"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/procesar/stream [post]
+func (h *ProcesamientoHandler) ProcesarStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tipoContenido := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	if tipoContenido != "application/x-ndjson" && tipoContenido != "application/jsonl" {
+		http.Error(w, "Content-Type debe ser application/x-ndjson", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	sucursalID, err := strconv.ParseUint(r.URL.Query().Get("sucursal_id"), 10, 64)
+	if err != nil || sucursalID == 0 {
+		http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+		return
+	}
+
+	tipo := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tipo")))
+	if !services.EsTipoDatos(tipo) {
+		http.Error(w, "tipo inválido: se acepta venta, producto o stock", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.sucursalDelLote(w, r, uint(sucursalID)); !ok {
+		return
+	}
+
+	tamanioChunk := tamanioChunkPorDefecto
+	if valor := r.URL.Query().Get("tamanio_chunk"); valor != "" {
+		tamanioChunk, err = strconv.Atoi(valor)
+		if err != nil || tamanioChunk <= 0 || tamanioChunk > tamanioChunkMaximo {
+			http.Error(w, fmt.Sprintf("tamanio_chunk debe estar entre 1 y %d", tamanioChunkMaximo), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Permitir escribir el progreso mientras se sigue leyendo el cuerpo
+	controlador := http.NewResponseController(w)
+	controlador.EnableFullDuplex()
+
+	// Renovar los plazos del servidor para no cortar envíos largos
+	renovarPlazos := func() {
+		plazo := time.Now().Add(plazoStream)
+		controlador.SetReadDeadline(plazo)
+		controlador.SetWriteDeadline(plazo)
+	}
+	renovarPlazos()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+
+	loteID := services.NuevoLoteID()
+	decoder := json.NewDecoder(r.Body)
+	chunk := make([]map[string]interface{}, 0, tamanioChunk)
+	final := ProgresoStreamResponse{LoteID: loteID, Final: true}
+
+	procesarChunk := func() error {
+		final.Chunk++
+		renovarPlazos()
+
+		ctx, cancelar := context.WithTimeout(r.Context(), plazoProcesamientoChunk)
+		defer cancelar()
+		resultado, err := h.procesador.ProcesarLote(ctx, &services.DatosCrudos{
+			LoteID:     fmt.Sprintf("%s_%d", loteID, final.Chunk),
+			Origen:     "stream",
+			Tipo:       tipo,
+			Datos:      chunk,
+			Timestamp:  time.Now(),
+			SucursalID: uint(sucursalID),
+		})
+
+		progreso := ProgresoStreamResponse{
+			Chunk:               final.Chunk,
+			Registros:           len(chunk),
+			RegistrosAcumulados: final.RegistrosAcumulados + len(chunk),
+		}
+		if resultado != nil {
+			progreso.LoteID = resultado.LoteID
+			progreso.RegistrosPersistidos = resultado.RegistrosPersistidos
+		}
+		if err != nil {
+			progreso.Error = err.Error()
+		}
+
+		final.RegistrosAcumulados = progreso.RegistrosAcumulados
+		final.RegistrosPersistidos += progreso.RegistrosPersistidos
+		final.Chunks = append(final.Chunks, progreso.LoteID)
+
+		encoder.Encode(progreso)
+		controlador.Flush()
+
+		chunk = make([]map[string]interface{}, 0, tamanioChunk)
+		renovarPlazos()
+		return err
+	}
+
+	for {
+		var registro map[string]interface{}
+		err := decoder.Decode(&registro)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			final.Error = fmt.Sprintf("registro %d inválido: %v", final.RegistrosAcumulados+len(chunk)+1, err)
+			break
+		}
+
+		chunk = append(chunk, registro)
+		if len(chunk) == tamanioChunk {
+			if err := procesarChunk(); err != nil {
+				final.Error = err.Error()
+				break
+			}
+		}
+	}
+
+	if final.Error == "" && len(chunk) > 0 {
+		if err := procesarChunk(); err != nil {
+			final.Error = err.Error()
+		}
+	}
+
+	final.Chunk = len(final.Chunks)
+	encoder.Encode(final)
+	controlador.Flush()
+}
+
+// GetEstadoLote godoc
+// @Summary Consultar estado de un lote
+// @Description Obtiene el estado y el resultado de un lote encolado para procesamiento asíncrono. Los lotes terminados se conservan 24 horas
+// @Tags procesamiento
+// @Produce json
+// @Param id path string true "ID del lote"
+// @Success 200 {object} services.EstadoLote
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/lotes/{id} [get]
+func (h *ProcesamientoHandler) GetEstadoLote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	loteID := strings.TrimPrefix(r.URL.Path, "/api/lotes/")
+	estado, existe := h.procesador.ObtenerEstadoLote(loteID)
+	if !existe {
+		http.Error(w, "Lote no encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(estado)
+}
+
 // GetDatosProcesados godoc
 // @Summary Consultar datos procesados
 // @Description Obtiene los datos procesados y depurados almacenados en memoria
@@ -167,8 +539,6 @@ func (h *ProcesamientoHandler) GetDatosProcesados(w http.ResponseWriter, r *http
 				"Ciudad":      "Ciudad Principal",
 				"TipoSistema": "api",
 				"ApiEndpoint": "https://api.sucursal.com",
-				"ApiKey":      "key123",
-				"ApiSecret":   "secret123",
 			},
 		},
 	}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// ProductoHandler maneja las consultas del catálogo de productos
+type ProductoHandler struct {
+	productos   repositories.ProductoRepository
+	precios     repositories.PrecioHistoricoRepository
+	movimientos repositories.MovimientoStockRepository
+}
+
+// NewProductoHandler crea una nueva instancia del handler
+func NewProductoHandler(
+	productos repositories.ProductoRepository,
+	precios repositories.PrecioHistoricoRepository,
+	movimientos repositories.MovimientoStockRepository,
+) *ProductoHandler {
+	return &ProductoHandler{
+		productos:   productos,
+		precios:     precios,
+		movimientos: movimientos,
+	}
+}
+
+type PrecioVigenteResponse struct {
+	SKU         string                   `json:"sku" example:"PROD-001"`
+	Fecha       string                   `json:"fecha" example:"2024-01-15T00:00:00Z"`
+	PrecioFinal float64                  `json:"precio_final" example:"90.45"`
+	Precio      entities.PrecioHistorico `json:"precio"`
+}
+
+type HistorialPreciosResponse struct {
+	SKU     string                     `json:"sku" example:"PROD-001"`
+	Precios []entities.PrecioHistorico `json:"precios"`
+}
+
+type SaldoSucursal struct {
+	SucursalID uint `json:"sucursal_id" example:"1"`
+	Stock      int  `json:"stock" example:"42"`
+}
+
+type MovimientosStockResponse struct {
+	SKU         string                     `json:"sku" example:"PROD-001"`
+	StockActual int                        `json:"stock_actual" example:"42"`
+	Saldos      []SaldoSucursal            `json:"saldos"`
+	Movimientos []entities.MovimientoStock `json:"movimientos"`
+}
+
+// RutaProductos despacha las rutas /api/productos/{sku}/precio,
+// /api/productos/{sku}/precios y /api/productos/{sku}/movimientos
+func (h *ProductoHandler) RutaProductos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ruta := strings.TrimPrefix(r.URL.Path, "/api/productos/")
+	switch {
+	case strings.HasSuffix(ruta, "/movimientos"):
+		h.GetMovimientosStock(w, r)
+	case strings.HasSuffix(ruta, "/precios"):
+		h.GetHistorialPrecios(w, r)
+	case strings.HasSuffix(ruta, "/precio"):
+		h.GetPrecioVigente(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// GetPrecioVigente godoc
+// @Summary Consultar el precio de un producto a una fecha
+// @Description Obtiene el precio y la oferta del producto vigentes en la fecha indicada según el historial de precios
+// @Tags productos
+// @Produce json
+// @Param sku path string true "SKU del producto"
+// @Param fecha query string false "Fecha (AAAA-MM-DD o RFC3339). Por defecto, el momento actual"
+// @Success 200 {object} PrecioVigenteResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/productos/{sku}/precio [get]
+func (h *ProductoHandler) GetPrecioVigente(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fecha := time.Now()
+	if valor := r.URL.Query().Get("fecha"); valor != "" {
+		var err error
+		if fecha, _, err = parsearFecha(valor); err != nil {
+			http.Error(w, "fecha inválida: use AAAA-MM-DD o RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+
+	producto, ok := h.obtenerProducto(w, r, "/precio")
+	if !ok {
+		return
+	}
+
+	precio, err := h.precios.ObtenerVigente(r.Context(), producto.ID, fecha)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		http.Error(w, "El producto no tenía precio en la fecha indicada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error consultando el historial de precios", http.StatusInternalServerError)
+		return
+	}
+
+	response := PrecioVigenteResponse{
+		SKU:         producto.SKU,
+		Fecha:       fecha.Format(time.RFC3339),
+		PrecioFinal: precio.CalcularPrecioFinal(),
+		Precio:      *precio,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetHistorialPrecios godoc
+// @Summary Consultar el historial de precios de un producto
+// @Description Obtiene todos los cambios de precio del producto con su período de vigencia y su origen
+// @Tags productos
+// @Produce json
+// @Param sku path string true "SKU del producto"
+// @Success 200 {object} HistorialPreciosResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/productos/{sku}/precios [get]
+func (h *ProductoHandler) GetHistorialPrecios(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	producto, ok := h.obtenerProducto(w, r, "/precios")
+	if !ok {
+		return
+	}
+
+	precios, err := h.precios.ListarPorProducto(r.Context(), producto.ID)
+	if err != nil {
+		http.Error(w, "Error consultando el historial de precios", http.StatusInternalServerError)
+		return
+	}
+	if precios == nil {
+		precios = []entities.PrecioHistorico{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HistorialPreciosResponse{SKU: producto.SKU, Precios: precios})
+}
+
+// GetMovimientosStock godoc
+// @Summary Consultar los movimientos de stock de un producto
+// @Description Obtiene el historial de movimientos de stock del producto (ventas, devoluciones, ajustes, transferencias y recepciones) y su stock en cada sucursal. La sucursal 0 agrupa el saldo inicial previo al registro de movimientos
+// @Tags productos
+// @Produce json
+// @Param sku path string true "SKU del producto"
+// @Param sucursal_id query int false "Solo los movimientos de esta sucursal"
+// @Success 200 {object} MovimientosStockResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/productos/{sku}/movimientos [get]
+func (h *ProductoHandler) GetMovimientosStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var sucursalID uint
+	if valor := r.URL.Query().Get("sucursal_id"); valor != "" {
+		id, err := strconv.ParseUint(valor, 10, 32)
+		if err != nil {
+			http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+			return
+		}
+		sucursalID = uint(id)
+	}
+
+	producto, ok := h.obtenerProducto(w, r, "/movimientos")
+	if !ok {
+		return
+	}
+
+	movimientos, err := h.movimientos.ListarPorProducto(r.Context(), producto.ID, sucursalID)
+	if err != nil {
+		http.Error(w, "Error consultando los movimientos de stock", http.StatusInternalServerError)
+		return
+	}
+	if movimientos == nil {
+		movimientos = []entities.MovimientoStock{}
+	}
+
+	porSucursal, err := h.movimientos.SaldosPorProducto(r.Context(), producto.ID)
+	if err != nil {
+		http.Error(w, "Error consultando los movimientos de stock", http.StatusInternalServerError)
+		return
+	}
+	saldos := make([]SaldoSucursal, 0, len(porSucursal))
+	for id, stock := range porSucursal {
+		if sucursalID == 0 || id == sucursalID {
+			saldos = append(saldos, SaldoSucursal{SucursalID: id, Stock: stock})
+		}
+	}
+	sort.Slice(saldos, func(i, j int) bool { return saldos[i].SucursalID < saldos[j].SucursalID })
+
+	response := MovimientosStockResponse{
+		SKU:         producto.SKU,
+		StockActual: producto.StockActual,
+		Saldos:      saldos,
+		Movimientos: movimientos,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// obtenerProducto resuelve el producto del SKU de la ruta, respondiendo el error si no existe
+func (h *ProductoHandler) obtenerProducto(w http.ResponseWriter, r *http.Request, sufijo string) (*entities.Producto, bool) {
+	sku := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/productos/"), sufijo)
+	if sku == "" || strings.Contains(sku, "/") {
+		http.Error(w, "SKU inválido", http.StatusBadRequest)
+		return nil, false
+	}
+
+	producto, err := h.productos.ObtenerPorSKU(r.Context(), sku)
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		http.Error(w, "Producto no encontrado", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error consultando el producto", http.StatusInternalServerError)
+		return nil, false
+	}
+	return producto, true
+}
+
+// parsearFecha interpreta una fecha AAAA-MM-DD o RFC3339 e indica si era solo una fecha, sin hora
+func parsearFecha(valor string) (time.Time, bool, error) {
+	if fecha, err := time.Parse("2006-01-02", valor); err == nil {
+		return fecha, true, nil
+	}
+	fecha, err := time.Parse(time.RFC3339, valor)
+	return fecha, false, err
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+)
+
+// ReporteHandler maneja la generación de reportes sobre los datos persistidos
+type ReporteHandler struct {
+	reportes *services.ReporteService
+}
+
+// NewReporteHandler crea una nueva instancia del handler
+func NewReporteHandler(reportes *services.ReporteService) *ReporteHandler {
+	return &ReporteHandler{reportes: reportes}
+}
+
+// GetReporteVentas godoc
+// @Summary Generar reporte de ventas por sucursal
+// @Description Genera el reporte de ventas completadas de la sucursal en el período, comparando cada línea con el precio de lista vigente a la fecha de la venta
+// @Tags reportes
+// @Produce json
+// @Param sucursal_id query int true "ID de la sucursal"
+// @Param desde query string true "Inicio del período (AAAA-MM-DD o RFC3339)"
+// @Param hasta query string true "Fin del período (AAAA-MM-DD inclusive, o RFC3339)"
+// @Success 200 {object} builders.Reporte
+// @Failure 400 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/reportes/ventas [get]
+func (h *ReporteHandler) GetReporteVentas(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	consulta := r.URL.Query()
+	sucursalID, err := strconv.ParseUint(consulta.Get("sucursal_id"), 10, 64)
+	if err != nil || sucursalID == 0 {
+		http.Error(w, "sucursal_id inválido", http.StatusBadRequest)
+		return
+	}
+
+	desde, _, errDesde := parsearFecha(consulta.Get("desde"))
+	hasta, soloFecha, errHasta := parsearFecha(consulta.Get("hasta"))
+	if errDesde != nil || errHasta != nil {
+		http.Error(w, "desde y hasta son requeridos: use AAAA-MM-DD o RFC3339", http.StatusBadRequest)
+		return
+	}
+	if soloFecha {
+		// Una fecha sin hora incluye el día completo
+		hasta = hasta.Add(24 * time.Hour)
+	}
+	if !desde.Before(hasta) {
+		http.Error(w, "desde debe ser anterior a hasta", http.StatusBadRequest)
+		return
+	}
+
+	reporte, err := h.reportes.VentasPorSucursal(r.Context(), uint(sucursalID), desde, hasta)
+	if err != nil {
+		http.Error(w, "Error generando el reporte", http.StatusInternalServerError)
+		return
+	}
+	storeUltimoReporte(reporte)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reporte)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+)
+
+// limitePurgasPorDefecto es la cantidad de registros de purga que se informan sin otro límite
+const limitePurgasPorDefecto = 50
+
+// RetencionHandler maneja la consulta de las políticas de retención y su auditoría
+type RetencionHandler struct {
+	retencion *services.RetencionService
+}
+
+// NewRetencionHandler crea una nueva instancia del handler
+func NewRetencionHandler(retencion *services.RetencionService) *RetencionHandler {
+	return &RetencionHandler{retencion: retencion}
+}
+
+// RetencionResponse informa las políticas de retención y las últimas purgas
+type RetencionResponse struct {
+	Politicas []services.EstadoPolitica `json:"politicas"`
+	Purgas    []entities.RegistroPurga  `json:"purgas"`
+}
+
+// GetRetencion godoc
+// @Summary Consultar políticas de retención y purgas
+// @Description Obtiene las políticas de retención configuradas en RETENCION_POLITICAS y los últimos registros de la auditoría de purgas, del más reciente al más antiguo. Una política inactiva corresponde a una clase sin datos para purgar
+// @Tags retencion
+// @Produce json
+// @Param clase query string false "Clase de datos (lotes_crudos, eventos, clientes_potenciales)"
+// @Param limite query int false "Cantidad máxima de purgas (50 por defecto)"
+// @Success 200 {object} RetencionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/retencion [get]
+func (h *RetencionHandler) GetRetencion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	consulta := r.URL.Query()
+	limite := limitePurgasPorDefecto
+	if valor := consulta.Get("limite"); valor != "" {
+		n, err := strconv.Atoi(valor)
+		if err != nil || n < 1 {
+			http.Error(w, "limite inválido", http.StatusBadRequest)
+			return
+		}
+		limite = n
+	}
+
+	purgas, err := h.retencion.ListarPurgas(r.Context(), consulta.Get("clase"), limite)
+	if err != nil {
+		http.Error(w, "Error consultando la auditoría de purgas", http.StatusInternalServerError)
+		return
+	}
+	if purgas == nil {
+		purgas = []entities.RegistroPurga{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RetencionResponse{Politicas: h.retencion.Politicas(), Purgas: purgas})
+}
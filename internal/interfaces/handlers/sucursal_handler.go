@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// SucursalHandler maneja el alta, modificación y consulta de sucursales
+type SucursalHandler struct {
+	sucursales *services.SucursalService
+}
+
+// NewSucursalHandler crea una nueva instancia del handler
+func NewSucursalHandler(sucursales *services.SucursalService) *SucursalHandler {
+	return &SucursalHandler{sucursales: sucursales}
+}
+
+// SucursalRequest son los datos de una sucursal que se pueden escribir. Las
+// credenciales nunca se incluyen en las respuestas.
+type SucursalRequest struct {
+	Nombre        string    `json:"nombre" example:"Sucursal Centro"`
+	Direccion     string    `json:"direccion" example:"Av. Principal 123"`
+	Telefono      string    `json:"telefono" example:"+1234567890"`
+	Email         string    `json:"email" example:"centro@empresa.com"`
+	Ciudad        string    `json:"ciudad" example:"Ciudad Principal"`
+	Estado        string    `json:"estado" example:"activa"`
+	FechaApertura time.Time `json:"fecha_apertura" example:"2020-03-01T00:00:00Z"`
+	APIEndpoint   string    `json:"api_endpoint" example:"https://api.sucursal.com"`
+	APIKey        string    `json:"api_key,omitempty" example:"key123"`
+	APISecret     string    `json:"api_secret,omitempty" example:"secret123"`
+	TipoSistema   string    `json:"tipo_sistema" example:"api"`
+	Configuracion string    `json:"configuracion" example:"{\"parametros\":{}}"`
+}
+
+// aSucursal convierte la solicitud en la sucursal con el ID indicado
+func (s *SucursalRequest) aSucursal(id uint) *entities.Sucursal {
+	return &entities.Sucursal{
+		ID:            id,
+		Nombre:        s.Nombre,
+		Direccion:     s.Direccion,
+		Telefono:      s.Telefono,
+		Email:         s.Email,
+		Ciudad:        s.Ciudad,
+		Estado:        s.Estado,
+		FechaApertura: s.FechaApertura,
+		APIEndpoint:   s.APIEndpoint,
+		APIKey:        s.APIKey,
+		APISecret:     s.APISecret,
+		TipoSistema:   s.TipoSistema,
+		Configuracion: s.Configuracion,
+	}
+}
+
+// RutaSucursales despacha las rutas /api/sucursales y /api/sucursales/{id}
+func (h *SucursalHandler) RutaSucursales(w http.ResponseWriter, r *http.Request) {
+	ruta := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/sucursales"), "/")
+	switch {
+	case ruta == "" && r.Method == http.MethodGet:
+		h.ListarSucursales(w, r)
+	case ruta == "" && r.Method == http.MethodPost:
+		h.CrearSucursal(w, r)
+	case ruta != "" && r.Method == http.MethodGet:
+		h.GetSucursal(w, r)
+	case ruta != "" && r.Method == http.MethodPut:
+		h.ActualizarSucursal(w, r)
+	default:
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListarSucursales godoc
+// @Summary Listar sucursales
+// @Description Obtiene las sucursales registradas. Las credenciales no se incluyen: api_key_configurada y api_secret_configurado indican si están cargadas
+// @Tags sucursales
+// @Produce json
+// @Success 200 {array} entities.Sucursal
+// @Failure 405 {object} ErrorResponse
+// @Router /api/sucursales [get]
+func (h *SucursalHandler) ListarSucursales(w http.ResponseWriter, r *http.Request) {
+	sucursales, err := h.sucursales.Listar(r.Context())
+	if err != nil {
+		http.Error(w, "Error consultando las sucursales", http.StatusInternalServerError)
+		return
+	}
+	if sucursales == nil {
+		sucursales = []entities.Sucursal{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sucursales)
+}
+
+// GetSucursal godoc
+// @Summary Consultar una sucursal
+// @Description Obtiene la sucursal indicada, sin sus credenciales
+// @Tags sucursales
+// @Produce json
+// @Param id path int true "ID de la sucursal"
+// @Success 200 {object} entities.Sucursal
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/sucursales/{id} [get]
+func (h *SucursalHandler) GetSucursal(w http.ResponseWriter, r *http.Request) {
+	id, ok := idSucursal(w, r)
+	if !ok {
+		return
+	}
+
+	sucursal, err := h.sucursales.ObtenerPorID(r.Context(), id)
+	if err != nil {
+		responderErrorSucursal(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sucursal)
+}
+
+// CrearSucursal godoc
+// @Summary Registrar una sucursal
+// @Description Registra una sucursal. api_key y api_secret se guardan cifrados y nunca se devuelven
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param sucursal body SucursalRequest true "Datos de la sucursal"
+// @Success 201 {object} entities.Sucursal
+// @Failure 400 {object} ErrorResponse
+// @Router /api/sucursales [post]
+func (h *SucursalHandler) CrearSucursal(w http.ResponseWriter, r *http.Request) {
+	var request SucursalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	sucursal := request.aSucursal(0)
+	if err := h.sucursales.Guardar(r.Context(), sucursal); err != nil {
+		responderErrorSucursal(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sucursal)
+}
+
+// ActualizarSucursal godoc
+// @Summary Modificar una sucursal
+// @Description Reemplaza los datos de la sucursal. Si api_key o api_secret se omiten se conservan los guardados
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la sucursal"
+// @Param sucursal body SucursalRequest true "Datos de la sucursal"
+// @Success 200 {object} entities.Sucursal
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/sucursales/{id} [put]
+func (h *SucursalHandler) ActualizarSucursal(w http.ResponseWriter, r *http.Request) {
+	id, ok := idSucursal(w, r)
+	if !ok {
+		return
+	}
+
+	var request SucursalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	sucursal := request.aSucursal(id)
+	if err := h.sucursales.Actualizar(r.Context(), sucursal); err != nil {
+		responderErrorSucursal(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sucursal)
+}
+
+// idSucursal obtiene el ID de la ruta /api/sucursales/{id}, respondiendo el error si es inválido
+func idSucursal(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	valor := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/sucursales"), "/")
+	id, err := strconv.ParseUint(valor, 10, 32)
+	if err != nil || id == 0 {
+		http.Error(w, "ID de sucursal inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// responderErrorSucursal responde el error de una operación sobre sucursales
+func responderErrorSucursal(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDatosInvalidos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repositories.ErrNoEncontrado):
+		http.Error(w, "Sucursal no encontrada", http.StatusNotFound)
+	default:
+		http.Error(w, "Error accediendo a la sucursal", http.StatusInternalServerError)
+	}
+}
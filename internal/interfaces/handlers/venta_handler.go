@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// VentaHandler maneja la consulta de ventas y sus cambios de estado
+type VentaHandler struct {
+	ventas *services.VentaService
+}
+
+// NewVentaHandler crea una nueva instancia del handler
+func NewVentaHandler(ventas *services.VentaService) *VentaHandler {
+	return &VentaHandler{ventas: ventas}
+}
+
+// DevolucionRequest indica las unidades devueltas de un detalle de la venta
+type DevolucionRequest struct {
+	DetalleID uint `json:"detalle_id" example:"12"`
+	Cantidad  int  `json:"cantidad" example:"1"`
+}
+
+// TransicionVentaRequest pide el cambio de estado de una venta
+type TransicionVentaRequest struct {
+	Estado       string              `json:"estado" example:"devuelta_parcial"`
+	Usuario      string              `json:"usuario" example:"jgomez"`
+	Motivo       string              `json:"motivo" example:"Producto fallado"`
+	Devoluciones []DevolucionRequest `json:"devoluciones"`
+}
+
+// TransicionVentaResponse es la venta después de cambiar de estado, con la
+// transición registrada y los movimientos de stock que generó
+type TransicionVentaResponse struct {
+	Venta       *entities.Venta             `json:"venta"`
+	Transicion  *entities.TransicionVenta   `json:"transicion"`
+	Movimientos []*entities.MovimientoStock `json:"movimientos"`
+}
+
+// RutaVentas despacha las rutas /api/ventas/{id} y /api/ventas/{id}/transiciones
+func (h *VentaHandler) RutaVentas(w http.ResponseWriter, r *http.Request) {
+	ruta := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ventas"), "/")
+	if _, subruta, ok := strings.Cut(ruta, "/"); ok {
+		switch {
+		case subruta == "transiciones" && r.Method == http.MethodGet:
+			h.ListarTransicionesVenta(w, r)
+		case subruta == "transiciones" && r.Method == http.MethodPost:
+			h.TransicionarVenta(w, r)
+		case subruta == "transiciones":
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	switch {
+	case ruta == "":
+		http.NotFound(w, r)
+	case r.Method == http.MethodGet:
+		h.GetVenta(w, r)
+	default:
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetVenta godoc
+// @Summary Consultar una venta
+// @Description Obtiene la venta indicada con sus detalles y las unidades devueltas de cada uno
+// @Tags ventas
+// @Produce json
+// @Param id path int true "ID de la venta"
+// @Success 200 {object} entities.Venta
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/ventas/{id} [get]
+func (h *VentaHandler) GetVenta(w http.ResponseWriter, r *http.Request) {
+	id, ok := idVenta(w, r)
+	if !ok {
+		return
+	}
+
+	venta, err := h.ventas.ObtenerPorID(r.Context(), id)
+	if err != nil {
+		responderErrorVenta(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(venta)
+}
+
+// ListarTransicionesVenta godoc
+// @Summary Historial de estados de una venta
+// @Description Obtiene los cambios de estado de la venta, del más antiguo al más nuevo, con el usuario que los hizo
+// @Tags ventas
+// @Produce json
+// @Param id path int true "ID de la venta"
+// @Success 200 {array} entities.TransicionVenta
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/ventas/{id}/transiciones [get]
+func (h *VentaHandler) ListarTransicionesVenta(w http.ResponseWriter, r *http.Request) {
+	id, ok := idVenta(w, r)
+	if !ok {
+		return
+	}
+
+	transiciones, err := h.ventas.ListarTransiciones(r.Context(), id)
+	if err != nil {
+		responderErrorVenta(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transiciones)
+}
+
+// TransicionarVenta godoc
+// @Summary Cambiar el estado de una venta
+// @Description Pasa la venta al estado indicado y publica venta_estado. Se admite pendiente → completada o anulada; completada → anulada, devuelta_parcial o devuelta; y devuelta_parcial → devuelta_parcial o devuelta. Completar una venta pendiente egresa su mercadería del stock. Anular una venta completada reingresa al stock las unidades no devueltas; una devolución reingresa las unidades devueltas, que en devuelta_parcial se indican por detalle y en devuelta, si se omiten, son todas las pendientes. Sólo se reingresan las unidades que egresaron por la venta. Cada movimiento publica stock_actualizado
+// @Tags ventas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la venta"
+// @Param transicion body TransicionVentaRequest true "Estado nuevo, usuario que lo cambia y unidades devueltas"
+// @Success 200 {object} TransicionVentaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/ventas/{id}/transiciones [post]
+func (h *VentaHandler) TransicionarVenta(w http.ResponseWriter, r *http.Request) {
+	id, ok := idVenta(w, r)
+	if !ok {
+		return
+	}
+
+	var request TransicionVentaRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	solicitud := services.SolicitudTransicionVenta{
+		Estado:  request.Estado,
+		Usuario: request.Usuario,
+		Motivo:  request.Motivo,
+	}
+	for _, devolucion := range request.Devoluciones {
+		solicitud.Devoluciones = append(solicitud.Devoluciones, services.DevolucionDetalle{
+			DetalleID: devolucion.DetalleID,
+			Cantidad:  devolucion.Cantidad,
+		})
+	}
+
+	resultado, err := h.ventas.Transicionar(r.Context(), id, solicitud)
+	if err != nil {
+		responderErrorVenta(w, err)
+		return
+	}
+
+	movimientos := resultado.Movimientos
+	if movimientos == nil {
+		movimientos = []*entities.MovimientoStock{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TransicionVentaResponse{
+		Venta:       resultado.Venta,
+		Transicion:  resultado.Transicion,
+		Movimientos: movimientos,
+	})
+}
+
+// idVenta obtiene el ID de las rutas /api/ventas/{id}/..., respondiendo el error si es inválido
+func idVenta(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	valor, _, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ventas"), "/"), "/")
+	id, err := strconv.ParseUint(valor, 10, 32)
+	if err != nil || id == 0 {
+		http.Error(w, "ID de venta inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// responderErrorVenta responde el error de una operación sobre ventas
+func responderErrorVenta(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrDatosInvalidos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTransicionInvalida):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repositories.ErrModificacionConcurrente):
+		http.Error(w, "La venta cambió de estado mientras se procesaba la solicitud; reintente", http.StatusConflict)
+	case errors.Is(err, repositories.ErrNoEncontrado):
+		http.Error(w, "Venta no encontrada", http.StatusNotFound)
+	default:
+		http.Error(w, "Error accediendo a la venta", http.StatusInternalServerError)
+	}
+}
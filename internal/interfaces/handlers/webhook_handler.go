@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/repositories"
+)
+
+// Cabeceras de la firma de los webhooks enviados por las sucursales
+const (
+	CabeceraTimestampWebhook = "X-Sucursal-Timestamp"
+	CabeceraFirmaWebhook     = "X-Sucursal-Firma"
+)
+
+// toleranciaWebhook es la antigüedad máxima aceptada para el timestamp firmado
+const toleranciaWebhook = 5 * time.Minute
+
+// tamanioMaximoWebhook limita el cuerpo de cada webhook (5 MB)
+const tamanioMaximoWebhook = 5 << 20
+
+// WebhookHandler recibe los datos que las sucursales envían en tiempo real
+type WebhookHandler struct {
+	procesador *services.ProcesadorDatosService
+	sucursales repositories.SucursalRepository
+
+	// firmas aceptadas dentro de la ventana de tolerancia, para rechazar reenvíos
+	firmasVistas map[string]time.Time
+	mutex        sync.Mutex
+}
+
+// NewWebhookHandler crea una nueva instancia del handler
+func NewWebhookHandler(procesador *services.ProcesadorDatosService, sucursales repositories.SucursalRepository) *WebhookHandler {
+	return &WebhookHandler{
+		procesador:   procesador,
+		sucursales:   sucursales,
+		firmasVistas: make(map[string]time.Time),
+	}
+}
+
+type WebhookSucursalRequest struct {
+	Tipo  string                   `json:"tipo" example:"venta"`
+	Datos []map[string]interface{} `json:"datos"`
+}
+
+type WebhookSucursalResponse struct {
+	Status    string `json:"status" example:"Datos recibidos"`
+	LoteID    string `json:"lote_id" example:"lote_1705314600000000000"`
+	Registros int    `json:"registros" example:"3"`
+	Time      string `json:"time" example:"2024-01-15T10:30:00Z"`
+}
+
+// RecibirWebhookSucursal godoc
+// @Summary Recibir datos enviados por una sucursal
+// @Description Recibe datos en tiempo real desde el sistema de una sucursal. La firma es el HMAC-SHA256 en hexadecimal de "<timestamp>.<cuerpo>" usando el API secret de la sucursal; el timestamp (segundos Unix) no puede tener más de 5 minutos de antigüedad.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la sucursal"
+// @Param X-Sucursal-Timestamp header string true "Timestamp Unix en segundos"
+// @Param X-Sucursal-Firma header string true "HMAC-SHA256 hexadecimal, opcionalmente con prefijo sha256="
+// @Param request body WebhookSucursalRequest true "Datos enviados por la sucursal"
+// @Success 202 {object} WebhookSucursalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 405 {object} ErrorResponse
+// @Router /api/webhooks/sucursales/{id} [post]
+func (h *WebhookHandler) RecibirWebhookSucursal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sucursalID, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/api/webhooks/sucursales/"), 10, 64)
+	if err != nil || sucursalID == 0 {
+		http.Error(w, "ID de sucursal inválido", http.StatusBadRequest)
+		return
+	}
+
+	sucursal, err := h.sucursales.ObtenerPorID(r.Context(), uint(sucursalID))
+	if errors.Is(err, repositories.ErrNoEncontrado) {
+		http.Error(w, "Sucursal no encontrada", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error consultando la sucursal", http.StatusInternalServerError)
+		return
+	}
+
+	if !sucursal.EsActiva() || sucursal.APISecret == "" {
+		http.Error(w, "La sucursal no está habilitada para enviar webhooks", http.StatusUnauthorized)
+		return
+	}
+
+	cuerpo, err := io.ReadAll(http.MaxBytesReader(w, r.Body, tamanioMaximoWebhook))
+	if err != nil {
+		http.Error(w, "Cuerpo de la petición inválido", http.StatusBadRequest)
+		return
+	}
+
+	timestamp := r.Header.Get(CabeceraTimestampWebhook)
+	firma := strings.TrimPrefix(r.Header.Get(CabeceraFirmaWebhook), "sha256=")
+	if err := h.verificarFirma(sucursal.APISecret, timestamp, firma, cuerpo); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var request WebhookSucursalRequest
+	if err := json.Unmarshal(cuerpo, &request); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return
+	}
+
+	request.Tipo = strings.ToLower(strings.TrimSpace(request.Tipo))
+	if !services.EsTipoDatos(request.Tipo) {
+		http.Error(w, "tipo inválido: se acepta venta, producto o stock", http.StatusBadRequest)
+		return
+	}
+
+	datosCrudos := &services.DatosCrudos{
+		LoteID:     services.NuevoLoteID(),
+		Origen:     "webhook",
+		Tipo:       request.Tipo,
+		Datos:      request.Datos,
+		Timestamp:  time.Now(),
+		SucursalID: sucursal.ID,
+	}
+
+	loteID := h.procesador.EncolarLote(datosCrudos)
+
+	response := WebhookSucursalResponse{
+		Status:    "Datos recibidos",
+		LoteID:    loteID,
+		Registros: len(request.Datos),
+		Time:      time.Now().Format(time.RFC3339),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// FirmarWebhook calcula la firma esperada para un cuerpo y timestamp
+func FirmarWebhook(secreto, timestamp string, cuerpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(secreto))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(cuerpo)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verificarFirma valida el timestamp, la firma HMAC y que la firma no haya sido usada antes
+func (h *WebhookHandler) verificarFirma(secreto, timestamp, firma string, cuerpo []byte) error {
+	if timestamp == "" || firma == "" {
+		return fmt.Errorf("faltan las cabeceras %s y %s", CabeceraTimestampWebhook, CabeceraFirmaWebhook)
+	}
+
+	segundos, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp inválido")
+	}
+
+	antiguedad := time.Since(time.Unix(segundos, 0))
+	if antiguedad > toleranciaWebhook || antiguedad < -toleranciaWebhook {
+		return fmt.Errorf("timestamp fuera de la ventana de tolerancia")
+	}
+
+	esperada := FirmarWebhook(secreto, timestamp, cuerpo)
+	if !hmac.Equal([]byte(esperada), []byte(strings.ToLower(firma))) {
+		return fmt.Errorf("firma inválida")
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	ahora := time.Now()
+	for vista, vence := range h.firmasVistas {
+		if ahora.After(vence) {
+			delete(h.firmasVistas, vista)
+		}
+	}
+
+	if _, repetida := h.firmasVistas[esperada]; repetida {
+		return fmt.Errorf("la petición ya fue recibida")
+	}
+	h.firmasVistas[esperada] = time.Unix(segundos, 0).Add(toleranciaWebhook)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/infrastructure/persistence"
+)
+
+const secretoPrueba = "s3cr3t"
+
+func timestampDesde(desplazamiento time.Duration) string {
+	return strconv.FormatInt(time.Now().Add(desplazamiento).Unix(), 10)
+}
+
+func TestVerificarFirma(t *testing.T) {
+	cuerpo := []byte(`{"tipo":"venta","datos":[]}`)
+	ahora := timestampDesde(0)
+	reciente := timestampDesde(-4 * time.Minute)
+	vencido := timestampDesde(-6 * time.Minute)
+	futuro := timestampDesde(6 * time.Minute)
+
+	casos := []struct {
+		nombre    string
+		timestamp string
+		firma     string
+		mensaje   string
+	}{
+		{"válida", ahora, FirmarWebhook(secretoPrueba, ahora, cuerpo), ""},
+		{"válida en mayúsculas", ahora, strings.ToUpper(FirmarWebhook(secretoPrueba, ahora, cuerpo)), ""},
+		{"dentro de la tolerancia", reciente, FirmarWebhook(secretoPrueba, reciente, cuerpo), ""},
+		{"sin timestamp", "", FirmarWebhook(secretoPrueba, ahora, cuerpo), "faltan las cabeceras"},
+		{"sin firma", ahora, "", "faltan las cabeceras"},
+		{"timestamp no numérico", "ayer", "abc", "timestamp inválido"},
+		{"timestamp vencido", vencido, FirmarWebhook(secretoPrueba, vencido, cuerpo), "fuera de la ventana"},
+		{"timestamp futuro", futuro, FirmarWebhook(secretoPrueba, futuro, cuerpo), "fuera de la ventana"},
+		{"otro secreto", ahora, FirmarWebhook("otro", ahora, cuerpo), "firma inválida"},
+		{"otro cuerpo", ahora, FirmarWebhook(secretoPrueba, ahora, []byte(`{}`)), "firma inválida"},
+		{"firma de otro timestamp", ahora, FirmarWebhook(secretoPrueba, reciente, cuerpo), "firma inválida"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			h := NewWebhookHandler(nil, nil)
+			err := h.verificarFirma(secretoPrueba, caso.timestamp, caso.firma, cuerpo)
+			if caso.mensaje == "" {
+				if err != nil {
+					t.Errorf("verificarFirma: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), caso.mensaje) {
+				t.Errorf("verificarFirma = %v, se esperaba un error con %q", err, caso.mensaje)
+			}
+		})
+	}
+}
+
+func TestVerificarFirmaRechazaReenvios(t *testing.T) {
+	h := NewWebhookHandler(nil, nil)
+	cuerpo := []byte(`{"tipo":"venta","datos":[]}`)
+	timestamp := timestampDesde(0)
+	firma := FirmarWebhook(secretoPrueba, timestamp, cuerpo)
+
+	if err := h.verificarFirma(secretoPrueba, timestamp, firma, cuerpo); err != nil {
+		t.Fatalf("primer envío: %v", err)
+	}
+	if err := h.verificarFirma(secretoPrueba, timestamp, firma, cuerpo); err == nil || !strings.Contains(err.Error(), "ya fue recibida") {
+		t.Errorf("reenvío = %v, se esperaba que se rechazara", err)
+	}
+
+	// Un envío nuevo del mismo cuerpo lleva otro timestamp y otra firma
+	otro := timestampDesde(time.Second)
+	if err := h.verificarFirma(secretoPrueba, otro, FirmarWebhook(secretoPrueba, otro, cuerpo), cuerpo); err != nil {
+		t.Errorf("envío con otro timestamp: %v", err)
+	}
+}
+
+func TestRecibirWebhookSucursalRechazaPeticionesNoAutorizadas(t *testing.T) {
+	sucursales := persistence.NewSucursalRepositoryMemoria()
+	for _, sucursal := range []*entities.Sucursal{
+		{Nombre: "Activa", Estado: "activa", APISecret: secretoPrueba},
+		{Nombre: "Inactiva", Estado: "inactiva", APISecret: secretoPrueba},
+		{Nombre: "Sin secreto", Estado: "activa"},
+	} {
+		if err := sucursales.Guardar(context.Background(), sucursal); err != nil {
+			t.Fatalf("Guardar: %v", err)
+		}
+	}
+
+	cuerpo := []byte(`{"tipo":"venta","datos":[]}`)
+	cuerpoTipoInvalido := []byte(`{"tipo":"cliente","datos":[]}`)
+
+	casos := []struct {
+		nombre  string
+		ruta    string
+		cuerpo  []byte
+		secreto string
+		estado  int
+	}{
+		{"sucursal inválida", "/api/webhooks/sucursales/abc", cuerpo, secretoPrueba, http.StatusBadRequest},
+		{"sucursal inexistente", "/api/webhooks/sucursales/99", cuerpo, secretoPrueba, http.StatusNotFound},
+		{"sucursal inactiva", "/api/webhooks/sucursales/2", cuerpo, secretoPrueba, http.StatusUnauthorized},
+		{"sucursal sin secreto", "/api/webhooks/sucursales/3", cuerpo, "", http.StatusUnauthorized},
+		{"firma con otro secreto", "/api/webhooks/sucursales/1", cuerpo, "otro", http.StatusUnauthorized},
+		{"tipo inválido", "/api/webhooks/sucursales/1", cuerpoTipoInvalido, secretoPrueba, http.StatusBadRequest},
+	}
+
+	h := NewWebhookHandler(nil, sucursales)
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			timestamp := timestampDesde(0)
+			r := httptest.NewRequest(http.MethodPost, caso.ruta, bytes.NewReader(caso.cuerpo))
+			r.Header.Set(CabeceraTimestampWebhook, timestamp)
+			r.Header.Set(CabeceraFirmaWebhook, "sha256="+FirmarWebhook(caso.secreto, timestamp, caso.cuerpo))
+			w := httptest.NewRecorder()
+
+			h.RecibirWebhookSucursal(w, r)
+			if w.Code != caso.estado {
+				t.Errorf("estado = %d, se esperaba %d (%s)", w.Code, caso.estado, strings.TrimSpace(w.Body.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-gestion-informacion/internal/application/services"
+	"sistema-gestion-informacion/internal/domain/entities"
+	"sistema-gestion-informacion/internal/domain/repositories"
+	"sistema-gestion-informacion/internal/infrastructure/connectors"
+)
+
+// ReglaArchivo asocia un patrón de nombre de archivo a una sucursal y tipo de datos
+type ReglaArchivo struct {
+	Patron     string `json:"patron"` // patrón glob, p. ej. "centro_ventas_*.csv"
+	SucursalID uint   `json:"sucursal_id"`
+	Tipo       string `json:"tipo"`
+}
+
+// patronConvencion reconoce archivos nombrados "sucursal_<id>_<tipo>_*.<ext>"
+// cuando ninguna regla configurada coincide
+var patronConvencion = regexp.MustCompile(`^sucursal_(\d+)_([a-z]+)`)
+
+// ResultadoArchivo es el contenido del archivo de resultado que acompaña a cada
+// archivo procesado o fallido
+type ResultadoArchivo struct {
+	Archivo     string                  `json:"archivo"`
+	Estado      string                  `json:"estado"` // 'procesado', 'fallido'
+	SucursalID  uint                    `json:"sucursal_id,omitempty"`
+	Tipo        string                  `json:"tipo,omitempty"`
+	Resultado   *services.ResultadoLote `json:"resultado,omitempty"`
+	Error       string                  `json:"error,omitempty"`
+	ProcesadoEn time.Time               `json:"procesado_en"`
+}
+
+// CarpetaWatcher vigila una carpeta de entrada donde las sucursales depositan
+// sus exportaciones y las procesa a medida que aparecen
+type CarpetaWatcher struct {
+	inbox      string
+	procesados string
+	fallidos   string
+	reglas     []ReglaArchivo
+	intervalo  time.Duration
+	procesador *services.ProcesadorDatosService
+	sucursales repositories.SucursalRepository
+
+	// tamaño observado en la revisión anterior, para procesar solo archivos
+	// que terminaron de copiarse
+	tamanios map[string]int64
+	// archivos ya procesados que siguen en la carpeta de entrada porque no
+	// pudieron moverse; no se vuelven a procesar mientras no cambien
+	manejados map[string]*archivoManejado
+}
+
+// archivoManejado es un archivo procesado pendiente de mover a su destino,
+// identificado por su nombre, tamaño y fecha de modificación
+type archivoManejado struct {
+	tamanio    int64
+	modificado time.Time
+	destino    string
+	resultado  *ResultadoArchivo
+}
+
+// NewCarpetaWatcher crea un watcher para la carpeta de entrada indicada. Los
+// archivos se mueven a las subcarpetas processed/ y failed/ al terminar.
+func NewCarpetaWatcher(inbox string, reglas []ReglaArchivo, intervalo time.Duration, procesador *services.ProcesadorDatosService, sucursales repositories.SucursalRepository) *CarpetaWatcher {
+	return &CarpetaWatcher{
+		inbox:      inbox,
+		procesados: filepath.Join(inbox, "processed"),
+		fallidos:   filepath.Join(inbox, "failed"),
+		reglas:     reglas,
+		intervalo:  intervalo,
+		procesador: procesador,
+		sucursales: sucursales,
+		tamanios:   make(map[string]int64),
+		manejados:  make(map[string]*archivoManejado),
+	}
+}
+
+// ParsearReglas interpreta reglas con el formato "patron:sucursal_id:tipo"
+// separadas por coma
+func ParsearReglas(texto string) ([]ReglaArchivo, error) {
+	var reglas []ReglaArchivo
+
+	for _, definicion := range strings.Split(texto, ",") {
+		definicion = strings.TrimSpace(definicion)
+		if definicion == "" {
+			continue
+		}
+
+		partes := strings.Split(definicion, ":")
+		if len(partes) != 3 {
+			return nil, fmt.Errorf("regla de archivo inválida %q: se esperaba patron:sucursal_id:tipo", definicion)
+		}
+
+		if _, err := filepath.Match(partes[0], ""); err != nil {
+			return nil, fmt.Errorf("patrón inválido en regla %q: %v", definicion, err)
+		}
+
+		sucursalID, err := strconv.ParseUint(partes[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sucursal inválida en regla %q: %v", definicion, err)
+		}
+
+		if !services.EsTipoDatos(partes[2]) {
+			return nil, fmt.Errorf("tipo inválido en regla %q: se acepta %s", definicion, strings.Join(services.TiposDatos, ", "))
+		}
+
+		reglas = append(reglas, ReglaArchivo{
+			Patron:     partes[0],
+			SucursalID: uint(sucursalID),
+			Tipo:       partes[2],
+		})
+	}
+
+	return reglas, nil
+}
+
+// Iniciar revisa la carpeta periódicamente hasta que se cancele el contexto
+func (w *CarpetaWatcher) Iniciar(ctx context.Context) error {
+	for _, dir := range []string{w.inbox, w.procesados, w.fallidos} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("error creando carpeta %s: %v", dir, err)
+		}
+	}
+
+	log.Printf("Vigilando carpeta de entrada %s cada %v", w.inbox, w.intervalo)
+
+	ticker := time.NewTicker(w.intervalo)
+	defer ticker.Stop()
+
+	for {
+		w.revisar(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// revisar procesa los archivos nuevos cuyo tamaño no cambió desde la revisión
+// anterior y reintenta mover los ya procesados que quedaron en la carpeta
+func (w *CarpetaWatcher) revisar(ctx context.Context) {
+	entradas, err := os.ReadDir(w.inbox)
+	if err != nil {
+		log.Printf("Error leyendo carpeta de entrada %s: %v", w.inbox, err)
+		return
+	}
+
+	vistos := make(map[string]int64)
+	presentes := make(map[string]bool)
+	for _, entrada := range entradas {
+		nombre := entrada.Name()
+		if entrada.IsDir() || strings.HasPrefix(nombre, ".") || connectors.FormatoDesdeNombre(nombre) == "" {
+			continue
+		}
+
+		info, err := entrada.Info()
+		if err != nil {
+			continue
+		}
+		presentes[nombre] = true
+
+		// Un archivo ya procesado solo se vuelve a procesar si cambió
+		if manejado, existe := w.manejados[nombre]; existe {
+			if manejado.tamanio == info.Size() && manejado.modificado.Equal(info.ModTime()) {
+				w.reintentarMovimiento(nombre, manejado)
+				continue
+			}
+			delete(w.manejados, nombre)
+		}
+
+		tamanioAnterior, visto := w.tamanios[nombre]
+		vistos[nombre] = info.Size()
+		if !visto || tamanioAnterior != info.Size() {
+			continue
+		}
+
+		w.procesarArchivo(ctx, nombre, info)
+		delete(vistos, nombre)
+	}
+
+	for nombre := range w.manejados {
+		if !presentes[nombre] {
+			delete(w.manejados, nombre)
+		}
+	}
+	w.tamanios = vistos
+}
+
+// reintentarMovimiento vuelve a intentar mover un archivo ya procesado, sin procesarlo otra vez
+func (w *CarpetaWatcher) reintentarMovimiento(nombre string, manejado *archivoManejado) {
+	if err := w.mover(filepath.Join(w.inbox, nombre), manejado.destino, manejado.resultado); err != nil {
+		log.Printf("Error moviendo archivo %s: %v", nombre, err)
+		return
+	}
+	delete(w.manejados, nombre)
+}
+
+// procesarArchivo procesa un archivo de la carpeta de entrada y lo mueve según
+// el resultado. Si no puede moverlo, lo registra para reintentar solo el movimiento.
+func (w *CarpetaWatcher) procesarArchivo(ctx context.Context, nombre string, info os.FileInfo) {
+	ruta := filepath.Join(w.inbox, nombre)
+	resultado := &ResultadoArchivo{Archivo: nombre}
+
+	regla, err := w.resolverRegla(nombre)
+	if err == nil {
+		resultado.SucursalID = regla.SucursalID
+		resultado.Tipo = regla.Tipo
+
+		var sucursal *entities.Sucursal
+		sucursal, err = w.sucursales.ObtenerPorID(ctx, regla.SucursalID)
+		if errors.Is(err, repositories.ErrNoEncontrado) {
+			err = fmt.Errorf("la sucursal %d no está registrada", regla.SucursalID)
+		}
+
+		var conector *connectors.ConectorArchivo
+		if err == nil {
+			conector, err = connectors.NewConectorArchivoSucursal(ruta, sucursal)
+		}
+		if err == nil {
+			log.Printf("Procesando archivo %s para sucursal %d", nombre, regla.SucursalID)
+			resultado.Resultado, err = w.procesador.SincronizarSucursal(ctx, regla.SucursalID, regla.Tipo, conector)
+		}
+		// Un archivo del que no se persistió ningún registro no se da por procesado
+		if err == nil && resultado.Resultado.RegistrosPersistidos == 0 && resultado.Resultado.RegistrosFallidos > 0 {
+			err = fmt.Errorf("no se persistió ninguno de los %d registros del archivo", resultado.Resultado.RegistrosFallidos)
+		}
+	}
+
+	resultado.ProcesadoEn = time.Now()
+	destino := w.procesados
+	resultado.Estado = "procesado"
+	if err != nil {
+		destino = w.fallidos
+		resultado.Estado = "fallido"
+		resultado.Error = err.Error()
+		log.Printf("Error procesando archivo %s: %v", nombre, err)
+	}
+
+	if err := w.mover(ruta, destino, resultado); err != nil {
+		log.Printf("Error moviendo archivo %s, se reintentará sin procesarlo de nuevo: %v", nombre, err)
+		w.manejados[nombre] = &archivoManejado{
+			tamanio:    info.Size(),
+			modificado: info.ModTime(),
+			destino:    destino,
+			resultado:  resultado,
+		}
+	}
+}
+
+// resolverRegla busca la regla que corresponde al nombre del archivo
+func (w *CarpetaWatcher) resolverRegla(nombre string) (*ReglaArchivo, error) {
+	for i := range w.reglas {
+		if coincide, _ := filepath.Match(w.reglas[i].Patron, nombre); coincide {
+			return &w.reglas[i], nil
+		}
+	}
+
+	if partes := patronConvencion.FindStringSubmatch(strings.ToLower(nombre)); partes != nil {
+		if !services.EsTipoDatos(partes[2]) {
+			return nil, fmt.Errorf("el archivo %s indica el tipo %q (se acepta %s)", nombre, partes[2], strings.Join(services.TiposDatos, ", "))
+		}
+		sucursalID, err := strconv.ParseUint(partes[1], 10, 64)
+		if err == nil {
+			return &ReglaArchivo{Patron: patronConvencion.String(), SucursalID: uint(sucursalID), Tipo: partes[2]}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("ninguna regla asocia el archivo %s a una sucursal", nombre)
+}
+
+// mover traslada el archivo a la carpeta destino y escribe a su lado el archivo de resultado
+func (w *CarpetaWatcher) mover(ruta, destino string, resultado *ResultadoArchivo) error {
+	nombreDestino := filepath.Base(ruta)
+	if _, err := os.Stat(filepath.Join(destino, nombreDestino)); err == nil {
+		nombreDestino = fmt.Sprintf("%s_%s", time.Now().Format("20060102T150405"), nombreDestino)
+	}
+
+	if err := os.Rename(ruta, filepath.Join(destino, nombreDestino)); err != nil {
+		return err
+	}
+
+	contenido, err := json.MarshalIndent(resultado, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(destino, nombreDestino+".resultado.json"), contenido, 0o644)
+}